@@ -7,6 +7,7 @@
 package api
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -67,9 +68,10 @@ func (x *GetMangaRequest) GetMangaId() string {
 }
 
 type ListMangaRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page counts from 1; leave page or page_size unset to list everything
+	Page          int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -163,9 +165,11 @@ func (x *SearchMangaRequest) GetQuery() string {
 }
 
 type GetUserProgressRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	MangaId       string                 `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId       string `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -215,10 +219,13 @@ func (x *GetUserProgressRequest) GetMangaId() string {
 }
 
 type UpdateProgressRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	MangaId       string                 `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
-	Chapter       int32                  `protobuf:"varint,3,opt,name=chapter,proto3" json:"chapter,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId  string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId string `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	// chapter must be at least 1
+	Chapter       int32 `protobuf:"varint,3,opt,name=chapter,proto3" json:"chapter,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -274,28 +281,32 @@ func (x *UpdateProgressRequest) GetChapter() int32 {
 	return 0
 }
 
-// Response messages
-type MangaResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Manga         *Manga                 `protobuf:"bytes,1,opt,name=manga,proto3" json:"manga,omitempty"`
+type WatchProgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// resume_token continues after the event that carried it; empty starts
+	// with the next event
+	ResumeToken   string `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MangaResponse) Reset() {
-	*x = MangaResponse{}
+func (x *WatchProgressRequest) Reset() {
+	*x = WatchProgressRequest{}
 	mi := &file_api_mangahub_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MangaResponse) String() string {
+func (x *WatchProgressRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MangaResponse) ProtoMessage() {}
+func (*WatchProgressRequest) ProtoMessage() {}
 
-func (x *MangaResponse) ProtoReflect() protoreflect.Message {
+func (x *WatchProgressRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_mangahub_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -307,40 +318,48 @@ func (x *MangaResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MangaResponse.ProtoReflect.Descriptor instead.
-func (*MangaResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use WatchProgressRequest.ProtoReflect.Descriptor instead.
+func (*WatchProgressRequest) Descriptor() ([]byte, []int) {
 	return file_api_mangahub_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *MangaResponse) GetManga() *Manga {
+func (x *WatchProgressRequest) GetUserId() string {
 	if x != nil {
-		return x.Manga
+		return x.UserId
 	}
-	return nil
+	return ""
 }
 
-type ListMangaResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Mangas        []*Manga               `protobuf:"bytes,1,rep,name=mangas,proto3" json:"mangas,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+func (x *WatchProgressRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+type WatchCatalogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// resume_token continues after the event that carried it; empty starts
+	// with the next event
+	ResumeToken   string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListMangaResponse) Reset() {
-	*x = ListMangaResponse{}
+func (x *WatchCatalogRequest) Reset() {
+	*x = WatchCatalogRequest{}
 	mi := &file_api_mangahub_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListMangaResponse) String() string {
+func (x *WatchCatalogRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListMangaResponse) ProtoMessage() {}
+func (*WatchCatalogRequest) ProtoMessage() {}
 
-func (x *ListMangaResponse) ProtoReflect() protoreflect.Message {
+func (x *WatchCatalogRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_mangahub_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -352,46 +371,41 @@ func (x *ListMangaResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListMangaResponse.ProtoReflect.Descriptor instead.
-func (*ListMangaResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use WatchCatalogRequest.ProtoReflect.Descriptor instead.
+func (*WatchCatalogRequest) Descriptor() ([]byte, []int) {
 	return file_api_mangahub_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ListMangaResponse) GetMangas() []*Manga {
-	if x != nil {
-		return x.Mangas
-	}
-	return nil
-}
-
-func (x *ListMangaResponse) GetTotal() int32 {
+func (x *WatchCatalogRequest) GetResumeToken() string {
 	if x != nil {
-		return x.Total
+		return x.ResumeToken
 	}
-	return 0
+	return ""
 }
 
-type UserProgressResponse struct {
+type RegisterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Progress      *UserProgress          `protobuf:"bytes,1,opt,name=progress,proto3" json:"progress,omitempty"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UserProgressResponse) Reset() {
-	*x = UserProgressResponse{}
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
 	mi := &file_api_mangahub_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UserProgressResponse) String() string {
+func (x *RegisterRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UserProgressResponse) ProtoMessage() {}
+func (*RegisterRequest) ProtoMessage() {}
 
-func (x *UserProgressResponse) ProtoReflect() protoreflect.Message {
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_mangahub_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -403,41 +417,54 @@ func (x *UserProgressResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UserProgressResponse.ProtoReflect.Descriptor instead.
-func (*UserProgressResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
 	return file_api_mangahub_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *UserProgressResponse) GetProgress() *UserProgress {
+func (x *RegisterRequest) GetUsername() string {
 	if x != nil {
-		return x.Progress
+		return x.Username
 	}
-	return nil
+	return ""
 }
 
-type UpdateProgressResponse struct {
+func (x *RegisterRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type LoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Progress      *UserProgress          `protobuf:"bytes,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateProgressResponse) Reset() {
-	*x = UpdateProgressResponse{}
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
 	mi := &file_api_mangahub_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateProgressResponse) String() string {
+func (x *LoginRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateProgressResponse) ProtoMessage() {}
+func (*LoginRequest) ProtoMessage() {}
 
-func (x *UpdateProgressResponse) ProtoReflect() protoreflect.Message {
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_mangahub_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -449,61 +476,46 @@ func (x *UpdateProgressResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateProgressResponse.ProtoReflect.Descriptor instead.
-func (*UpdateProgressResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
 	return file_api_mangahub_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *UpdateProgressResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
-}
-
-func (x *UpdateProgressResponse) GetMessage() string {
+func (x *LoginRequest) GetUsername() string {
 	if x != nil {
-		return x.Message
+		return x.Username
 	}
 	return ""
 }
 
-func (x *UpdateProgressResponse) GetProgress() *UserProgress {
+func (x *LoginRequest) GetPassword() string {
 	if x != nil {
-		return x.Progress
+		return x.Password
 	}
-	return nil
+	return ""
 }
 
-// Data models
-type Manga struct {
+type RefreshRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
-	Genres        []string               `protobuf:"bytes,4,rep,name=genres,proto3" json:"genres,omitempty"`
-	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
-	TotalChapters int32                  `protobuf:"varint,6,opt,name=total_chapters,json=totalChapters,proto3" json:"total_chapters,omitempty"`
-	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
-	CoverUrl      string                 `protobuf:"bytes,8,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Manga) Reset() {
-	*x = Manga{}
+func (x *RefreshRequest) Reset() {
+	*x = RefreshRequest{}
 	mi := &file_api_mangahub_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Manga) String() string {
+func (x *RefreshRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Manga) ProtoMessage() {}
+func (*RefreshRequest) ProtoMessage() {}
 
-func (x *Manga) ProtoReflect() protoreflect.Message {
+func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_mangahub_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -515,93 +527,153 @@ func (x *Manga) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Manga.ProtoReflect.Descriptor instead.
-func (*Manga) Descriptor() ([]byte, []int) {
+// Deprecated: Use RefreshRequest.ProtoReflect.Descriptor instead.
+func (*RefreshRequest) Descriptor() ([]byte, []int) {
 	return file_api_mangahub_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Manga) GetId() string {
+func (x *RefreshRequest) GetToken() string {
 	if x != nil {
-		return x.Id
+		return x.Token
 	}
 	return ""
 }
 
-func (x *Manga) GetTitle() string {
+type AddLibraryEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId  string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId string `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	// status defaults to plan_to_read
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddLibraryEntryRequest) Reset() {
+	*x = AddLibraryEntryRequest{}
+	mi := &file_api_mangahub_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddLibraryEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddLibraryEntryRequest) ProtoMessage() {}
+
+func (x *AddLibraryEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[10]
 	if x != nil {
-		return x.Title
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Manga) GetAuthor() string {
+// Deprecated: Use AddLibraryEntryRequest.ProtoReflect.Descriptor instead.
+func (*AddLibraryEntryRequest) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AddLibraryEntryRequest) GetUserId() string {
 	if x != nil {
-		return x.Author
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *Manga) GetGenres() []string {
+func (x *AddLibraryEntryRequest) GetMangaId() string {
 	if x != nil {
-		return x.Genres
+		return x.MangaId
 	}
-	return nil
+	return ""
 }
 
-func (x *Manga) GetStatus() string {
+func (x *AddLibraryEntryRequest) GetStatus() string {
 	if x != nil {
 		return x.Status
 	}
 	return ""
 }
 
-func (x *Manga) GetTotalChapters() int32 {
-	if x != nil {
-		return x.TotalChapters
-	}
-	return 0
+type ListLibraryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Manga) GetDescription() string {
+func (x *ListLibraryRequest) Reset() {
+	*x = ListLibraryRequest{}
+	mi := &file_api_mangahub_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLibraryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLibraryRequest) ProtoMessage() {}
+
+func (x *ListLibraryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[11]
 	if x != nil {
-		return x.Description
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Manga) GetCoverUrl() string {
+// Deprecated: Use ListLibraryRequest.ProtoReflect.Descriptor instead.
+func (*ListLibraryRequest) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListLibraryRequest) GetUserId() string {
 	if x != nil {
-		return x.CoverUrl
+		return x.UserId
 	}
 	return ""
 }
 
-type UserProgress struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	MangaId       string                 `protobuf:"bytes,3,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
-	Chapter       int32                  `protobuf:"varint,4,opt,name=chapter,proto3" json:"chapter,omitempty"`
-	UpdatedAt     string                 `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+type UpdateLibraryStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId       string `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UserProgress) Reset() {
-	*x = UserProgress{}
-	mi := &file_api_mangahub_proto_msgTypes[10]
+func (x *UpdateLibraryStatusRequest) Reset() {
+	*x = UpdateLibraryStatusRequest{}
+	mi := &file_api_mangahub_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UserProgress) String() string {
+func (x *UpdateLibraryStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UserProgress) ProtoMessage() {}
+func (*UpdateLibraryStatusRequest) ProtoMessage() {}
 
-func (x *UserProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_api_mangahub_proto_msgTypes[10]
+func (x *UpdateLibraryStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -612,76 +684,1900 @@ func (x *UserProgress) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UserProgress.ProtoReflect.Descriptor instead.
-func (*UserProgress) Descriptor() ([]byte, []int) {
-	return file_api_mangahub_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use UpdateLibraryStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLibraryStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *UserProgress) GetId() string {
+func (x *UpdateLibraryStatusRequest) GetUserId() string {
 	if x != nil {
-		return x.Id
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *UserProgress) GetUserId() string {
+func (x *UpdateLibraryStatusRequest) GetMangaId() string {
 	if x != nil {
-		return x.UserId
+		return x.MangaId
 	}
 	return ""
 }
 
-func (x *UserProgress) GetMangaId() string {
+func (x *UpdateLibraryStatusRequest) GetStatus() string {
 	if x != nil {
-		return x.MangaId
+		return x.Status
 	}
 	return ""
 }
 
-func (x *UserProgress) GetChapter() int32 {
+type RemoveLibraryEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId       string `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveLibraryEntryRequest) Reset() {
+	*x = RemoveLibraryEntryRequest{}
+	mi := &file_api_mangahub_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveLibraryEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveLibraryEntryRequest) ProtoMessage() {}
+
+func (x *RemoveLibraryEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[13]
 	if x != nil {
-		return x.Chapter
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *UserProgress) GetUpdatedAt() string {
+// Deprecated: Use RemoveLibraryEntryRequest.ProtoReflect.Descriptor instead.
+func (*RemoveLibraryEntryRequest) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RemoveLibraryEntryRequest) GetUserId() string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.UserId
 	}
 	return ""
 }
 
-var File_api_mangahub_proto protoreflect.FileDescriptor
+func (x *RemoveLibraryEntryRequest) GetMangaId() string {
+	if x != nil {
+		return x.MangaId
+	}
+	return ""
+}
 
-const file_api_mangahub_proto_rawDesc = "" +
-	"\n" +
-	"\x12api/mangahub.proto\x12\bmangahub\",\n" +
-	"\x0fGetMangaRequest\x12\x19\n" +
-	"\bmanga_id\x18\x01 \x01(\tR\amangaId\"C\n" +
-	"\x10ListMangaRequest\x12\x12\n" +
-	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"*\n" +
-	"\x12SearchMangaRequest\x12\x14\n" +
-	"\x05query\x18\x01 \x01(\tR\x05query\"L\n" +
-	"\x16GetUserProgressRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
-	"\bmanga_id\x18\x02 \x01(\tR\amangaId\"e\n" +
-	"\x15UpdateProgressRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
-	"\bmanga_id\x18\x02 \x01(\tR\amangaId\x12\x18\n" +
-	"\achapter\x18\x03 \x01(\x05R\achapter\"6\n" +
-	"\rMangaResponse\x12%\n" +
-	"\x05manga\x18\x01 \x01(\v2\x0f.mangahub.MangaR\x05manga\"R\n" +
-	"\x11ListMangaResponse\x12'\n" +
-	"\x06mangas\x18\x01 \x03(\v2\x0f.mangahub.MangaR\x06mangas\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total\"J\n" +
-	"\x14UserProgressResponse\x122\n" +
+type GetProfileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileRequest) Reset() {
+	*x = GetProfileRequest{}
+	mi := &file_api_mangahub_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileRequest) ProtoMessage() {}
+
+func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
+func (*GetProfileRequest) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetProfileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// SyncRequest is a message from a Sync client
+type SyncRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*SyncRequest_Register
+	//	*SyncRequest_ProgressUpdate
+	//	*SyncRequest_Ping
+	Message       isSyncRequest_Message `protobuf_oneof:"message"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	mi := &file_api_mangahub_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SyncRequest) GetMessage() isSyncRequest_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SyncRequest) GetRegister() *SyncRegister {
+	if x != nil {
+		if x, ok := x.Message.(*SyncRequest_Register); ok {
+			return x.Register
+		}
+	}
+	return nil
+}
+
+func (x *SyncRequest) GetProgressUpdate() *SyncProgressUpdate {
+	if x != nil {
+		if x, ok := x.Message.(*SyncRequest_ProgressUpdate); ok {
+			return x.ProgressUpdate
+		}
+	}
+	return nil
+}
+
+func (x *SyncRequest) GetPing() *SyncPing {
+	if x != nil {
+		if x, ok := x.Message.(*SyncRequest_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+type isSyncRequest_Message interface {
+	isSyncRequest_Message()
+}
+
+type SyncRequest_Register struct {
+	Register *SyncRegister `protobuf:"bytes,1,opt,name=register,proto3,oneof"`
+}
+
+type SyncRequest_ProgressUpdate struct {
+	ProgressUpdate *SyncProgressUpdate `protobuf:"bytes,2,opt,name=progress_update,json=progressUpdate,proto3,oneof"`
+}
+
+type SyncRequest_Ping struct {
+	Ping *SyncPing `protobuf:"bytes,3,opt,name=ping,proto3,oneof"`
+}
+
+func (*SyncRequest_Register) isSyncRequest_Message() {}
+
+func (*SyncRequest_ProgressUpdate) isSyncRequest_Message() {}
+
+func (*SyncRequest_Ping) isSyncRequest_Message() {}
+
+// SyncRegister associates the stream with a user, answered by registered
+type SyncRegister struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRegister) Reset() {
+	*x = SyncRegister{}
+	mi := &file_api_mangahub_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRegister) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRegister) ProtoMessage() {}
+
+func (x *SyncRegister) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRegister.ProtoReflect.Descriptor instead.
+func (*SyncRegister) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SyncRegister) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// SyncProgressUpdate is relayed to every other client, answered by ack. It
+// is not saved; use MangaService.UpdateProgress for that.
+type SyncProgressUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id defaults to the caller; only admins and service accounts may
+	// name another user
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId       string `protobuf:"bytes,2,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	Chapter       int32  `protobuf:"varint,3,opt,name=chapter,proto3" json:"chapter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncProgressUpdate) Reset() {
+	*x = SyncProgressUpdate{}
+	mi := &file_api_mangahub_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncProgressUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncProgressUpdate) ProtoMessage() {}
+
+func (x *SyncProgressUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncProgressUpdate.ProtoReflect.Descriptor instead.
+func (*SyncProgressUpdate) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SyncProgressUpdate) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SyncProgressUpdate) GetMangaId() string {
+	if x != nil {
+		return x.MangaId
+	}
+	return ""
+}
+
+func (x *SyncProgressUpdate) GetChapter() int32 {
+	if x != nil {
+		return x.Chapter
+	}
+	return 0
+}
+
+// SyncPing is answered by pong
+type SyncPing struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPing) Reset() {
+	*x = SyncPing{}
+	mi := &file_api_mangahub_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPing) ProtoMessage() {}
+
+func (x *SyncPing) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPing.ProtoReflect.Descriptor instead.
+func (*SyncPing) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{18}
+}
+
+// Response messages
+type MangaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Manga         *Manga                 `protobuf:"bytes,1,opt,name=manga,proto3" json:"manga,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MangaResponse) Reset() {
+	*x = MangaResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MangaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MangaResponse) ProtoMessage() {}
+
+func (x *MangaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MangaResponse.ProtoReflect.Descriptor instead.
+func (*MangaResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *MangaResponse) GetManga() *Manga {
+	if x != nil {
+		return x.Manga
+	}
+	return nil
+}
+
+type ListMangaResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Mangas []*Manga               `protobuf:"bytes,1,rep,name=mangas,proto3" json:"mangas,omitempty"`
+	// total counts every match, not only those on the page
+	Total         int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMangaResponse) Reset() {
+	*x = ListMangaResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMangaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMangaResponse) ProtoMessage() {}
+
+func (x *ListMangaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMangaResponse.ProtoReflect.Descriptor instead.
+func (*ListMangaResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListMangaResponse) GetMangas() []*Manga {
+	if x != nil {
+		return x.Mangas
+	}
+	return nil
+}
+
+func (x *ListMangaResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type UserProgressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Progress      *UserProgress          `protobuf:"bytes,1,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserProgressResponse) Reset() {
+	*x = UserProgressResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProgressResponse) ProtoMessage() {}
+
+func (x *UserProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProgressResponse.ProtoReflect.Descriptor instead.
+func (*UserProgressResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UserProgressResponse) GetProgress() *UserProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+type UpdateProgressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Progress      *UserProgress          `protobuf:"bytes,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProgressResponse) Reset() {
+	*x = UpdateProgressResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProgressResponse) ProtoMessage() {}
+
+func (x *UpdateProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProgressResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProgressResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UpdateProgressResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateProgressResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateProgressResponse) GetProgress() *UserProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+// ProgressEvent is a progress update on a WatchProgress stream
+type ProgressEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// resume_token resumes the stream after this event
+	ResumeToken   string        `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	Timestamp     string        `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Progress      *UserProgress `protobuf:"bytes,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgressEvent) Reset() {
+	*x = ProgressEvent{}
+	mi := &file_api_mangahub_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgressEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgressEvent) ProtoMessage() {}
+
+func (x *ProgressEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgressEvent.ProtoReflect.Descriptor instead.
+func (*ProgressEvent) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ProgressEvent) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *ProgressEvent) GetProgress() *UserProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+// CatalogEvent is a catalog change on a WatchCatalog stream
+type CatalogEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// resume_token resumes the stream after this event
+	ResumeToken string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	Timestamp   string `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*CatalogEvent_Created
+	//	*CatalogEvent_Updated
+	//	*CatalogEvent_DeletedMangaId
+	Event         isCatalogEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CatalogEvent) Reset() {
+	*x = CatalogEvent{}
+	mi := &file_api_mangahub_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CatalogEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogEvent) ProtoMessage() {}
+
+func (x *CatalogEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogEvent.ProtoReflect.Descriptor instead.
+func (*CatalogEvent) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CatalogEvent) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *CatalogEvent) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *CatalogEvent) GetEvent() isCatalogEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CatalogEvent) GetCreated() *Manga {
+	if x != nil {
+		if x, ok := x.Event.(*CatalogEvent_Created); ok {
+			return x.Created
+		}
+	}
+	return nil
+}
+
+func (x *CatalogEvent) GetUpdated() *Manga {
+	if x != nil {
+		if x, ok := x.Event.(*CatalogEvent_Updated); ok {
+			return x.Updated
+		}
+	}
+	return nil
+}
+
+func (x *CatalogEvent) GetDeletedMangaId() string {
+	if x != nil {
+		if x, ok := x.Event.(*CatalogEvent_DeletedMangaId); ok {
+			return x.DeletedMangaId
+		}
+	}
+	return ""
+}
+
+type isCatalogEvent_Event interface {
+	isCatalogEvent_Event()
+}
+
+type CatalogEvent_Created struct {
+	Created *Manga `protobuf:"bytes,3,opt,name=created,proto3,oneof"`
+}
+
+type CatalogEvent_Updated struct {
+	Updated *Manga `protobuf:"bytes,4,opt,name=updated,proto3,oneof"`
+}
+
+type CatalogEvent_DeletedMangaId struct {
+	DeletedMangaId string `protobuf:"bytes,5,opt,name=deleted_manga_id,json=deletedMangaId,proto3,oneof"`
+}
+
+func (*CatalogEvent_Created) isCatalogEvent_Event() {}
+
+func (*CatalogEvent_Updated) isCatalogEvent_Event() {}
+
+func (*CatalogEvent_DeletedMangaId) isCatalogEvent_Event() {}
+
+// SyncResponse is a message to a Sync client
+type SyncResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*SyncResponse_Registered
+	//	*SyncResponse_Ack
+	//	*SyncResponse_Pong
+	//	*SyncResponse_Broadcast
+	//	*SyncResponse_Error
+	Message       isSyncResponse_Message `protobuf_oneof:"message"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncResponse) Reset() {
+	*x = SyncResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResponse) ProtoMessage() {}
+
+func (x *SyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SyncResponse) GetMessage() isSyncResponse_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetRegistered() *SyncRegistered {
+	if x != nil {
+		if x, ok := x.Message.(*SyncResponse_Registered); ok {
+			return x.Registered
+		}
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetAck() *SyncAck {
+	if x != nil {
+		if x, ok := x.Message.(*SyncResponse_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetPong() *SyncPong {
+	if x != nil {
+		if x, ok := x.Message.(*SyncResponse_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetBroadcast() *SyncBroadcast {
+	if x != nil {
+		if x, ok := x.Message.(*SyncResponse_Broadcast); ok {
+			return x.Broadcast
+		}
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetError() *SyncError {
+	if x != nil {
+		if x, ok := x.Message.(*SyncResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isSyncResponse_Message interface {
+	isSyncResponse_Message()
+}
+
+type SyncResponse_Registered struct {
+	Registered *SyncRegistered `protobuf:"bytes,1,opt,name=registered,proto3,oneof"`
+}
+
+type SyncResponse_Ack struct {
+	Ack *SyncAck `protobuf:"bytes,2,opt,name=ack,proto3,oneof"`
+}
+
+type SyncResponse_Pong struct {
+	Pong *SyncPong `protobuf:"bytes,3,opt,name=pong,proto3,oneof"`
+}
+
+type SyncResponse_Broadcast struct {
+	Broadcast *SyncBroadcast `protobuf:"bytes,4,opt,name=broadcast,proto3,oneof"`
+}
+
+type SyncResponse_Error struct {
+	Error *SyncError `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+}
+
+func (*SyncResponse_Registered) isSyncResponse_Message() {}
+
+func (*SyncResponse_Ack) isSyncResponse_Message() {}
+
+func (*SyncResponse_Pong) isSyncResponse_Message() {}
+
+func (*SyncResponse_Broadcast) isSyncResponse_Message() {}
+
+func (*SyncResponse_Error) isSyncResponse_Message() {}
+
+type SyncRegistered struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Timestamp     string                 `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRegistered) Reset() {
+	*x = SyncRegistered{}
+	mi := &file_api_mangahub_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRegistered) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRegistered) ProtoMessage() {}
+
+func (x *SyncRegistered) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRegistered.ProtoReflect.Descriptor instead.
+func (*SyncRegistered) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SyncRegistered) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SyncRegistered) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+type SyncAck struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is the request acknowledged, e.g. "progress_update"
+	Type          string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp     string `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncAck) Reset() {
+	*x = SyncAck{}
+	mi := &file_api_mangahub_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncAck) ProtoMessage() {}
+
+func (x *SyncAck) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncAck.ProtoReflect.Descriptor instead.
+func (*SyncAck) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SyncAck) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SyncAck) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+type SyncPong struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     string                 `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPong) Reset() {
+	*x = SyncPong{}
+	mi := &file_api_mangahub_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPong) ProtoMessage() {}
+
+func (x *SyncPong) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPong.ProtoReflect.Descriptor instead.
+func (*SyncPong) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SyncPong) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+// SyncBroadcast carries the messages the TCP protocol pushes:
+// progress_update, progress_broadcast, library_update, new_manga,
+// manga_updated and manga_deleted
+type SyncBroadcast struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId       string                 `protobuf:"bytes,3,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	Chapter       int32                  `protobuf:"varint,4,opt,name=chapter,proto3" json:"chapter,omitempty"`
+	Data          map[string]string      `protobuf:"bytes,5,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Timestamp     string                 `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncBroadcast) Reset() {
+	*x = SyncBroadcast{}
+	mi := &file_api_mangahub_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncBroadcast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncBroadcast) ProtoMessage() {}
+
+func (x *SyncBroadcast) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncBroadcast.ProtoReflect.Descriptor instead.
+func (*SyncBroadcast) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SyncBroadcast) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SyncBroadcast) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SyncBroadcast) GetMangaId() string {
+	if x != nil {
+		return x.MangaId
+	}
+	return ""
+}
+
+func (x *SyncBroadcast) GetChapter() int32 {
+	if x != nil {
+		return x.Chapter
+	}
+	return 0
+}
+
+func (x *SyncBroadcast) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SyncBroadcast) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+// SyncError reports a request that could not be handled; the stream stays
+// open
+type SyncError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncError) Reset() {
+	*x = SyncError{}
+	mi := &file_api_mangahub_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncError) ProtoMessage() {}
+
+func (x *SyncError) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncError.ProtoReflect.Descriptor instead.
+func (*SyncError) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SyncError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type AuthResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Token    string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	UserId   string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	Role     string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	// expires_at is when the token expires (RFC3339)
+	ExpiresAt     string `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthResponse) Reset() {
+	*x = AuthResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthResponse) ProtoMessage() {}
+
+func (x *AuthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthResponse.ProtoReflect.Descriptor instead.
+func (*AuthResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AuthResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *AuthResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AuthResponse) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *AuthResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *AuthResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type LibraryEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         *LibraryEntry          `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LibraryEntryResponse) Reset() {
+	*x = LibraryEntryResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LibraryEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LibraryEntryResponse) ProtoMessage() {}
+
+func (x *LibraryEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LibraryEntryResponse.ProtoReflect.Descriptor instead.
+func (*LibraryEntryResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LibraryEntryResponse) GetEntry() *LibraryEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type ListLibraryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*LibraryEntry        `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLibraryResponse) Reset() {
+	*x = ListLibraryResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLibraryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLibraryResponse) ProtoMessage() {}
+
+func (x *ListLibraryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLibraryResponse.ProtoReflect.Descriptor instead.
+func (*ListLibraryResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListLibraryResponse) GetEntries() []*LibraryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListLibraryResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type RemoveLibraryEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveLibraryEntryResponse) Reset() {
+	*x = RemoveLibraryEntryResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveLibraryEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveLibraryEntryResponse) ProtoMessage() {}
+
+func (x *RemoveLibraryEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveLibraryEntryResponse.ProtoReflect.Descriptor instead.
+func (*RemoveLibraryEntryResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RemoveLibraryEntryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RemoveLibraryEntryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *UserProfile           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProfileResponse) Reset() {
+	*x = ProfileResponse{}
+	mi := &file_api_mangahub_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileResponse) ProtoMessage() {}
+
+func (x *ProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileResponse.ProtoReflect.Descriptor instead.
+func (*ProfileResponse) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ProfileResponse) GetUser() *UserProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// Data models
+type Manga struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Genres        []string               `protobuf:"bytes,4,rep,name=genres,proto3" json:"genres,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	TotalChapters int32                  `protobuf:"varint,6,opt,name=total_chapters,json=totalChapters,proto3" json:"total_chapters,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	CoverUrl      string                 `protobuf:"bytes,8,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Manga) Reset() {
+	*x = Manga{}
+	mi := &file_api_mangahub_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Manga) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Manga) ProtoMessage() {}
+
+func (x *Manga) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Manga.ProtoReflect.Descriptor instead.
+func (*Manga) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *Manga) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Manga) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Manga) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Manga) GetGenres() []string {
+	if x != nil {
+		return x.Genres
+	}
+	return nil
+}
+
+func (x *Manga) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Manga) GetTotalChapters() int32 {
+	if x != nil {
+		return x.TotalChapters
+	}
+	return 0
+}
+
+func (x *Manga) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Manga) GetCoverUrl() string {
+	if x != nil {
+		return x.CoverUrl
+	}
+	return ""
+}
+
+type UserProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId       string                 `protobuf:"bytes,3,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	Chapter       int32                  `protobuf:"varint,4,opt,name=chapter,proto3" json:"chapter,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserProgress) Reset() {
+	*x = UserProgress{}
+	mi := &file_api_mangahub_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProgress) ProtoMessage() {}
+
+func (x *UserProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProgress.ProtoReflect.Descriptor instead.
+func (*UserProgress) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *UserProgress) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserProgress) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserProgress) GetMangaId() string {
+	if x != nil {
+		return x.MangaId
+	}
+	return ""
+}
+
+func (x *UserProgress) GetChapter() int32 {
+	if x != nil {
+		return x.Chapter
+	}
+	return 0
+}
+
+func (x *UserProgress) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type LibraryEntry struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId  string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MangaId string                 `protobuf:"bytes,3,opt,name=manga_id,json=mangaId,proto3" json:"manga_id,omitempty"`
+	// status is one of reading, completed, plan_to_read, dropped
+	Status        string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	AddedAt       string `protobuf:"bytes,5,opt,name=added_at,json=addedAt,proto3" json:"added_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LibraryEntry) Reset() {
+	*x = LibraryEntry{}
+	mi := &file_api_mangahub_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LibraryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LibraryEntry) ProtoMessage() {}
+
+func (x *LibraryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LibraryEntry.ProtoReflect.Descriptor instead.
+func (*LibraryEntry) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *LibraryEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *LibraryEntry) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *LibraryEntry) GetMangaId() string {
+	if x != nil {
+		return x.MangaId
+	}
+	return ""
+}
+
+func (x *LibraryEntry) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LibraryEntry) GetAddedAt() string {
+	if x != nil {
+		return x.AddedAt
+	}
+	return ""
+}
+
+type UserProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserProfile) Reset() {
+	*x = UserProfile{}
+	mi := &file_api_mangahub_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProfile) ProtoMessage() {}
+
+func (x *UserProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_api_mangahub_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProfile.ProtoReflect.Descriptor instead.
+func (*UserProfile) Descriptor() ([]byte, []int) {
+	return file_api_mangahub_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *UserProfile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserProfile) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserProfile) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UserProfile) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *UserProfile) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+var File_api_mangahub_proto protoreflect.FileDescriptor
+
+const file_api_mangahub_proto_rawDesc = "" +
+	"\n" +
+	"\x12api/mangahub.proto\x12\bmangahub\x1a\x1cgoogle/api/annotations.proto\",\n" +
+	"\x0fGetMangaRequest\x12\x19\n" +
+	"\bmanga_id\x18\x01 \x01(\tR\amangaId\"C\n" +
+	"\x10ListMangaRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"*\n" +
+	"\x12SearchMangaRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\"L\n" +
+	"\x16GetUserProgressRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x02 \x01(\tR\amangaId\"e\n" +
+	"\x15UpdateProgressRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x02 \x01(\tR\amangaId\x12\x18\n" +
+	"\achapter\x18\x03 \x01(\x05R\achapter\"R\n" +
+	"\x14WatchProgressRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12!\n" +
+	"\fresume_token\x18\x02 \x01(\tR\vresumeToken\"8\n" +
+	"\x13WatchCatalogRequest\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\"_\n" +
+	"\x0fRegisterRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\"F\n" +
+	"\fLoginRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"&\n" +
+	"\x0eRefreshRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"d\n" +
+	"\x16AddLibraryEntryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x02 \x01(\tR\amangaId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"-\n" +
+	"\x12ListLibraryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"h\n" +
+	"\x1aUpdateLibraryStatusRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x02 \x01(\tR\amangaId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"O\n" +
+	"\x19RemoveLibraryEntryRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x02 \x01(\tR\amangaId\",\n" +
+	"\x11GetProfileRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\xc1\x01\n" +
+	"\vSyncRequest\x124\n" +
+	"\bregister\x18\x01 \x01(\v2\x16.mangahub.SyncRegisterH\x00R\bregister\x12G\n" +
+	"\x0fprogress_update\x18\x02 \x01(\v2\x1c.mangahub.SyncProgressUpdateH\x00R\x0eprogressUpdate\x12(\n" +
+	"\x04ping\x18\x03 \x01(\v2\x12.mangahub.SyncPingH\x00R\x04pingB\t\n" +
+	"\amessage\"'\n" +
+	"\fSyncRegister\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"b\n" +
+	"\x12SyncProgressUpdate\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x02 \x01(\tR\amangaId\x12\x18\n" +
+	"\achapter\x18\x03 \x01(\x05R\achapter\"\n" +
+	"\n" +
+	"\bSyncPing\"6\n" +
+	"\rMangaResponse\x12%\n" +
+	"\x05manga\x18\x01 \x01(\v2\x0f.mangahub.MangaR\x05manga\"R\n" +
+	"\x11ListMangaResponse\x12'\n" +
+	"\x06mangas\x18\x01 \x03(\v2\x0f.mangahub.MangaR\x06mangas\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"J\n" +
+	"\x14UserProgressResponse\x122\n" +
 	"\bprogress\x18\x01 \x01(\v2\x16.mangahub.UserProgressR\bprogress\"\x80\x01\n" +
 	"\x16UpdateProgressResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x122\n" +
-	"\bprogress\x18\x03 \x01(\v2\x16.mangahub.UserProgressR\bprogress\"\xdb\x01\n" +
+	"\bprogress\x18\x03 \x01(\v2\x16.mangahub.UserProgressR\bprogress\"\x84\x01\n" +
+	"\rProgressEvent\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\x122\n" +
+	"\bprogress\x18\x03 \x01(\v2\x16.mangahub.UserProgressR\bprogress\"\xde\x01\n" +
+	"\fCatalogEvent\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\x12+\n" +
+	"\acreated\x18\x03 \x01(\v2\x0f.mangahub.MangaH\x00R\acreated\x12+\n" +
+	"\aupdated\x18\x04 \x01(\v2\x0f.mangahub.MangaH\x00R\aupdated\x12*\n" +
+	"\x10deleted_manga_id\x18\x05 \x01(\tH\x00R\x0edeletedMangaIdB\a\n" +
+	"\x05event\"\x8c\x02\n" +
+	"\fSyncResponse\x12:\n" +
+	"\n" +
+	"registered\x18\x01 \x01(\v2\x18.mangahub.SyncRegisteredH\x00R\n" +
+	"registered\x12%\n" +
+	"\x03ack\x18\x02 \x01(\v2\x11.mangahub.SyncAckH\x00R\x03ack\x12(\n" +
+	"\x04pong\x18\x03 \x01(\v2\x12.mangahub.SyncPongH\x00R\x04pong\x127\n" +
+	"\tbroadcast\x18\x04 \x01(\v2\x17.mangahub.SyncBroadcastH\x00R\tbroadcast\x12+\n" +
+	"\x05error\x18\x05 \x01(\v2\x13.mangahub.SyncErrorH\x00R\x05errorB\t\n" +
+	"\amessage\"G\n" +
+	"\x0eSyncRegistered\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\";\n" +
+	"\aSyncAck\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\"(\n" +
+	"\bSyncPong\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\tR\ttimestamp\"\xff\x01\n" +
+	"\rSyncBroadcast\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x03 \x01(\tR\amangaId\x12\x18\n" +
+	"\achapter\x18\x04 \x01(\x05R\achapter\x125\n" +
+	"\x04data\x18\x05 \x03(\v2!.mangahub.SyncBroadcast.DataEntryR\x04data\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\tR\ttimestamp\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"%\n" +
+	"\tSyncError\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x8c\x01\n" +
+	"\fAuthResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\tR\texpiresAt\"D\n" +
+	"\x14LibraryEntryResponse\x12,\n" +
+	"\x05entry\x18\x01 \x01(\v2\x16.mangahub.LibraryEntryR\x05entry\"]\n" +
+	"\x13ListLibraryResponse\x120\n" +
+	"\aentries\x18\x01 \x03(\v2\x16.mangahub.LibraryEntryR\aentries\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"P\n" +
+	"\x1aRemoveLibraryEntryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"<\n" +
+	"\x0fProfileResponse\x12)\n" +
+	"\x04user\x18\x01 \x01(\v2\x15.mangahub.UserProfileR\x04user\"\xdb\x01\n" +
 	"\x05Manga\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
@@ -697,13 +2593,42 @@ const file_api_mangahub_proto_rawDesc = "" +
 	"\bmanga_id\x18\x03 \x01(\tR\amangaId\x12\x18\n" +
 	"\achapter\x18\x04 \x01(\x05R\achapter\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\x05 \x01(\tR\tupdatedAt2\x88\x03\n" +
-	"\fMangaService\x12>\n" +
-	"\bGetManga\x12\x19.mangahub.GetMangaRequest\x1a\x17.mangahub.MangaResponse\x12D\n" +
-	"\tListManga\x12\x1a.mangahub.ListMangaRequest\x1a\x1b.mangahub.ListMangaResponse\x12H\n" +
-	"\vSearchManga\x12\x1c.mangahub.SearchMangaRequest\x1a\x1b.mangahub.ListMangaResponse\x12S\n" +
-	"\x0fGetUserProgress\x12 .mangahub.GetUserProgressRequest\x1a\x1e.mangahub.UserProgressResponse\x12S\n" +
-	"\x0eUpdateProgress\x12\x1f.mangahub.UpdateProgressRequest\x1a .mangahub.UpdateProgressResponseB\x0eZ\fmangahub/apib\x06proto3"
+	"updated_at\x18\x05 \x01(\tR\tupdatedAt\"\x85\x01\n" +
+	"\fLibraryEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\bmanga_id\x18\x03 \x01(\tR\amangaId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x19\n" +
+	"\badded_at\x18\x05 \x01(\tR\aaddedAt\"\x82\x01\n" +
+	"\vUserProfile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt2\xf3\x05\n" +
+	"\fMangaService\x12`\n" +
+	"\bGetManga\x12\x19.mangahub.GetMangaRequest\x1a\x17.mangahub.MangaResponse\" \x82\xd3\xe4\x93\x02\x1a\x12\x18/api/v2/manga/{manga_id}\x12[\n" +
+	"\tListManga\x12\x1a.mangahub.ListMangaRequest\x1a\x1b.mangahub.ListMangaResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v2/manga\x12f\n" +
+	"\vSearchManga\x12\x1c.mangahub.SearchMangaRequest\x1a\x1b.mangahub.ListMangaResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v2/manga:search\x12x\n" +
+	"\x0fGetUserProgress\x12 .mangahub.GetUserProgressRequest\x1a\x1e.mangahub.UserProgressResponse\"#\x82\xd3\xe4\x93\x02\x1d\x12\x1b/api/v2/progress/{manga_id}\x12p\n" +
+	"\x0eUpdateProgress\x12\x1f.mangahub.UpdateProgressRequest\x1a .mangahub.UpdateProgressResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v2/progress\x12j\n" +
+	"\rWatchProgress\x12\x1e.mangahub.WatchProgressRequest\x1a\x17.mangahub.ProgressEvent\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/v2/progress:watch0\x01\x12d\n" +
+	"\fWatchCatalog\x12\x1d.mangahub.WatchCatalogRequest\x1a\x16.mangahub.CatalogEvent\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/api/v2/manga:watch0\x012\xa4\x02\n" +
+	"\vAuthService\x12_\n" +
+	"\bRegister\x12\x19.mangahub.RegisterRequest\x1a\x16.mangahub.AuthResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/api/v2/auth/register\x12V\n" +
+	"\x05Login\x12\x16.mangahub.LoginRequest\x1a\x16.mangahub.AuthResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/api/v2/auth/login\x12\\\n" +
+	"\aRefresh\x12\x18.mangahub.RefreshRequest\x1a\x16.mangahub.AuthResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/api/v2/auth/refresh2\xc9\x03\n" +
+	"\x0eLibraryService\x12c\n" +
+	"\x03Add\x12 .mangahub.AddLibraryEntryRequest\x1a\x1e.mangahub.LibraryEntryResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/api/v2/library\x12\\\n" +
+	"\x04List\x12\x1c.mangahub.ListLibraryRequest\x1a\x1d.mangahub.ListLibraryResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/api/v2/library\x12{\n" +
+	"\fUpdateStatus\x12$.mangahub.UpdateLibraryStatusRequest\x1a\x1e.mangahub.LibraryEntryResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\x1a\x1a/api/v2/library/{manga_id}\x12w\n" +
+	"\x06Remove\x12#.mangahub.RemoveLibraryEntryRequest\x1a$.mangahub.RemoveLibraryEntryResponse\"\"\x82\xd3\xe4\x93\x02\x1c*\x1a/api/v2/library/{manga_id}2l\n" +
+	"\vUserService\x12]\n" +
+	"\n" +
+	"GetProfile\x12\x1b.mangahub.GetProfileRequest\x1a\x19.mangahub.ProfileResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/api/v2/profile2H\n" +
+	"\vSyncService\x129\n" +
+	"\x04Sync\x12\x15.mangahub.SyncRequest\x1a\x16.mangahub.SyncResponse(\x010\x01B\x0eZ\fmangahub/apib\x06proto3"
 
 var (
 	file_api_mangahub_proto_rawDescOnce sync.Once
@@ -717,40 +2642,107 @@ func file_api_mangahub_proto_rawDescGZIP() []byte {
 	return file_api_mangahub_proto_rawDescData
 }
 
-var file_api_mangahub_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_mangahub_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_api_mangahub_proto_goTypes = []any{
-	(*GetMangaRequest)(nil),        // 0: mangahub.GetMangaRequest
-	(*ListMangaRequest)(nil),       // 1: mangahub.ListMangaRequest
-	(*SearchMangaRequest)(nil),     // 2: mangahub.SearchMangaRequest
-	(*GetUserProgressRequest)(nil), // 3: mangahub.GetUserProgressRequest
-	(*UpdateProgressRequest)(nil),  // 4: mangahub.UpdateProgressRequest
-	(*MangaResponse)(nil),          // 5: mangahub.MangaResponse
-	(*ListMangaResponse)(nil),      // 6: mangahub.ListMangaResponse
-	(*UserProgressResponse)(nil),   // 7: mangahub.UserProgressResponse
-	(*UpdateProgressResponse)(nil), // 8: mangahub.UpdateProgressResponse
-	(*Manga)(nil),                  // 9: mangahub.Manga
-	(*UserProgress)(nil),           // 10: mangahub.UserProgress
+	(*GetMangaRequest)(nil),            // 0: mangahub.GetMangaRequest
+	(*ListMangaRequest)(nil),           // 1: mangahub.ListMangaRequest
+	(*SearchMangaRequest)(nil),         // 2: mangahub.SearchMangaRequest
+	(*GetUserProgressRequest)(nil),     // 3: mangahub.GetUserProgressRequest
+	(*UpdateProgressRequest)(nil),      // 4: mangahub.UpdateProgressRequest
+	(*WatchProgressRequest)(nil),       // 5: mangahub.WatchProgressRequest
+	(*WatchCatalogRequest)(nil),        // 6: mangahub.WatchCatalogRequest
+	(*RegisterRequest)(nil),            // 7: mangahub.RegisterRequest
+	(*LoginRequest)(nil),               // 8: mangahub.LoginRequest
+	(*RefreshRequest)(nil),             // 9: mangahub.RefreshRequest
+	(*AddLibraryEntryRequest)(nil),     // 10: mangahub.AddLibraryEntryRequest
+	(*ListLibraryRequest)(nil),         // 11: mangahub.ListLibraryRequest
+	(*UpdateLibraryStatusRequest)(nil), // 12: mangahub.UpdateLibraryStatusRequest
+	(*RemoveLibraryEntryRequest)(nil),  // 13: mangahub.RemoveLibraryEntryRequest
+	(*GetProfileRequest)(nil),          // 14: mangahub.GetProfileRequest
+	(*SyncRequest)(nil),                // 15: mangahub.SyncRequest
+	(*SyncRegister)(nil),               // 16: mangahub.SyncRegister
+	(*SyncProgressUpdate)(nil),         // 17: mangahub.SyncProgressUpdate
+	(*SyncPing)(nil),                   // 18: mangahub.SyncPing
+	(*MangaResponse)(nil),              // 19: mangahub.MangaResponse
+	(*ListMangaResponse)(nil),          // 20: mangahub.ListMangaResponse
+	(*UserProgressResponse)(nil),       // 21: mangahub.UserProgressResponse
+	(*UpdateProgressResponse)(nil),     // 22: mangahub.UpdateProgressResponse
+	(*ProgressEvent)(nil),              // 23: mangahub.ProgressEvent
+	(*CatalogEvent)(nil),               // 24: mangahub.CatalogEvent
+	(*SyncResponse)(nil),               // 25: mangahub.SyncResponse
+	(*SyncRegistered)(nil),             // 26: mangahub.SyncRegistered
+	(*SyncAck)(nil),                    // 27: mangahub.SyncAck
+	(*SyncPong)(nil),                   // 28: mangahub.SyncPong
+	(*SyncBroadcast)(nil),              // 29: mangahub.SyncBroadcast
+	(*SyncError)(nil),                  // 30: mangahub.SyncError
+	(*AuthResponse)(nil),               // 31: mangahub.AuthResponse
+	(*LibraryEntryResponse)(nil),       // 32: mangahub.LibraryEntryResponse
+	(*ListLibraryResponse)(nil),        // 33: mangahub.ListLibraryResponse
+	(*RemoveLibraryEntryResponse)(nil), // 34: mangahub.RemoveLibraryEntryResponse
+	(*ProfileResponse)(nil),            // 35: mangahub.ProfileResponse
+	(*Manga)(nil),                      // 36: mangahub.Manga
+	(*UserProgress)(nil),               // 37: mangahub.UserProgress
+	(*LibraryEntry)(nil),               // 38: mangahub.LibraryEntry
+	(*UserProfile)(nil),                // 39: mangahub.UserProfile
+	nil,                                // 40: mangahub.SyncBroadcast.DataEntry
 }
 var file_api_mangahub_proto_depIdxs = []int32{
-	9,  // 0: mangahub.MangaResponse.manga:type_name -> mangahub.Manga
-	9,  // 1: mangahub.ListMangaResponse.mangas:type_name -> mangahub.Manga
-	10, // 2: mangahub.UserProgressResponse.progress:type_name -> mangahub.UserProgress
-	10, // 3: mangahub.UpdateProgressResponse.progress:type_name -> mangahub.UserProgress
-	0,  // 4: mangahub.MangaService.GetManga:input_type -> mangahub.GetMangaRequest
-	1,  // 5: mangahub.MangaService.ListManga:input_type -> mangahub.ListMangaRequest
-	2,  // 6: mangahub.MangaService.SearchManga:input_type -> mangahub.SearchMangaRequest
-	3,  // 7: mangahub.MangaService.GetUserProgress:input_type -> mangahub.GetUserProgressRequest
-	4,  // 8: mangahub.MangaService.UpdateProgress:input_type -> mangahub.UpdateProgressRequest
-	5,  // 9: mangahub.MangaService.GetManga:output_type -> mangahub.MangaResponse
-	6,  // 10: mangahub.MangaService.ListManga:output_type -> mangahub.ListMangaResponse
-	6,  // 11: mangahub.MangaService.SearchManga:output_type -> mangahub.ListMangaResponse
-	7,  // 12: mangahub.MangaService.GetUserProgress:output_type -> mangahub.UserProgressResponse
-	8,  // 13: mangahub.MangaService.UpdateProgress:output_type -> mangahub.UpdateProgressResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	16, // 0: mangahub.SyncRequest.register:type_name -> mangahub.SyncRegister
+	17, // 1: mangahub.SyncRequest.progress_update:type_name -> mangahub.SyncProgressUpdate
+	18, // 2: mangahub.SyncRequest.ping:type_name -> mangahub.SyncPing
+	36, // 3: mangahub.MangaResponse.manga:type_name -> mangahub.Manga
+	36, // 4: mangahub.ListMangaResponse.mangas:type_name -> mangahub.Manga
+	37, // 5: mangahub.UserProgressResponse.progress:type_name -> mangahub.UserProgress
+	37, // 6: mangahub.UpdateProgressResponse.progress:type_name -> mangahub.UserProgress
+	37, // 7: mangahub.ProgressEvent.progress:type_name -> mangahub.UserProgress
+	36, // 8: mangahub.CatalogEvent.created:type_name -> mangahub.Manga
+	36, // 9: mangahub.CatalogEvent.updated:type_name -> mangahub.Manga
+	26, // 10: mangahub.SyncResponse.registered:type_name -> mangahub.SyncRegistered
+	27, // 11: mangahub.SyncResponse.ack:type_name -> mangahub.SyncAck
+	28, // 12: mangahub.SyncResponse.pong:type_name -> mangahub.SyncPong
+	29, // 13: mangahub.SyncResponse.broadcast:type_name -> mangahub.SyncBroadcast
+	30, // 14: mangahub.SyncResponse.error:type_name -> mangahub.SyncError
+	40, // 15: mangahub.SyncBroadcast.data:type_name -> mangahub.SyncBroadcast.DataEntry
+	38, // 16: mangahub.LibraryEntryResponse.entry:type_name -> mangahub.LibraryEntry
+	38, // 17: mangahub.ListLibraryResponse.entries:type_name -> mangahub.LibraryEntry
+	39, // 18: mangahub.ProfileResponse.user:type_name -> mangahub.UserProfile
+	0,  // 19: mangahub.MangaService.GetManga:input_type -> mangahub.GetMangaRequest
+	1,  // 20: mangahub.MangaService.ListManga:input_type -> mangahub.ListMangaRequest
+	2,  // 21: mangahub.MangaService.SearchManga:input_type -> mangahub.SearchMangaRequest
+	3,  // 22: mangahub.MangaService.GetUserProgress:input_type -> mangahub.GetUserProgressRequest
+	4,  // 23: mangahub.MangaService.UpdateProgress:input_type -> mangahub.UpdateProgressRequest
+	5,  // 24: mangahub.MangaService.WatchProgress:input_type -> mangahub.WatchProgressRequest
+	6,  // 25: mangahub.MangaService.WatchCatalog:input_type -> mangahub.WatchCatalogRequest
+	7,  // 26: mangahub.AuthService.Register:input_type -> mangahub.RegisterRequest
+	8,  // 27: mangahub.AuthService.Login:input_type -> mangahub.LoginRequest
+	9,  // 28: mangahub.AuthService.Refresh:input_type -> mangahub.RefreshRequest
+	10, // 29: mangahub.LibraryService.Add:input_type -> mangahub.AddLibraryEntryRequest
+	11, // 30: mangahub.LibraryService.List:input_type -> mangahub.ListLibraryRequest
+	12, // 31: mangahub.LibraryService.UpdateStatus:input_type -> mangahub.UpdateLibraryStatusRequest
+	13, // 32: mangahub.LibraryService.Remove:input_type -> mangahub.RemoveLibraryEntryRequest
+	14, // 33: mangahub.UserService.GetProfile:input_type -> mangahub.GetProfileRequest
+	15, // 34: mangahub.SyncService.Sync:input_type -> mangahub.SyncRequest
+	19, // 35: mangahub.MangaService.GetManga:output_type -> mangahub.MangaResponse
+	20, // 36: mangahub.MangaService.ListManga:output_type -> mangahub.ListMangaResponse
+	20, // 37: mangahub.MangaService.SearchManga:output_type -> mangahub.ListMangaResponse
+	21, // 38: mangahub.MangaService.GetUserProgress:output_type -> mangahub.UserProgressResponse
+	22, // 39: mangahub.MangaService.UpdateProgress:output_type -> mangahub.UpdateProgressResponse
+	23, // 40: mangahub.MangaService.WatchProgress:output_type -> mangahub.ProgressEvent
+	24, // 41: mangahub.MangaService.WatchCatalog:output_type -> mangahub.CatalogEvent
+	31, // 42: mangahub.AuthService.Register:output_type -> mangahub.AuthResponse
+	31, // 43: mangahub.AuthService.Login:output_type -> mangahub.AuthResponse
+	31, // 44: mangahub.AuthService.Refresh:output_type -> mangahub.AuthResponse
+	32, // 45: mangahub.LibraryService.Add:output_type -> mangahub.LibraryEntryResponse
+	33, // 46: mangahub.LibraryService.List:output_type -> mangahub.ListLibraryResponse
+	32, // 47: mangahub.LibraryService.UpdateStatus:output_type -> mangahub.LibraryEntryResponse
+	34, // 48: mangahub.LibraryService.Remove:output_type -> mangahub.RemoveLibraryEntryResponse
+	35, // 49: mangahub.UserService.GetProfile:output_type -> mangahub.ProfileResponse
+	25, // 50: mangahub.SyncService.Sync:output_type -> mangahub.SyncResponse
+	35, // [35:51] is the sub-list for method output_type
+	19, // [19:35] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_api_mangahub_proto_init() }
@@ -758,15 +2750,32 @@ func file_api_mangahub_proto_init() {
 	if File_api_mangahub_proto != nil {
 		return
 	}
+	file_api_mangahub_proto_msgTypes[15].OneofWrappers = []any{
+		(*SyncRequest_Register)(nil),
+		(*SyncRequest_ProgressUpdate)(nil),
+		(*SyncRequest_Ping)(nil),
+	}
+	file_api_mangahub_proto_msgTypes[24].OneofWrappers = []any{
+		(*CatalogEvent_Created)(nil),
+		(*CatalogEvent_Updated)(nil),
+		(*CatalogEvent_DeletedMangaId)(nil),
+	}
+	file_api_mangahub_proto_msgTypes[25].OneofWrappers = []any{
+		(*SyncResponse_Registered)(nil),
+		(*SyncResponse_Ack)(nil),
+		(*SyncResponse_Pong)(nil),
+		(*SyncResponse_Broadcast)(nil),
+		(*SyncResponse_Error)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_mangahub_proto_rawDesc), len(file_api_mangahub_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   41,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   5,
 		},
 		GoTypes:           file_api_mangahub_proto_goTypes,
 		DependencyIndexes: file_api_mangahub_proto_depIdxs,
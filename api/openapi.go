@@ -0,0 +1,9 @@
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI (Swagger 2.0) document of the /api/v2 REST
+// gateway, generated from mangahub.proto by protoc-gen-openapiv2
+//
+//go:embed mangahub.swagger.json
+var OpenAPI []byte
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mangahub/internal/problem"
+	"mangahub/internal/service"
+)
+
+// printError prints a failed command's error after prefix, one line per
+// invalid field for validation errors
+func printError(prefix string, err error) {
+	printFields(prefix, err.Error(), service.FieldsOf(err))
+}
+
+// printProblem prints the problem+json error reply of a REST call, falling
+// back to the raw body for replies that are not problems
+func printProblem(action string, status int, body []byte) {
+	var p problem.Problem
+	if err := json.Unmarshal(body, &p); err != nil || p.Detail == "" {
+		fmt.Printf("Error: Failed to %s (Status: %d)\n", action, status)
+		fmt.Printf("Response: %s\n", string(body))
+		return
+	}
+	printFields("Error", p.Detail, p.Errors)
+}
+
+func printFields(prefix, message string, fields []problem.FieldError) {
+	if len(fields) == 0 {
+		fmt.Printf("%s: %s\n", prefix, message)
+		return
+	}
+	fmt.Printf("%s:\n", prefix)
+	for _, field := range fields {
+		fmt.Printf("  %s: %s\n", field.Field, field.Message)
+	}
+}
@@ -10,23 +10,34 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"mangahub/api"
 	"mangahub/internal/auth"
+	"mangahub/internal/chat"
+	"mangahub/internal/config"
+	"mangahub/internal/events"
 	grpcService "mangahub/internal/grpc"
+	"mangahub/internal/health"
 	"mangahub/internal/library"
+	"mangahub/internal/logging"
 	"mangahub/internal/manga"
 	"mangahub/internal/middleware"
+	"mangahub/internal/presence"
 	"mangahub/internal/progress"
+	"mangahub/internal/ratelimit"
+	"mangahub/internal/service"
 	"mangahub/internal/tcp"
 	"mangahub/internal/udp"
 	"mangahub/internal/user"
@@ -35,9 +46,12 @@ import (
 	"mangahub/pkg/models"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -98,6 +112,14 @@ func main() {
 		} else {
 			fmt.Println("Missing library command. Available: add")
 		}
+	case "discover":
+		handleDiscover()
+	case "admin":
+		if len(os.Args) > 2 && os.Args[2] == "set-role" {
+			handleSetRole()
+		} else {
+			fmt.Println("Missing admin command. Available: set-role")
+		}
 	case "progress":
 		if len(os.Args) > 2 {
 			switch os.Args[2] {
@@ -127,6 +149,8 @@ func printHelp() {
 	fmt.Println("  mangahub manga info <manga-id>")
 	fmt.Println("  mangahub library add --manga-id <id> --status <status>")
 	fmt.Println("  mangahub progress update --manga-id <id> --chapter <number>")
+	fmt.Println("  mangahub discover [--timeout <duration>] [--group <addr:port>]")
+	fmt.Println("  mangahub admin set-role --username <name> --role <user|moderator|admin|service>")
 }
 
 func handleMangaInfo() {
@@ -140,10 +164,10 @@ func handleMangaInfo() {
 	db := database.ConnectDB()
 	defer db.Close()
 
-	repo := &manga.MangaRepository{DB: db}
-	m, err := repo.GetMangaByID(mangaID)
+	mangas := &service.MangaService{Store: &manga.MangaRepository{DB: db}}
+	m, err := mangas.Get(context.Background(), mangaID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if service.CodeOf(err) == service.NotFound {
 			fmt.Printf("Manga with ID \"%s\" not found.\n", mangaID)
 		} else {
 			log.Fatalf("Failed to get manga info: %v", err)
@@ -184,55 +208,24 @@ func handleMangaList() {
 	db := database.ConnectDB()
 	defer db.Close()
 
-	repo := &manga.MangaRepository{DB: db}
-	mangas, err := repo.GetAllManga()
+	mangas := &service.MangaService{Store: &manga.MangaRepository{DB: db}}
+	result, err := mangas.List(context.Background(), service.MangaQuery{Genre: *genre, Page: *page, Limit: *limit})
 	if err != nil {
 		log.Fatalf("Failed to list manga: %v", err)
 	}
 
-	// Filter
-	var filtered []models.Manga
-	if *genre != "" {
-		for _, m := range mangas {
-			found := false
-			for _, g := range m.Genres {
-				if strings.EqualFold(g, *genre) {
-					found = true
-					break
-				}
-			}
-			if found {
-				filtered = append(filtered, m)
-			}
-		}
-	} else {
-		filtered = mangas
-	}
-
-	// Paginate
-	total := len(filtered)
-	totalPages := (total + *limit - 1) / *limit
-	if totalPages == 0 {
-		totalPages = 1
-	}
-
-	start := (*page - 1) * *limit
-	end := start + *limit
-	if start >= total {
-		if total == 0 {
+	if len(result.Manga) == 0 {
+		if result.Total == 0 {
 			fmt.Println("No manga found.")
 		} else {
-			fmt.Printf("No manga found on page %d (Total pages: %d).\n", *page, totalPages)
+			fmt.Printf("No manga found on page %d (Total pages: %d).\n", *page, result.TotalPages)
 		}
 		return
 	}
-	if end > total {
-		end = total
-	}
 
-	fmt.Printf("Listing manga (Page %d/%d, Total: %d):\n", *page, totalPages, total)
+	fmt.Printf("Listing manga (Page %d/%d, Total: %d):\n", result.Page, result.TotalPages, result.Total)
 	fmt.Println("--------------------------------------------------")
-	for _, m := range filtered[start:end] {
+	for _, m := range result.Manga {
 		fmt.Printf("ID: %s\n", m.ID)
 		fmt.Printf("Title: %s\n", m.Title)
 		fmt.Printf("Author: %s\n", m.Author)
@@ -262,41 +255,20 @@ func handleMangaSearch() {
 	db := database.ConnectDB()
 	defer db.Close()
 
-	repo := &manga.MangaRepository{DB: db}
-	results, err := repo.SearchManga(query)
+	mangas := &service.MangaService{Store: &manga.MangaRepository{DB: db}}
+	result, err := mangas.Search(context.Background(), service.MangaQuery{Query: query, Genre: *genre, Status: *status})
 	if err != nil {
 		log.Fatalf("Failed to search manga: %v", err)
 	}
 
-	// Apply filters if provided
-	filtered := []models.Manga{}
-	for _, m := range results {
-		if *genre != "" {
-			found := false
-			for _, g := range m.Genres {
-				if strings.EqualFold(g, *genre) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
-		if *status != "" && !strings.EqualFold(m.Status, *status) {
-			continue
-		}
-		filtered = append(filtered, m)
-	}
-
-	if len(filtered) == 0 {
+	if result.Total == 0 {
 		fmt.Printf("No manga found matching \"%s\" with the given filters.\n", query)
 		return
 	}
 
-	fmt.Printf("Found %d results for \"%s\":\n", len(filtered), query)
+	fmt.Printf("Found %d results for \"%s\":\n", result.Total, query)
 	fmt.Println("--------------------------------------------------")
-	for _, m := range filtered {
+	for _, m := range result.Manga {
 		fmt.Printf("ID: %s\n", m.ID)
 		fmt.Printf("Title: %s\n", m.Title)
 		fmt.Printf("Author: %s\n", m.Author)
@@ -338,37 +310,21 @@ func handleRegister() {
 		return
 	}
 
-	if len(password) < 6 {
-		fmt.Println("Password must be at least 6 characters")
-		return
-	}
-
 	db := database.ConnectDB()
 	defer db.Close()
 
-	repo := &user.UserRepository{DB: db}
-
-	// Check if user exists
-	_, err := repo.GetUserByUsername(*username)
-	if err == nil {
-		fmt.Println("Username already exists")
-		return
-	}
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	users := &service.UserService{Store: &user.UserRepository{DB: db}}
+	newUser, err := users.Register(context.Background(), service.Registration{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+	})
 	if err != nil {
-		log.Fatalf("Failed to hash password: %v", err)
-	}
-
-	newUser := models.User{
-		ID:           uuid.New().String(),
-		Username:     *username,
-		Email:        *email,
-		PasswordHash: string(hashedPassword),
-	}
-
-	if err := repo.CreateUser(newUser); err != nil {
-		log.Fatalf("Failed to create user: %v", err)
+		if service.CodeOf(err) == service.Internal {
+			log.Fatalf("Failed to create user: %v", err)
+		}
+		printError("✗ Registration failed", err)
+		return
 	}
 
 	fmt.Println("✓ Account created successfully!")
@@ -405,43 +361,29 @@ func handleLogin() {
 	db := database.ConnectDB()
 	defer db.Close()
 
-	repo := &user.UserRepository{DB: db}
-
-	var user models.User
-	var err error
-
-	if *username != "" {
-		user, err = repo.GetUserByUsername(*username)
-	} else {
-		user, err = repo.GetUserByEmail(*email)
-	}
-
+	users := &service.UserService{Store: &user.UserRepository{DB: db}}
+	session, err := users.Login(context.Background(), service.Credentials{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+	})
 	if err != nil {
+		if service.CodeOf(err) == service.Internal {
+			log.Fatalf("Failed to log in: %v", err)
+		}
 		fmt.Println("Invalid username/email or password")
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		fmt.Println("Invalid username/email or password")
-		return
-	}
-
-	// Generate token and save it
-	token, err := auth.GenerateToken(user)
-	if err != nil {
-		log.Fatalf("Failed to generate token: %v", err)
-	}
-
 	// Save token to file
-	if err := saveToken(token); err != nil {
+	if err := saveToken(session.Token); err != nil {
 		log.Printf("Warning: Failed to save token: %v", err)
 	}
 
-	// Calculate expiry (24 hours from now)
-	expiry := time.Now().Add(24 * time.Hour).UTC().Format("2006-01-02 15:04:05 UTC")
+	expiry := session.Claims.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC")
 
 	fmt.Println("✓ Login successful!")
-	fmt.Printf("Welcome back, %s!\n", user.Username)
+	fmt.Printf("Welcome back, %s!\n", session.Claims.Username)
 	fmt.Println("Session Details:")
 	fmt.Printf(" Token expires: %s (24 hours)\n", expiry)
 	fmt.Println(" Permissions: read, write, sync")
@@ -504,7 +446,7 @@ func handleAuthStatus() {
 	repo := &user.UserRepository{DB: db}
 	var email string
 	if userID != "" {
-		if u, err := repo.GetUserByID(userID); err == nil {
+		if u, err := repo.GetUserByID(context.Background(), userID); err == nil {
 			email = u.Email
 		}
 	}
@@ -528,6 +470,21 @@ func handleAuthStatus() {
 }
 
 func runServer() {
+	cfg := config.Load()
+
+	// Every server logs JSON to stdout through the default logger; admins
+	// change the level at runtime through /api/v1/admin/log-level
+	logLevel := new(slog.LevelVar)
+	level, levelErr := logging.ParseLevel(cfg.LogLevel)
+	if levelErr == nil {
+		logLevel.Set(level)
+	}
+	logger := logging.New(os.Stdout, cfg.LogFormat, logLevel)
+	slog.SetDefault(logger)
+	if levelErr != nil {
+		logger.Warn("Invalid MANGAHUB_LOG_LEVEL, using info", "error", levelErr)
+	}
+
 	// Initialize database
 	db := database.ConnectDB()
 	defer db.Close()
@@ -537,27 +494,119 @@ func runServer() {
 	mangaRepo := &manga.MangaRepository{DB: db}
 	libraryRepo := &library.LibraryRepository{DB: db}
 	progressRepo := &progress.ProgressRepository{DB: db}
+	chatRepo := &chat.ChatRepository{DB: db}
+	presenceRepo := &presence.PresenceRepository{DB: db}
 
 	// Load initial manga data from JSON if database is empty
 	loadInitialMangaData(db, mangaRepo)
 
+	// Domain events reach every transport through the bus; presence
+	// aggregates each user's connections across them
+	bus := events.NewBus()
+	bus.Logger = logger
+	presenceTracker := presence.NewTracker(presenceRepo, bus)
+	presenceTracker.ReadingWindow = cfg.PresenceReadingWindow
+
+	// Rate limits share one store: the HTTP and gRPC APIs limit each user,
+	// or each IP without a token, and the TCP and WebSocket servers limit
+	// how often each IP connects
+	limitStore := ratelimit.NewMemoryStore()
+	limitDone := make(chan struct{})
+	go limitStore.RunCleanup(time.Minute, limitDone)
+	authLimit := ratelimit.Limit{Rate: cfg.AuthRateLimit, Burst: cfg.AuthRateBurst}
+	httpLimits := ratelimit.Policies{
+		Default: ratelimit.Limit{Rate: cfg.RateLimit, Burst: cfg.RateBurst},
+		Routes: map[string]ratelimit.Limit{
+			"POST /api/v1/auth/login":    authLimit,
+			"POST /api/v1/auth/register": authLimit,
+		},
+		Logger: logger,
+	}
+	grpcLimits := ratelimit.Policies{
+		Default: httpLimits.Default,
+		Routes: map[string]ratelimit.Limit{
+			"/mangahub.AuthService/Login":    authLimit,
+			"/mangahub.AuthService/Register": authLimit,
+		},
+		Logger: logger,
+	}
+	connectionLimiter := &ratelimit.Limiter{
+		Store:  limitStore,
+		Name:   "connection",
+		Limit:  ratelimit.Limit{Rate: cfg.ConnectionRate, Burst: cfg.ConnectionBurst},
+		Logger: logger,
+	}
+
 	// Initialize network servers
 	tcpServer := tcp.NewServer(":8081")
-	udpServer := udp.NewServer(":8082", "127.0.0.1", 8083)
+	tcpServer.Logger = logger.With("server", "tcp")
+	tcpServer.Router.Logger = logger.With("server", "sync")
+	tcpServer.Router.Presence = presenceTracker
+	tcpServer.ConnectionLimiter = connectionLimiter
+	udpServer := udp.NewServer(":8082", "239.255.77.77", 8083)
+	udpServer.Presence = presenceTracker
+	udpServer.Logger = logger.With("server", "udp")
+	udpServer.Services = map[string]string{
+		"http":      ":8080",
+		"tcp":       ":8081",
+		"grpc":      ":8084",
+		"websocket": ":8080/ws",
+	}
 	wsHub := websocket.NewHub()
+	wsHub.Logger = logger.With("server", "websocket")
+	// The WebSocket upgrade admits the browser origins the HTTP API does
+	corsPolicies := newCORSPolicies(cfg)
+	wsHub.CheckOrigin = websocket.OriginChecker(corsPolicies.For("/ws").AllowsOrigin)
+	wsHub.Store = chatRepo
+	wsHub.HistorySize = cfg.ChatHistorySize
+	wsHub.DirectStore = chatRepo
+	wsHub.Moderation = chatRepo
+	wsHub.Users = userRepo
+	wsHub.Filter = websocket.NewWordFilter(cfg.ChatBannedWords)
+	wsHub.MaxMessageLength = cfg.ChatMaxLength
+	wsHub.MessageRate = cfg.ChatMessageRate
+	wsHub.MessageBurst = cfg.ChatMessageBurst
+	wsHub.ConnectionLimiter = connectionLimiter
+	wsHub.Presence = presenceTracker
+	if err := wsHub.LoadSanctions(context.Background()); err != nil {
+		logger.Error("Failed to load chat sanctions", "error", err)
+	}
+	var redisBroker *websocket.RedisBroker
+	if cfg.RedisAddr != "" {
+		redisBroker = websocket.NewRedisBroker(cfg.RedisAddr, cfg.RedisChannel)
+		redisBroker.Logger = wsHub.Logger
+		wsHub.Broker = redisBroker
+		logger.Info("WebSocket hub sharing messages through Redis", "hub_id", wsHub.ID, "addr", cfg.RedisAddr)
+	}
+
+	bus.Subscribe("presence", presenceTracker.HandleEvent)
+	bus.Subscribe("tcp", tcpServer.HandleEvent)
+	bus.Subscribe("udp", udpServer.HandleEvent)
+	bus.Subscribe("websocket", wsHub.HandleEvent)
+
+	// gRPC watch streams read recent events from the feed so clients can
+	// resume after reconnecting
+	grpcFeed := events.NewLog(cfg.GRPCFeedSize)
+	bus.Subscribe("grpc-feed", grpcFeed.HandleEvent)
+
+	// The services own validation, authorization and events; the REST
+	// handlers, gRPC servers and CLI only adapt them
+	userService := &service.UserService{Store: userRepo, Logger: logger}
+	mangaService := &service.MangaService{Store: mangaRepo, Events: bus, Logger: logger}
+	libraryService := &service.LibraryService{Store: libraryRepo, Events: bus, Logger: logger}
+	progressService := &service.ProgressService{Store: progressRepo, Events: bus, Logger: logger}
 
 	// Initialize handlers
-	userHandler := &user.UserHandler{Repo: userRepo}
-	mangaHandler := &manga.MangaHandler{
-		Repo:      mangaRepo,
-		UDPServer: udpServer,
-	}
-	libraryHandler := &library.LibraryHandler{Repo: libraryRepo}
-	progressHandler := &progress.ProgressHandler{
-		Repo:      progressRepo,
-		TCPServer: tcpServer,
-		UDPServer: udpServer,
-	}
+	userHandler := &user.UserHandler{Service: userService}
+	mangaHandler := &manga.MangaHandler{Service: mangaService}
+	libraryHandler := &library.LibraryHandler{Service: libraryService}
+	chatHandler := &chat.ChatHandler{Hub: wsHub, Repo: chatRepo}
+	progressHandler := &progress.ProgressHandler{Service: progressService}
+	presenceHandler := &presence.PresenceHandler{Tracker: presenceTracker, Repo: presenceRepo}
+
+	// Prune old chat messages hourly
+	retentionDone := make(chan struct{})
+	go chat.RunRetention(chatRepo, cfg.ChatRetention, time.Hour, logger.With("task", "chat_retention"), retentionDone)
 
 	// Start network servers
 	var wg sync.WaitGroup
@@ -567,7 +616,7 @@ func runServer() {
 	go func() {
 		defer wg.Done()
 		if err := tcpServer.Start(); err != nil {
-			log.Printf("TCP Server error: %v", err)
+			logger.Error("TCP server failed", "error", err)
 		}
 	}()
 
@@ -575,7 +624,7 @@ func runServer() {
 	go func() {
 		defer wg.Done()
 		if err := udpServer.Start(); err != nil {
-			log.Printf("UDP Server error: %v", err)
+			logger.Error("UDP server failed", "error", err)
 		}
 	}()
 
@@ -588,88 +637,111 @@ func runServer() {
 	// Start gRPC server
 	grpcListener, err := net.Listen("tcp", ":8084")
 	if err != nil {
-		log.Fatalf("Failed to listen on gRPC port: %v", err)
-	}
-	grpcServer := grpc.NewServer()
+		logger.Error("Failed to listen on gRPC port", "error", err)
+		os.Exit(1)
+	}
+	grpcMetrics := grpcService.NewMetrics()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcService.UnaryRequestIDInterceptor(),
+			grpcService.UnaryLoggingInterceptor(logger.With("server", "grpc")),
+			grpcService.UnaryMetricsInterceptor(grpcMetrics),
+			grpcService.UnaryAuthInterceptor(),
+			grpcService.UnaryRateLimitInterceptor(limitStore, grpcLimits),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcService.StreamRequestIDInterceptor(),
+			grpcService.StreamLoggingInterceptor(logger.With("server", "grpc")),
+			grpcService.StreamMetricsInterceptor(grpcMetrics),
+			grpcService.StreamAuthInterceptor(),
+			grpcService.StreamRateLimitInterceptor(limitStore, grpcLimits),
+		),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    cfg.GRPCKeepaliveTime,
+			Timeout: cfg.GRPCKeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPCKeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+	)
+	// Closed on shutdown to end the streams GracefulStop would wait for
+	grpcShutdown := make(chan struct{})
 	grpcServiceServer := &grpcService.MangaServiceServer{
-		MangaRepo:    mangaRepo,
-		ProgressRepo: progressRepo,
+		Manga:    mangaService,
+		Progress: progressService,
+		Feed:     grpcFeed,
+		Shutdown: grpcShutdown,
 	}
 	api.RegisterMangaServiceServer(grpcServer, grpcServiceServer)
+	api.RegisterAuthServiceServer(grpcServer, &grpcService.AuthServiceServer{Users: userService})
+	api.RegisterLibraryServiceServer(grpcServer, &grpcService.LibraryServiceServer{Library: libraryService})
+	api.RegisterUserServiceServer(grpcServer, &grpcService.UserServiceServer{Users: userService})
+	api.RegisterSyncServiceServer(grpcServer, &grpcService.SyncServiceServer{Router: tcpServer.Router, Shutdown: grpcShutdown})
+	healthServer := grpcHealth.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	if cfg.GRPCReflection {
+		reflection.Register(grpcServer)
+	}
 
+	var grpcRunning atomic.Bool
 	go func() {
 		defer wg.Done()
-		log.Println("gRPC Server listening on :8084")
+		logger.Info("gRPC server listening", "address", ":8084")
+		grpcRunning.Store(true)
+		defer grpcRunning.Store(false)
 		if err := grpcServer.Serve(grpcListener); err != nil {
-			log.Printf("gRPC Server error: %v", err)
+			logger.Error("gRPC server failed", "error", err)
 		}
 	}()
 
-	// Initialize HTTP router
-	router := gin.Default()
-
-	// Middleware
-	router.Use(middleware.CORS())
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-
-	// Health check
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "MangaHub API is running",
-			"services": gin.H{
-				"http":      "running",
-				"tcp":       "running",
-				"udp":       "running",
-				"websocket": "running",
-				"grpc":      "running",
-			},
-		})
-	})
-
-	// API routes
-	api := router.Group("/api/v1")
-	{
-		// Auth routes (public)
-		authGroup := api.Group("/auth")
-		{
-			authGroup.POST("/register", userHandler.Register)
-			authGroup.POST("/login", userHandler.Login)
-		}
-
-		// Manga routes (public)
-		mangaGroup := api.Group("/manga")
-		{
-			mangaGroup.GET("", mangaHandler.GetAllManga)
-			mangaGroup.GET("/search", mangaHandler.SearchManga)
-			mangaGroup.GET("/:id", mangaHandler.GetMangaByID)
-			mangaGroup.POST("", auth.JWTAuthMiddleware(), mangaHandler.CreateManga) // Protected
-		}
-
-		// Library routes (protected)
-		libraryGroup := api.Group("/library")
-		libraryGroup.Use(auth.JWTAuthMiddleware())
-		{
-			libraryGroup.GET("", libraryHandler.GetUserLibrary)
-			libraryGroup.POST("", libraryHandler.AddToLibrary)
-			libraryGroup.PUT("/:id", libraryHandler.UpdateStatus)
-			libraryGroup.DELETE("/:id", libraryHandler.RemoveFromLibrary)
-		}
-
-		// Progress routes (protected)
-		progressGroup := api.Group("/progress")
-		progressGroup.Use(auth.JWTAuthMiddleware())
-		{
-			progressGroup.GET("", progressHandler.GetUserProgress)
-			progressGroup.GET("/:id", progressHandler.GetMangaProgress)
-			progressGroup.POST("", progressHandler.UpdateProgress)
-		}
+	// Health checks back both "/" and the gRPC health service
+	checker := health.NewChecker()
+	checker.Add("database", db.PingContext)
+	checker.Add("tcp", health.Running(tcpServer.Running))
+	checker.Add("udp", health.Running(udpServer.Running))
+	checker.Add("websocket", wsHub.Ping)
+	checker.Add("grpc", health.Running(grpcRunning.Load))
+	healthDone := make(chan struct{})
+	go checker.Watch(healthServer, cfg.HealthCheckInterval, healthDone)
+
+	// The /api/v2 REST gateway relays to the gRPC server
+	gatewayConn, err := grpc.NewClient("localhost:8084", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		logger.Error("Failed to create gRPC gateway client", "error", err)
+		os.Exit(1)
+	}
+	defer gatewayConn.Close()
+	gateway, err := grpcService.NewGateway(context.Background(), gatewayConn)
+	if err != nil {
+		logger.Error("Failed to create gRPC gateway", "error", err)
+		os.Exit(1)
 	}
 
-	// WebSocket endpoint
-	router.GET("/ws", func(c *gin.Context) {
-		websocket.HandleWebSocket(wsHub, c.Writer, c.Request)
+	// Initialize HTTP router
+	router, _, err := newRouter(routes{
+		user:     userHandler,
+		manga:    mangaHandler,
+		library:  libraryHandler,
+		progress: progressHandler,
+		chat:     chatHandler,
+		presence: presenceHandler,
+		health:   healthHandler(checker),
+		metrics:  grpcMetrics,
+		gateway:  gateway,
+		websocket: func(c *gin.Context) {
+			websocket.HandleWebSocket(wsHub, c.Writer, c.Request)
+		},
+		rateLimit:      middleware.RateLimit(limitStore, httpLimits),
+		trustedProxies: cfg.TrustedProxies,
+		cors:           corsPolicies,
+		logger:         logger.With("server", "http"),
+		logLevel:       logLevel,
 	})
+	if err != nil {
+		logger.Error("Failed to create HTTP router", "error", err)
+		os.Exit(1)
+	}
 
 	// Start HTTP server
 	httpServer := &http.Server{
@@ -678,9 +750,10 @@ func runServer() {
 	}
 
 	go func() {
-		log.Println("HTTP Server listening on :8080")
+		logger.Info("HTTP server listening", "address", ":8080")
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("HTTP Server error: %v", err)
+			logger.Error("HTTP server failed", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -689,7 +762,14 @@ func runServer() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down servers...")
+	logger.Info("Shutting down servers")
+
+	// Tell load balancers to stop sending traffic, and end gRPC watch and
+	// sync streams, including those relayed by the gateway, so neither the
+	// HTTP nor the gRPC server waits on them
+	close(healthDone)
+	healthServer.Shutdown()
+	close(grpcShutdown)
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -697,9 +777,12 @@ func runServer() {
 
 	// Stop HTTP server
 	if err := httpServer.Shutdown(ctx); err != nil {
-		log.Printf("HTTP Server shutdown error: %v", err)
+		logger.Error("HTTP server shutdown failed", "error", err)
 	}
 
+	// No more events once the handlers are done
+	bus.Close()
+
 	// Stop TCP server
 	tcpServer.Stop()
 
@@ -709,7 +792,89 @@ func runServer() {
 	// Stop gRPC server
 	grpcServer.GracefulStop()
 
-	log.Println("All servers stopped")
+	// Stop WebSocket hub
+	wsHub.Stop()
+	if redisBroker != nil {
+		redisBroker.Close()
+	}
+
+	// Stop chat retention and rate limit cleanup
+	close(retentionDone)
+	close(limitDone)
+
+	logger.Info("All servers stopped")
+}
+
+// handleSetRole changes a user's role directly in the database. The user
+// must log in again for the new role to be in their token.
+func handleSetRole() {
+	setRoleCmd := flag.NewFlagSet("set-role", flag.ExitOnError)
+	username := setRoleCmd.String("username", "", "Username")
+	role := setRoleCmd.String("role", "", "Role: user, moderator, admin or service")
+	setRoleCmd.Parse(os.Args[3:])
+
+	if *username == "" {
+		fmt.Println("Username is required")
+		setRoleCmd.Usage()
+		return
+	}
+	switch *role {
+	case models.RoleUser, models.RoleModerator, models.RoleAdmin, models.RoleService:
+	default:
+		fmt.Println("Role must be one of: user, moderator, admin, service")
+		return
+	}
+
+	db := database.ConnectDB()
+	defer db.Close()
+
+	repo := &user.UserRepository{DB: db}
+	u, err := repo.GetUserByUsername(context.Background(), *username)
+	if err != nil {
+		fmt.Printf("User \"%s\" not found.\n", *username)
+		return
+	}
+	if err := repo.SetRole(context.Background(), u.ID, *role); err != nil {
+		log.Fatalf("Failed to set role: %v", err)
+	}
+
+	fmt.Printf("✓ %s is now %s. They need to log in again for it to take effect.\n", u.Username, *role)
+}
+
+// handleDiscover listens for multicast announcements and prints the servers found.
+func handleDiscover() {
+	discoverCmd := flag.NewFlagSet("discover", flag.ExitOnError)
+	timeout := discoverCmd.Duration("timeout", 6*time.Second, "How long to listen for announcements")
+	group := discoverCmd.String("group", udp.DefaultGroupAddress, "Multicast group address")
+	discoverCmd.Parse(os.Args[2:])
+
+	fmt.Printf("Listening for MangaHub servers on %s for %s...\n", *group, timeout.String())
+
+	servers, err := udp.Discover(*group, *timeout)
+	if err != nil {
+		fmt.Printf("✗ Discovery failed: %v\n", err)
+		return
+	}
+
+	if len(servers) == 0 {
+		fmt.Println("No MangaHub servers found on the local network.")
+		return
+	}
+
+	fmt.Printf("Found %d server(s):\n", len(servers))
+	fmt.Println("--------------------------------------------------")
+	for _, server := range servers {
+		fmt.Printf("Server:  %s (%s)\n", server.Name, server.Address)
+		names := make([]string, 0, len(server.Services))
+		for name := range server.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %-10s %s\n", name+":", server.Services[name])
+		}
+		fmt.Println("--------------------------------------------------")
+	}
 }
 
 func loadInitialMangaData(db *sql.DB, mangaRepo *manga.MangaRepository) {
@@ -723,18 +888,18 @@ func loadInitialMangaData(db *sql.DB, mangaRepo *manga.MangaRepository) {
 	// Load from JSON file
 	mangaList, err := manga.LoadMangaData()
 	if err != nil {
-		log.Printf("Warning: Could not load initial manga data: %v", err)
+		slog.Warn("Could not load initial manga data", "error", err)
 		return
 	}
 
 	// Insert into database
 	for _, m := range mangaList {
-		if err := mangaRepo.CreateManga(m); err != nil {
-			log.Printf("Warning: Could not insert manga %s: %v", m.ID, err)
+		if err := mangaRepo.CreateManga(context.Background(), m); err != nil {
+			slog.Warn("Could not insert manga", "manga_id", m.ID, "error", err)
 		}
 	}
 
-	log.Printf("Loaded %d manga from JSON file", len(mangaList))
+	slog.Info("Loaded manga from JSON file", "count", len(mangaList))
 }
 
 // Token storage functions
@@ -801,11 +966,10 @@ func makeAuthenticatedRequest(method, url string, body interface{}) (*http.Respo
 func handleLibraryAdd() {
 	addCmd := flag.NewFlagSet("add", flag.ExitOnError)
 	mangaID := addCmd.String("manga-id", "", "Manga ID")
-	status := addCmd.String("status", "", "Reading status (reading, plan_to_read, completed, on_hold, dropped)")
-	rating := addCmd.Int("rating", 0, "Rating (0-10)")
+	status := addCmd.String("status", "", "Reading status: "+strings.Join(service.LibraryStatuses, ", "))
 
 	if len(os.Args) < 4 {
-		fmt.Println("Usage: mangahub library add --manga-id <id> --status <status> [--rating <0-10>]")
+		fmt.Println("Usage: mangahub library add --manga-id <id> [--status <status>]")
 		return
 	}
 	addCmd.Parse(os.Args[3:])
@@ -817,37 +981,15 @@ func handleLibraryAdd() {
 	}
 
 	if *status == "" {
-		*status = "plan_to_read" // Default status
+		*status = service.DefaultLibraryStatus
 	}
-
-	// Validate status
-	validStatuses := []string{"reading", "plan_to_read", "completed", "on_hold", "dropped"}
-	valid := false
-	for _, s := range validStatuses {
-		if *status == s {
-			valid = true
-			break
-		}
-	}
-	if !valid {
-		fmt.Printf("Error: Invalid status '%s'. Valid statuses: %v\n", *status, validStatuses)
-		return
-	}
-
-	// Validate rating
-	if *rating < 0 || *rating > 10 {
-		fmt.Println("Error: Rating must be between 0 and 10")
+	if err := service.ValidateLibraryStatus(*status); err != nil {
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
 
 	// Make API request
-	reqBody := map[string]interface{}{
-		"manga_id": *mangaID,
-		"status":   *status,
-	}
-	if *rating > 0 {
-		reqBody["rating"] = *rating
-	}
+	reqBody := library.AddRequest{MangaID: *mangaID, Status: *status}
 
 	resp, err := makeAuthenticatedRequest("POST", "http://localhost:8080/api/v1/library", reqBody)
 	if err != nil {
@@ -863,15 +1005,7 @@ func handleLibraryAdd() {
 	}
 
 	if resp.StatusCode != http.StatusCreated {
-		var errorResp map[string]interface{}
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			if errMsg, ok := errorResp["error"].(string); ok {
-				fmt.Printf("Error: %s\n", errMsg)
-				return
-			}
-		}
-		fmt.Printf("Error: Failed to add manga to library (Status: %d)\n", resp.StatusCode)
-		fmt.Printf("Response: %s\n", string(body))
+		printProblem("add manga to library", resp.StatusCode, body)
 		return
 	}
 
@@ -884,9 +1018,6 @@ func handleLibraryAdd() {
 	fmt.Println("✓ Manga added to library successfully!")
 	fmt.Printf("Manga ID: %s\n", *mangaID)
 	fmt.Printf("Status: %s\n", *status)
-	if *rating > 0 {
-		fmt.Printf("Rating: %d\n", *rating)
-	}
 	if id, ok := libraryEntry["id"].(string); ok {
 		fmt.Printf("Library Entry ID: %s\n", id)
 	}
@@ -909,17 +1040,14 @@ func handleProgressUpdate() {
 		return
 	}
 
-	if *chapter <= 0 {
-		fmt.Println("Error: --chapter must be a positive number")
+	if err := service.ValidateChapter(*chapter); err != nil {
+		fmt.Printf("Error: --%v\n", err)
 		updateCmd.Usage()
 		return
 	}
 
 	// Make API request
-	reqBody := map[string]interface{}{
-		"manga_id": *mangaID,
-		"chapter":  *chapter,
-	}
+	reqBody := progress.UpdateRequest{MangaID: *mangaID, Chapter: *chapter}
 
 	resp, err := makeAuthenticatedRequest("POST", "http://localhost:8080/api/v1/progress", reqBody)
 	if err != nil {
@@ -935,15 +1063,7 @@ func handleProgressUpdate() {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var errorResp map[string]interface{}
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			if errMsg, ok := errorResp["error"].(string); ok {
-				fmt.Printf("Error: %s\n", errMsg)
-				return
-			}
-		}
-		fmt.Printf("Error: Failed to update progress (Status: %d)\n", resp.StatusCode)
-		fmt.Printf("Response: %s\n", string(body))
+		printProblem("update progress", resp.StatusCode, body)
 		return
 	}
 
@@ -961,31 +1081,6 @@ func handleProgressUpdate() {
 	}
 }
 
-// validatePasswordStrength enforces a stronger password policy.
-func validatePasswordStrength(pw string) error {
-	if len(pw) < 8 {
-		return fmt.Errorf("Password must be at least 8 characters with mixed case and numbers")
-	}
-
-	var hasUpper, hasLower, hasDigit bool
-	for _, r := range pw {
-		switch {
-		case r >= 'A' && r <= 'Z':
-			hasUpper = true
-		case r >= 'a' && r <= 'z':
-			hasLower = true
-		case r >= '0' && r <= '9':
-			hasDigit = true
-		}
-	}
-
-	if !hasUpper || !hasLower || !hasDigit {
-		return fmt.Errorf("Password must be at least 8 characters with mixed case and numbers")
-	}
-
-	return nil
-}
-
 // handleChangePassword allows an authenticated user to change their password.
 func handleChangePassword() {
 	// Require existing valid token
@@ -997,8 +1092,8 @@ func handleChangePassword() {
 		return
 	}
 
-	userID, _, _, err := auth.ParseToken(token)
-	if err != nil || userID == "" {
+	claims, err := auth.ParseTokenClaims(token)
+	if err != nil || claims.UserID == "" {
 		fmt.Println("✗ Change password failed: Invalid or expired session")
 		fmt.Println("Please login again:")
 		fmt.Println("  mangahub auth login --username <username>")
@@ -1025,40 +1120,28 @@ func handleChangePassword() {
 		return
 	}
 
-	if err := validatePasswordStrength(newPassword); err != nil {
-		fmt.Printf("✗ Change password failed: %s\n", err.Error())
-		return
-	}
-
 	db := database.ConnectDB()
 	defer db.Close()
 
-	repo := &user.UserRepository{DB: db}
-	u, err := repo.GetUserByID(userID)
-	if err != nil {
+	users := &service.UserService{Store: &user.UserRepository{DB: db}}
+	err = users.ChangePassword(auth.WithUser(context.Background(), claims), currentPassword, newPassword)
+	switch service.CodeOf(err) {
+	case service.Invalid:
+		printError("✗ Change password failed", err)
+		return
+	case service.NotFound:
 		fmt.Println("✗ Change password failed: User not found")
 		return
-	}
-
-	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(currentPassword)); err != nil {
+	case service.PermissionDenied:
 		fmt.Println("✗ Change password failed: Invalid current password")
 		fmt.Println("The current password you entered is incorrect.")
 		return
 	}
-
-	// Hash new password and update
-	newHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		fmt.Println("✗ Change password failed: Internal error")
 		return
 	}
 
-	if err := repo.UpdatePassword(userID, string(newHash)); err != nil {
-		fmt.Println("✗ Change password failed: Internal error")
-		return
-	}
-
 	fmt.Println("✓ Password changed successfully!")
 	fmt.Println("Your new password is now active.")
 	fmt.Println("For security, you may need to login again in some clients.")
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/chat"
+	"mangahub/internal/config"
+	"mangahub/internal/health"
+	"mangahub/internal/library"
+	"mangahub/internal/logging"
+	"mangahub/internal/manga"
+	"mangahub/internal/middleware"
+	"mangahub/internal/openapi"
+	"mangahub/internal/presence"
+	"mangahub/internal/problem"
+	"mangahub/internal/progress"
+	"mangahub/internal/user"
+	"mangahub/internal/websocket"
+	"mangahub/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// specPath serves the OpenAPI document of the routes below
+const specPath = "/api/v1/openapi.json"
+
+// routes holds what the HTTP router serves
+type routes struct {
+	user     *user.UserHandler
+	manga    *manga.MangaHandler
+	library  *library.LibraryHandler
+	progress *progress.ProgressHandler
+	chat     *chat.ChatHandler
+	presence *presence.PresenceHandler
+
+	health    gin.HandlerFunc
+	metrics   http.Handler
+	gateway   http.Handler
+	websocket gin.HandlerFunc
+
+	// rateLimit limits calls to /api/v1; nil leaves them unlimited
+	rateLimit gin.HandlerFunc
+	// trustedProxies may set X-Forwarded-For; no proxy is trusted when
+	// empty
+	trustedProxies []string
+	// cors decides which browser origins may call each route
+	cors middleware.CORSPolicies
+	// logger writes the access log; nil uses the default logger
+	logger *slog.Logger
+	// logLevel is the level admins change at runtime
+	logLevel *slog.LevelVar
+}
+
+// newCORSPolicies lets the configured origins call the API and read its
+// rate limit and request ID headers. The OpenAPI document may be read from
+// any origin, so tools hosted elsewhere can load it.
+func newCORSPolicies(cfg config.Config) middleware.CORSPolicies {
+	policy := middleware.CORSPolicy{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "Cache-Control", "X-Requested-With", middleware.RequestIDHeader},
+		ExposedHeaders:   []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", middleware.RequestIDHeader},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}
+	public := middleware.CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+		MaxAge:         cfg.CORSMaxAge,
+	}
+	return middleware.CORSPolicies{
+		Default: policy,
+		Routes: map[string]middleware.CORSPolicy{
+			specPath: public,
+		},
+	}
+}
+
+// healthResponse is the reply of the health check
+type healthResponse struct {
+	Message  string            `json:"message"`
+	Services map[string]string `json:"services"`
+}
+
+// healthHandler reports the state of every service checked by checker
+func healthHandler(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		results := checker.Run(c.Request.Context())
+		services := map[string]string{"http": "running"}
+		for _, result := range results {
+			services[result.Name] = "running"
+			if !result.Healthy() {
+				services[result.Name] = "down: " + result.Error.Error()
+			}
+		}
+		if !health.Healthy(results) {
+			c.JSON(http.StatusServiceUnavailable, healthResponse{Message: "MangaHub API is degraded", Services: services})
+			return
+		}
+		c.JSON(http.StatusOK, healthResponse{Message: "MangaHub API is running", Services: services})
+	}
+}
+
+// logLevelRequest is the body of the log level route and its reply
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required" enum:"debug,info,warn,error"`
+}
+
+// logLevelHandler reports the log level, or changes it on PUT
+func logLevelHandler(level *slog.LevelVar) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodPut {
+			var req logLevelRequest
+			if !problem.BindJSON(c, &req) {
+				return
+			}
+			parsed, err := logging.ParseLevel(req.Level)
+			if err != nil {
+				message := "Level must be one of debug, info, warn or error"
+				problem.Abort(c, problem.New(http.StatusBadRequest, message, problem.FieldError{Field: "level", Message: message}))
+				return
+			}
+			level.Set(parsed)
+			slog.InfoContext(c.Request.Context(), "Log level changed", "level", parsed.String(), "user_id", auth.GetUserID(c))
+		}
+		c.JSON(http.StatusOK, logLevelRequest{Level: strings.ToLower(level.Level().String())})
+	}
+}
+
+// newRouter registers every HTTP route and documents it in the returned
+// spec. Only the /api/v2 gateway is left out; it is described by the
+// OpenAPI document generated from the proto. It fails when a trusted proxy
+// is not a valid IP or CIDR.
+func newRouter(r routes) (*gin.Engine, *openapi.Spec, error) {
+	router := gin.New()
+	if err := router.SetTrustedProxies(r.trustedProxies); err != nil {
+		return nil, nil, err
+	}
+
+	// Middleware. The request ID comes first so every log line of the
+	// request, including the access log and panics, carries it.
+	router.Use(middleware.RequestID())
+	router.Use(middleware.AccessLog(r.logger))
+	router.Use(middleware.Recovery(r.logger))
+	router.Use(middleware.ClientIP())
+	router.Use(middleware.CORS(r.cors))
+
+	spec := openapi.New(openapi.Info{
+		Title:       "MangaHub API",
+		Version:     "1.0",
+		Description: "REST API of the MangaHub server. Authenticated routes take the token from POST /api/v1/auth/login as a bearer token. Calls are rate limited per user, or per IP without a token; responses carry RateLimit-* headers and 429 replies a Retry-After header. Every response carries an X-Request-ID header, echoing a valid one sent with the request, that identifies the request in the server logs.",
+	})
+	root := spec.Router(&router.RouterGroup)
+
+	root.GET("/", openapi.Route{
+		Summary:  "Health check",
+		Response: healthResponse{},
+		Errors:   []int{http.StatusServiceUnavailable},
+	}, r.health)
+
+	// gRPC call counts and latencies for Prometheus
+	root.GET("/metrics", openapi.Route{
+		Summary:     "gRPC call metrics in the Prometheus text format",
+		Response:    "",
+		ContentType: "text/plain",
+	}, gin.WrapH(r.metrics))
+
+	// The /api/v2 REST gateway is generated from the proto and calls the
+	// gRPC services, so it shares their implementation and interceptors
+	router.Any("/api/v2/*path", gin.WrapH(r.gateway))
+
+	// WebSocket endpoint
+	root.GET("/ws", openapi.Route{
+		Summary:     "Open a WebSocket chat connection",
+		Description: "Upgrades to a WebSocket. The token is read from the Authorization header or the token query parameter.",
+		Query:       []openapi.Param{{Name: "token", Description: "bearer token, for clients that cannot set headers"}},
+		Status:      http.StatusSwitchingProtocols,
+		Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests},
+	}, r.websocket)
+
+	// API documentation
+	root.GET("/docs", openapi.Route{
+		Summary:     "API explorer",
+		Response:    "",
+		ContentType: "text/html",
+	}, gin.WrapH(openapi.Explorer(specPath)))
+	root.GET(specPath, openapi.Route{
+		Summary:  "This OpenAPI document",
+		Response: map[string]interface{}{},
+	}, gin.WrapH(spec))
+
+	// API routes
+	var limits []gin.HandlerFunc
+	if r.rateLimit != nil {
+		limits = append(limits, r.rateLimit)
+	}
+	api := root.Group("/api/v1", "", limits...)
+	{
+		// Auth routes (public)
+		authGroup := api.Group("/auth", "auth")
+		{
+			authGroup.POST("/register", openapi.Route{
+				Summary:  "Register a user",
+				Body:     user.RegisterRequest{},
+				Status:   http.StatusCreated,
+				Response: user.RegisterResponse{},
+				Errors:   []int{http.StatusBadRequest, http.StatusConflict},
+			}, r.user.Register)
+			authGroup.POST("/login", openapi.Route{
+				Summary:  "Log in and get a token",
+				Body:     user.LoginRequest{},
+				Response: user.LoginResponse{},
+				Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+			}, r.user.Login)
+		}
+
+		// Manga routes (public)
+		mangaGroup := api.Group("/manga", "manga")
+		{
+			mangaGroup.GET("", openapi.Route{
+				Summary:  "List the catalog",
+				Response: []models.Manga{},
+			}, r.manga.GetAllManga)
+			mangaGroup.GET("/search", openapi.Route{
+				Summary:  "Search the catalog",
+				Query:    []openapi.Param{{Name: "q", Description: "search terms", Required: true}},
+				Response: []models.Manga{},
+				Errors:   []int{http.StatusBadRequest},
+			}, r.manga.SearchManga)
+			mangaGroup.GET("/:id", openapi.Route{
+				Summary:  "Get a manga",
+				Response: models.Manga{},
+				Errors:   []int{http.StatusNotFound},
+			}, r.manga.GetMangaByID)
+			mangaGroup.POST("", openapi.Route{
+				Summary:     "Add a manga to the catalog",
+				Description: "An ID is generated when none is given.",
+				Auth:        true,
+				Body:        models.Manga{},
+				Status:      http.StatusCreated,
+				Response:    models.Manga{},
+				Errors:      []int{http.StatusBadRequest, http.StatusConflict},
+			}, auth.JWTAuthMiddleware(), r.manga.CreateManga)
+			mangaGroup.PUT("/:id", openapi.Route{
+				Summary:  "Replace a manga (admins)",
+				Auth:     true,
+				Body:     models.Manga{},
+				Response: models.Manga{},
+				Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+			}, auth.JWTAuthMiddleware(), auth.RequireRole(models.RoleAdmin), r.manga.UpdateManga)
+			mangaGroup.DELETE("/:id", openapi.Route{
+				Summary:  "Delete a manga (admins)",
+				Auth:     true,
+				Response: openapi.MessageResponse{},
+				Errors:   []int{http.StatusForbidden, http.StatusNotFound},
+			}, auth.JWTAuthMiddleware(), auth.RequireRole(models.RoleAdmin), r.manga.DeleteManga)
+		}
+
+		// Library routes (protected)
+		libraryGroup := api.Group("/library", "library").Authenticated(auth.JWTAuthMiddleware())
+		{
+			libraryGroup.GET("", openapi.Route{
+				Summary:  "List the caller's library",
+				Response: []models.UserLibrary{},
+			}, r.library.GetUserLibrary)
+			libraryGroup.POST("", openapi.Route{
+				Summary:  "Add a manga to the caller's library",
+				Body:     library.AddRequest{},
+				Status:   http.StatusCreated,
+				Response: models.UserLibrary{},
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}, r.library.AddToLibrary)
+			libraryGroup.PUT("/:id", openapi.Route{
+				Summary:  "Change the status of a manga in the caller's library",
+				Body:     library.UpdateStatusRequest{},
+				Response: openapi.MessageResponse{},
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}, r.library.UpdateStatus)
+			libraryGroup.DELETE("/:id", openapi.Route{
+				Summary:  "Remove a manga from the caller's library",
+				Response: openapi.MessageResponse{},
+				Errors:   []int{http.StatusNotFound},
+			}, r.library.RemoveFromLibrary)
+		}
+
+		// Progress routes (protected)
+		progressGroup := api.Group("/progress", "progress").Authenticated(auth.JWTAuthMiddleware())
+		{
+			progressGroup.GET("", openapi.Route{
+				Summary:  "List the caller's reading progress",
+				Response: []models.UserProgress{},
+			}, r.progress.GetUserProgress)
+			progressGroup.GET("/:id", openapi.Route{
+				Summary:  "Get the caller's progress in a manga",
+				Response: models.UserProgress{},
+				Errors:   []int{http.StatusNotFound},
+			}, r.progress.GetMangaProgress)
+			progressGroup.POST("", openapi.Route{
+				Summary:  "Record the chapter the caller reached",
+				Body:     progress.UpdateRequest{},
+				Response: models.UserProgress{},
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}, r.progress.UpdateProgress)
+		}
+
+		chatPage := []openapi.Param{
+			{Name: "before", Description: "ID of the oldest message already fetched", Type: "integer"},
+			{Name: "limit", Description: "1 to 100, 50 by default", Type: "integer"},
+		}
+
+		// Chat room routes (protected)
+		roomGroup := api.Group("/rooms", "chat").Authenticated(auth.JWTAuthMiddleware())
+		{
+			roomGroup.GET("", openapi.Route{
+				Summary:  "List the active chat rooms",
+				Response: []websocket.RoomInfo{},
+			}, r.chat.ListRooms)
+			roomGroup.GET("/:id", openapi.Route{
+				Summary:  "Get the members of a chat room",
+				Response: chat.RoomResponse{},
+				Errors:   []int{http.StatusNotFound},
+			}, r.chat.GetRoom)
+			roomGroup.GET("/:id/messages", openapi.Route{
+				Summary:  "Page back through a room's messages, oldest first",
+				Query:    chatPage,
+				Response: []models.ChatMessage{},
+				Errors:   []int{http.StatusBadRequest},
+			}, r.chat.GetMessages)
+		}
+
+		// Direct message routes (protected)
+		conversationGroup := api.Group("/conversations", "chat").Authenticated(auth.JWTAuthMiddleware())
+		{
+			conversationGroup.GET("", openapi.Route{
+				Summary:  "List the caller's conversations, most recent first",
+				Response: []models.Conversation{},
+			}, r.chat.ListConversations)
+			conversationGroup.GET("/:id/messages", openapi.Route{
+				Summary:  "Page back through the direct messages exchanged with a user",
+				Query:    chatPage,
+				Response: []models.DirectMessage{},
+				Errors:   []int{http.StatusBadRequest},
+			}, r.chat.GetConversation)
+		}
+
+		// Presence routes (protected)
+		presenceGroup := api.Group("/presence", "presence").Authenticated(auth.JWTAuthMiddleware())
+		{
+			presenceGroup.GET("", openapi.Route{
+				Summary:  "List the online users visible to the caller",
+				Response: []models.Presence{},
+			}, r.presence.ListOnline)
+			presenceGroup.GET("/:id", openapi.Route{
+				Summary:  "Get the presence of a user",
+				Response: models.Presence{},
+				Errors:   []int{http.StatusNotFound},
+			}, r.presence.GetPresence)
+			presenceGroup.PUT("/visibility", openapi.Route{
+				Summary:  "Change who may see the caller online",
+				Body:     presence.VisibilityRequest{},
+				Response: presence.VisibilityRequest{},
+				Errors:   []int{http.StatusBadRequest},
+			}, r.presence.SetVisibility)
+		}
+
+		// Friends routes (protected)
+		friendGroup := api.Group("/friends", "presence").Authenticated(auth.JWTAuthMiddleware())
+		{
+			friendGroup.GET("", openapi.Route{
+				Summary:  "List the caller's friends",
+				Response: []models.Friend{},
+			}, r.presence.ListFriends)
+			friendGroup.POST("", openapi.Route{
+				Summary:  "Add a friend by user ID or username",
+				Body:     presence.AddFriendRequest{},
+				Status:   http.StatusCreated,
+				Response: presence.AddFriendResponse{},
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}, r.presence.AddFriend)
+			friendGroup.DELETE("/:id", openapi.Route{
+				Summary:  "Remove a friend",
+				Response: openapi.MessageResponse{},
+				Errors:   []int{http.StatusNotFound},
+			}, r.presence.RemoveFriend)
+		}
+
+		// Moderation routes (moderators and admins)
+		moderationGroup := api.Group("/moderation", "moderation").
+			Authenticated(auth.JWTAuthMiddleware(), auth.RequireRole(models.RoleModerator, models.RoleAdmin))
+		{
+			moderationGroup.GET("/actions", openapi.Route{
+				Summary: "List moderation actions, newest first",
+				Query: []openapi.Param{
+					{Name: "user_id", Description: "only actions targeting this user"},
+					{Name: "limit", Description: "1 to 200, 50 by default", Type: "integer"},
+				},
+				Response: []models.ModerationAction{},
+				Errors:   []int{http.StatusBadRequest, http.StatusForbidden},
+			}, r.chat.ListModerationActions)
+		}
+
+		// Admin routes
+		adminGroup := api.Group("/admin", "admin").
+			Authenticated(auth.JWTAuthMiddleware(), auth.RequireRole(models.RoleAdmin))
+		{
+			adminGroup.GET("/log-level", openapi.Route{
+				Summary:  "Get the server log level",
+				Response: logLevelRequest{},
+				Errors:   []int{http.StatusForbidden},
+			}, logLevelHandler(r.logLevel))
+			adminGroup.PUT("/log-level", openapi.Route{
+				Summary:     "Change the server log level",
+				Description: "Takes effect immediately for every server and lasts until the next restart.",
+				Body:        logLevelRequest{},
+				Response:    logLevelRequest{},
+				Errors:      []int{http.StatusBadRequest, http.StatusForbidden},
+			}, logLevelHandler(r.logLevel))
+		}
+	}
+
+	// Answer CORS preflights for every path above
+	middleware.HandleOptions(router)
+
+	return router, spec, nil
+}
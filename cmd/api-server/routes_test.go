@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/chat"
+	"mangahub/internal/config"
+	"mangahub/internal/health"
+	"mangahub/internal/library"
+	"mangahub/internal/manga"
+	"mangahub/internal/openapi"
+	"mangahub/internal/presence"
+	"mangahub/internal/progress"
+	"mangahub/internal/user"
+	"mangahub/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouter(t *testing.T) (*gin.Engine, *openapi.Spec) {
+	gin.SetMode(gin.TestMode)
+	router, spec, err := newRouter(testRoutes())
+	require.NoError(t, err)
+	return router, spec
+}
+
+// testRoutes are routes without services, for testing the router itself
+func testRoutes() routes {
+	return routes{
+		user:      &user.UserHandler{},
+		manga:     &manga.MangaHandler{},
+		library:   &library.LibraryHandler{},
+		progress:  &progress.ProgressHandler{},
+		chat:      &chat.ChatHandler{},
+		presence:  &presence.PresenceHandler{},
+		health:    healthHandler(health.NewChecker()),
+		metrics:   http.NotFoundHandler(),
+		gateway:   http.NotFoundHandler(),
+		websocket: func(c *gin.Context) {},
+		cors:      newCORSPolicies(config.Config{AllowedOrigins: []string{"https://*.example.com"}, CORSMaxAge: time.Minute}),
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		logLevel:  new(slog.LevelVar),
+	}
+}
+
+// TestRoutesDocumented fails when a route is registered on gin directly
+// instead of through the openapi Group
+func TestRoutesDocumented(t *testing.T) {
+	router, spec := testRouter(t)
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, "/api/v2/") {
+			// described by the document generated from the proto
+			continue
+		}
+		if route.Method == http.MethodOptions {
+			// CORS preflights
+			continue
+		}
+		assert.True(t, spec.Has(route.Method, route.Path), "%s %s is not in the OpenAPI document", route.Method, route.Path)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	router, _ := testRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, specPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Enum []string `json:"enum"`
+				} `json:"properties"`
+				Required []string `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Contains(t, doc.Paths["/api/v1/manga/{id}"], "put")
+	assert.Contains(t, doc.Paths["/api/v1/library"], "post")
+
+	add := doc.Components.Schemas["AddRequest"]
+	assert.Equal(t, []string{"manga_id"}, add.Required)
+	assert.Equal(t, []string{"reading", "completed", "plan_to_read", "dropped"}, add.Properties["status"].Enum)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), specPath)
+}
+
+func TestCORS(t *testing.T) {
+	router, _ := testRouter(t)
+	preflight := func(path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("/api/v1/library/berserk", "https://app.example.com")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Equal(t, "60", w.Header().Get("Access-Control-Max-Age"))
+
+	w = preflight("/api/v1/library", "https://evil.test")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Paths without routes are not found rather than approved
+	w = preflight("/api/v1/nothing", "https://app.example.com")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The OpenAPI document may be read from anywhere
+	w = preflight(specPath, "https://evil.test")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://evil.test", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+
+	// Plain OPTIONS requests list the methods of the path
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/api/v1/library/berserk", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "DELETE, OPTIONS, PUT", w.Header().Get("Allow"))
+}
+
+func TestLogLevel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := testRoutes()
+	router, _, err := newRouter(r)
+	require.NoError(t, err)
+
+	call := func(method, role, body string) *httptest.ResponseRecorder {
+		token, err := auth.GenerateToken(models.User{ID: "u1", Username: "u1", Role: role})
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, "/api/v1/admin/log-level", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call(http.MethodGet, models.RoleAdmin, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"info"}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+
+	w = call(http.MethodPut, models.RoleAdmin, `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"level":"debug"}`, w.Body.String())
+	assert.Equal(t, slog.LevelDebug, r.logLevel.Level())
+
+	w = call(http.MethodPut, models.RoleAdmin, `{"level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, slog.LevelDebug, r.logLevel.Level())
+
+	w = call(http.MethodPut, models.RoleUser, `{"level":"error"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, slog.LevelDebug, r.logLevel.Level())
+}
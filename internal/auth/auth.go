@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"mangahub/internal/problem"
 	"mangahub/pkg/models"
 
 	"github.com/gin-gonic/gin"
@@ -25,9 +26,14 @@ func CheckPassword(hash, password string) error {
 }
 
 func GenerateToken(user models.User) (string, error) {
+	role := user.Role
+	if role == "" {
+		role = models.RoleUser
+	}
 	claims := jwt.MapClaims{
 		"user_id":  user.ID,
 		"username": user.Username,
+		"role":     role,
 		"exp":      time.Now().Add(time.Hour * 24).Unix(),
 		"iat":      time.Now().Unix(),
 	}
@@ -35,8 +41,17 @@ func GenerateToken(user models.User) (string, error) {
 	return token.SignedString(JWTSecret)
 }
 
-// ParseToken parses a JWT token string and returns basic user information and expiry time.
-func ParseToken(tokenString string) (userID string, username string, expiry time.Time, err error) {
+// Claims is the identity carried by a token
+type Claims struct {
+	UserID    string
+	Username  string
+	Role      string
+	ExpiresAt time.Time
+}
+
+// ParseTokenClaims validates a JWT token string and returns its claims.
+// Tokens issued before roles existed get RoleUser.
+func ParseTokenClaims(tokenString string) (Claims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
@@ -44,26 +59,63 @@ func ParseToken(tokenString string) (userID string, username string, expiry time
 		return JWTSecret, nil
 	})
 	if err != nil || !token.Valid {
-		return "", "", time.Time{}, errors.New("invalid or expired token")
+		return Claims{}, errors.New("invalid or expired token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", "", time.Time{}, errors.New("invalid token claims")
+		return Claims{}, errors.New("invalid token claims")
 	}
 
-	if uid, ok := claims["user_id"].(string); ok {
-		userID = uid
+	var claims Claims
+	if uid, ok := mapClaims["user_id"].(string); ok {
+		claims.UserID = uid
+	}
+	if un, ok := mapClaims["username"].(string); ok {
+		claims.Username = un
 	}
-	if un, ok := claims["username"].(string); ok {
-		username = un
+	claims.Role = models.RoleUser
+	if role, ok := mapClaims["role"].(string); ok && role != "" {
+		claims.Role = role
+	}
+	if exp, ok := mapClaims["exp"].(float64); ok {
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
 	}
 
-	if exp, ok := claims["exp"].(float64); ok {
-		expiry = time.Unix(int64(exp), 0)
+	return claims, nil
+}
+
+// ParseToken parses a JWT token string and returns basic user information and expiry time.
+func ParseToken(tokenString string) (userID string, username string, expiry time.Time, err error) {
+	claims, err := ParseTokenClaims(tokenString)
+	if err != nil {
+		return "", "", time.Time{}, err
 	}
+	return claims.UserID, claims.Username, claims.ExpiresAt, nil
+}
 
-	return userID, username, expiry, nil
+// IsModerator reports whether a role may moderate chat
+func IsModerator(role string) bool {
+	return role == models.RoleModerator || role == models.RoleAdmin
+}
+
+// roleRanks orders roles by authority; roles not listed rank as users
+var roleRanks = map[string]int{
+	models.RoleModerator: 1,
+	models.RoleAdmin:     2,
+	models.RoleService:   2,
+}
+
+// Outranks reports whether role has more authority than other, as a
+// moderator needs over the users they sanction
+func Outranks(role, other string) bool {
+	return roleRanks[role] > roleRanks[other]
+}
+
+// CanActForAnyUser reports whether a role may read and change other users'
+// data, as admins and service accounts do
+func CanActForAnyUser(role string) bool {
+	return role == models.RoleAdmin || role == models.RoleService
 }
 
 // JWT Middleware for protecting routes
@@ -71,15 +123,13 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			problem.Abort(c, problem.New(http.StatusUnauthorized, "Authorization header required"))
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			problem.Abort(c, problem.New(http.StatusUnauthorized, "Invalid authorization header format"))
 			return
 		}
 
@@ -92,20 +142,29 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			problem.Abort(c, problem.New(http.StatusUnauthorized, "Invalid or expired token"))
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+			problem.Abort(c, problem.New(http.StatusUnauthorized, "Invalid token claims"))
 			return
 		}
 
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = models.RoleUser
+		}
+
 		c.Set("user_id", claims["user_id"])
 		c.Set("username", claims["username"])
+		c.Set("role", role)
+
+		// The services read the caller from the request context
+		userID, _ := claims["user_id"].(string)
+		username, _ := claims["username"].(string)
+		c.Request = c.Request.WithContext(WithUser(c.Request.Context(), Claims{UserID: userID, Username: username, Role: role}))
 		c.Next()
 	}
 }
@@ -118,3 +177,23 @@ func GetUserID(c *gin.Context) string {
 	}
 	return userID.(string)
 }
+
+// GetRole extracts the user's role from context (must be called after JWTAuthMiddleware)
+func GetRole(c *gin.Context) string {
+	return c.GetString("role")
+}
+
+// RequireRole only lets users with one of the given roles through (must be
+// used after JWTAuthMiddleware)
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		problem.Abort(c, problem.New(http.StatusForbidden, "Insufficient permissions"))
+	}
+}
@@ -0,0 +1,16 @@
+package auth
+
+import "context"
+
+type claimsKey struct{}
+
+// WithUser returns a context carrying the claims of the authenticated user
+func WithUser(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// UserFromContext returns the claims stored by WithUser
+func UserFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
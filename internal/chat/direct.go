@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"mangahub/pkg/models"
+)
+
+const directMessageColumns = "id, sender_id, sender_name, recipient_id, content, read_at, created_at"
+
+// SaveDirectMessage stores a direct message. It returns sql.ErrNoRows when
+// the recipient does not exist.
+func (r *ChatRepository) SaveDirectMessage(ctx context.Context, msg models.DirectMessage) (int64, error) {
+	var exists int
+	if err := r.DB.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = ?", msg.RecipientID).Scan(&exists); err != nil {
+		return 0, err
+	}
+
+	result, err := r.DB.ExecContext(ctx, "INSERT INTO direct_messages (sender_id, sender_name, recipient_id, content) VALUES (?, ?, ?, ?)",
+		msg.SenderID, msg.SenderName, msg.RecipientID, msg.Content)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetUndelivered returns the messages sent to a user while they were
+// offline, oldest first
+func (r *ChatRepository) GetUndelivered(ctx context.Context, userID string) ([]models.DirectMessage, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+directMessageColumns+" FROM direct_messages WHERE recipient_id = ? AND delivered = 0 ORDER BY id",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDirectMessages(rows)
+}
+
+// MarkDelivered flags messages as delivered so they are not sent again on
+// the recipient's next connection
+func (r *ChatRepository) MarkDelivered(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	_, err := r.DB.ExecContext(ctx, "UPDATE direct_messages SET delivered = 1 WHERE id IN ("+placeholders+")", args...)
+	return err
+}
+
+// MarkRead marks the messages peerID sent to userID up to and including
+// upToID as read (all of them when upToID is 0) and returns how many changed
+func (r *ChatRepository) MarkRead(ctx context.Context, userID, peerID string, upToID int64) (int64, error) {
+	query := "UPDATE direct_messages SET read_at = CURRENT_TIMESTAMP, delivered = 1 WHERE recipient_id = ? AND sender_id = ? AND read_at IS NULL"
+	args := []interface{}{userID, peerID}
+	if upToID > 0 {
+		query += " AND id <= ?"
+		args = append(args, upToID)
+	}
+
+	result, err := r.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetConversations lists the users userID exchanged direct messages with,
+// most recent conversation first, with the number of unread messages in each
+func (r *ChatRepository) GetConversations(ctx context.Context, userID string) ([]models.Conversation, error) {
+	query := `
+	SELECT c.peer_id, COALESCE(u.username, ''), c.unread,
+		m.id, m.sender_id, m.sender_name, m.recipient_id, m.content, m.read_at, m.created_at
+	FROM (
+		SELECT CASE WHEN sender_id = ? THEN recipient_id ELSE sender_id END AS peer_id,
+			MAX(id) AS last_id,
+			SUM(CASE WHEN recipient_id = ? AND read_at IS NULL THEN 1 ELSE 0 END) AS unread
+		FROM direct_messages
+		WHERE sender_id = ? OR recipient_id = ?
+		GROUP BY peer_id
+	) c
+	JOIN direct_messages m ON m.id = c.last_id
+	LEFT JOIN users u ON u.id = c.peer_id
+	ORDER BY m.id DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversations := []models.Conversation{}
+	for rows.Next() {
+		var c models.Conversation
+		var senderName, readAt sql.NullString
+		m := &c.LastMessage
+		if err := rows.Scan(&c.UserID, &c.Username, &c.UnreadCount,
+			&m.ID, &m.SenderID, &senderName, &m.RecipientID, &m.Content, &readAt, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.SenderName = senderName.String
+		m.ReadAt = readAt.String
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
+
+// GetDirectMessagesBefore returns up to limit messages exchanged between
+// userID and peerID with an ID below beforeID (all when beforeID is 0),
+// oldest first
+func (r *ChatRepository) GetDirectMessagesBefore(ctx context.Context, userID, peerID string, beforeID int64, limit int) ([]models.DirectMessage, error) {
+	query := "SELECT " + directMessageColumns + " FROM direct_messages WHERE ((sender_id = ? AND recipient_id = ?) OR (sender_id = ? AND recipient_id = ?))"
+	args := []interface{}{userID, peerID, peerID, userID}
+	if beforeID > 0 {
+		query += " AND id < ?"
+		args = append(args, beforeID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanDirectMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse into chronological order
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+func scanDirectMessages(rows *sql.Rows) ([]models.DirectMessage, error) {
+	messages := []models.DirectMessage{}
+	for rows.Next() {
+		var m models.DirectMessage
+		var senderName, readAt sql.NullString
+		if err := rows.Scan(&m.ID, &m.SenderID, &senderName, &m.RecipientID, &m.Content, &readAt, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.SenderName = senderName.String
+		m.ReadAt = readAt.String
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"net/http"
+	"strconv"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/problem"
+	"mangahub/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChatHandler struct {
+	Hub  *websocket.Hub
+	Repo *ChatRepository
+}
+
+// RoomResponse is the reply of GetRoom
+type RoomResponse struct {
+	websocket.RoomInfo
+	Members []websocket.Member `json:"members"`
+}
+
+func (h *ChatHandler) ListRooms(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Hub.Rooms())
+}
+
+func (h *ChatHandler) GetRoom(c *gin.Context) {
+	room := c.Param("id")
+	members := h.Hub.RoomMembers(room)
+	if len(members) == 0 {
+		problem.Abort(c, problem.New(http.StatusNotFound, "Room not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, RoomResponse{
+		RoomInfo: websocket.RoomInfo{Room: room, Occupants: len(members)},
+		Members:  members,
+	})
+}
+
+// GetMessages pages back through a room's history. Messages are returned
+// oldest first; pass the first message's ID as ?before= to get older ones.
+func (h *ChatHandler) GetMessages(c *gin.Context) {
+	before, limit, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.Repo.GetMessagesBefore(c.Request.Context(), c.Param("id"), before, limit)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch messages"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// ListConversations lists the users the caller exchanged direct messages
+// with, most recent first, with unread counts
+func (h *ChatHandler) ListConversations(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	conversations, err := h.Repo.GetConversations(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch conversations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+// GetConversation pages back through the direct messages exchanged with the
+// user in :id, paginated like GetMessages
+func (h *ChatHandler) GetConversation(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	before, limit, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.Repo.GetDirectMessagesBefore(c.Request.Context(), userID, c.Param("id"), before, limit)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch messages"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// ListModerationActions returns the moderation audit log, newest first,
+// optionally filtered with ?user_id= to the actions targeting one user
+func (h *ChatHandler) ListModerationActions(c *gin.Context) {
+	limit := 50
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 200 {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "Query parameter 'limit' must be between 1 and 200"))
+			return
+		}
+		limit = n
+	}
+
+	actions, err := h.Repo.GetModerationActions(c.Request.Context(), c.Query("user_id"), limit)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch moderation actions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+// pageParams parses the ?before= and ?limit= query parameters, replying with
+// 400 and returning ok=false when they are invalid
+func pageParams(c *gin.Context) (before int64, limit int, ok bool) {
+	if value := c.Query("before"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "Query parameter 'before' must be a positive message ID"))
+			return 0, 0, false
+		}
+		before = id
+	}
+
+	limit = 50
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 100 {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "Query parameter 'limit' must be between 1 and 100"))
+			return 0, 0, false
+		}
+		limit = n
+	}
+
+	return before, limit, true
+}
@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+
+	"mangahub/pkg/models"
+)
+
+// SaveModerationAction records an action in the audit log
+func (r *ChatRepository) SaveModerationAction(ctx context.Context, action models.ModerationAction) (int64, error) {
+	var expiresAt interface{}
+	if action.ExpiresAt != "" {
+		expiresAt = action.ExpiresAt
+	}
+
+	result, err := r.DB.ExecContext(ctx, `INSERT INTO moderation_actions
+		(action, moderator_id, moderator_name, target_id, message_id, reason, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		action.Action, action.ModeratorID, action.ModeratorName, action.TargetID, action.MessageID, action.Reason, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetModerationActions returns up to limit audit log entries, newest first,
+// optionally only those targeting targetID
+func (r *ChatRepository) GetModerationActions(ctx context.Context, targetID string, limit int) ([]models.ModerationAction, error) {
+	query := "SELECT id, action, moderator_id, moderator_name, target_id, message_id, reason, expires_at, created_at FROM moderation_actions"
+	var args []interface{}
+	if targetID != "" {
+		query += " WHERE target_id = ?"
+		args = append(args, targetID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	return r.queryModerationActions(ctx, query, args...)
+}
+
+// GetSanctions returns every mute, unmute, ban and unban in the order they
+// happened, so the current sanctions can be rebuilt after a restart
+func (r *ChatRepository) GetSanctions(ctx context.Context) ([]models.ModerationAction, error) {
+	return r.queryModerationActions(ctx, `SELECT id, action, moderator_id, moderator_name, target_id, message_id, reason, expires_at, created_at
+		FROM moderation_actions WHERE action IN ('mute', 'unmute', 'ban', 'unban') ORDER BY id`)
+}
+
+// DeleteMessage removes a chat message and returns it. It returns
+// sql.ErrNoRows when the message does not exist.
+func (r *ChatRepository) DeleteMessage(ctx context.Context, id int64) (models.ChatMessage, error) {
+	var m models.ChatMessage
+	var username sql.NullString
+	err := r.DB.QueryRowContext(ctx, "SELECT id, room, user_id, username, content, created_at FROM chat_messages WHERE id = ?", id).
+		Scan(&m.ID, &m.Room, &m.UserID, &username, &m.Content, &m.CreatedAt)
+	if err != nil {
+		return m, err
+	}
+	m.Username = username.String
+
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM chat_messages WHERE id = ?", id)
+	return m, err
+}
+
+func (r *ChatRepository) queryModerationActions(ctx context.Context, query string, args ...interface{}) ([]models.ModerationAction, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []models.ModerationAction{}
+	for rows.Next() {
+		var a models.ModerationAction
+		var moderatorName, targetID, reason, expiresAt sql.NullString
+		var messageID sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.Action, &a.ModeratorID, &moderatorName, &targetID, &messageID, &reason, &expiresAt, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.ModeratorName = moderatorName.String
+		a.TargetID = targetID.String
+		a.MessageID = messageID.Int64
+		a.Reason = reason.String
+		a.ExpiresAt = expiresAt.String
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
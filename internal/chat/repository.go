@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"mangahub/pkg/models"
+	"time"
+)
+
+type ChatRepository struct {
+	DB *sql.DB
+}
+
+func (r *ChatRepository) SaveMessage(ctx context.Context, msg models.ChatMessage) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, "INSERT INTO chat_messages (room, user_id, username, content) VALUES (?, ?, ?, ?)",
+		msg.Room, msg.UserID, msg.Username, msg.Content)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// GetRecentMessages returns the last limit messages of a room, oldest first
+func (r *ChatRepository) GetRecentMessages(ctx context.Context, room string, limit int) ([]models.ChatMessage, error) {
+	return r.GetMessagesBefore(ctx, room, 0, limit)
+}
+
+// GetMessagesBefore returns up to limit messages of a room with an ID below
+// beforeID (all messages when beforeID is 0), oldest first
+func (r *ChatRepository) GetMessagesBefore(ctx context.Context, room string, beforeID int64, limit int) ([]models.ChatMessage, error) {
+	query := "SELECT id, room, user_id, username, content, created_at FROM chat_messages WHERE room = ?"
+	args := []interface{}{room}
+	if beforeID > 0 {
+		query += " AND id < ?"
+		args = append(args, beforeID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []models.ChatMessage{}
+	for rows.Next() {
+		var m models.ChatMessage
+		var username sql.NullString
+		if err := rows.Scan(&m.ID, &m.Room, &m.UserID, &username, &m.Content, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.Username = username.String
+		messages = append(messages, m)
+	}
+
+	// Reverse into chronological order
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, rows.Err()
+}
+
+// PruneOlderThan deletes messages created before cutoff and returns how many were removed
+func (r *ChatRepository) PruneOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM chat_messages WHERE created_at < ?", cutoff.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
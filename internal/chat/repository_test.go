@@ -0,0 +1,205 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"mangahub/pkg/models"
+	"testing"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open in-memory db: %v", err)
+	}
+
+	createChatMessagesTable := `
+	CREATE TABLE IF NOT EXISTS chat_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		room TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		username TEXT,
+		content TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS direct_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sender_id TEXT NOT NULL,
+		sender_name TEXT,
+		recipient_id TEXT NOT NULL,
+		content TEXT NOT NULL,
+		delivered INTEGER DEFAULT 0,
+		read_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS moderation_actions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT NOT NULL,
+		moderator_id TEXT NOT NULL,
+		moderator_name TEXT,
+		target_id TEXT,
+		message_id INTEGER,
+		reason TEXT,
+		expires_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		username TEXT UNIQUE NOT NULL
+	);
+	INSERT INTO users (id, username) VALUES ('u1', 'alice'), ('u2', 'bob'), ('u3', 'carol');`
+	_, err = db.Exec(createChatMessagesTable)
+	if err != nil {
+		t.Fatalf("Failed to create chat_messages table: %v", err)
+	}
+
+	return db
+}
+
+func TestChatRepository_Pagination(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &ChatRepository{DB: db}
+	for _, content := range []string{"one", "two", "three", "four"} {
+		_, err := repo.SaveMessage(ctx, models.ChatMessage{Room: "manga:naruto", UserID: "u1", Username: "alice", Content: content})
+		require.NoError(t, err)
+	}
+	_, err := repo.SaveMessage(ctx, models.ChatMessage{Room: "lobby", UserID: "u1", Content: "elsewhere"})
+	require.NoError(t, err)
+
+	recent, err := repo.GetRecentMessages(ctx, "manga:naruto", 2)
+	assert.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, "three", recent[0].Content)
+	assert.Equal(t, "four", recent[1].Content)
+
+	older, err := repo.GetMessagesBefore(ctx, "manga:naruto", recent[0].ID, 10)
+	assert.NoError(t, err)
+	require.Len(t, older, 2)
+	assert.Equal(t, "one", older[0].Content)
+	assert.Equal(t, "two", older[1].Content)
+}
+
+func TestChatRepository_PruneOlderThan(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &ChatRepository{DB: db}
+	_, err := db.Exec("INSERT INTO chat_messages (room, user_id, content, created_at) VALUES ('lobby', 'u1', 'old', '2020-01-01 00:00:00')")
+	require.NoError(t, err)
+	_, err = repo.SaveMessage(ctx, models.ChatMessage{Room: "lobby", UserID: "u1", Content: "new"})
+	require.NoError(t, err)
+
+	removed, err := repo.PruneOlderThan(ctx, time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	messages, err := repo.GetRecentMessages(ctx, "lobby", 10)
+	assert.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "new", messages[0].Content)
+}
+
+func TestChatRepository_DirectMessages(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &ChatRepository{DB: db}
+	send := func(from, to, content string) int64 {
+		id, err := repo.SaveDirectMessage(ctx, models.DirectMessage{SenderID: from, RecipientID: to, Content: content})
+		require.NoError(t, err)
+		return id
+	}
+	first := send("u2", "u1", "hi alice")
+	send("u1", "u2", "hi bob")
+	send("u2", "u1", "are you there?")
+	send("u3", "u1", "hello from carol")
+
+	_, err := repo.SaveDirectMessage(ctx, models.DirectMessage{SenderID: "u1", RecipientID: "nobody", Content: "?"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Everything sent to alice is pending until delivered
+	pending, err := repo.GetUndelivered(ctx, "u1")
+	assert.NoError(t, err)
+	require.Len(t, pending, 3)
+	require.NoError(t, repo.MarkDelivered(ctx, []int64{pending[0].ID, pending[1].ID}))
+	pending, err = repo.GetUndelivered(ctx, "u1")
+	assert.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "hello from carol", pending[0].Content)
+
+	conversations, err := repo.GetConversations(ctx, "u1")
+	assert.NoError(t, err)
+	require.Len(t, conversations, 2)
+	assert.Equal(t, "u3", conversations[0].UserID)
+	assert.Equal(t, "carol", conversations[0].Username)
+	assert.Equal(t, 1, conversations[0].UnreadCount)
+	assert.Equal(t, "u2", conversations[1].UserID)
+	assert.Equal(t, 2, conversations[1].UnreadCount)
+	assert.Equal(t, "are you there?", conversations[1].LastMessage.Content)
+
+	// Reading up to the first message leaves the later one unread
+	read, err := repo.MarkRead(ctx, "u1", "u2", first)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), read)
+	conversations, err = repo.GetConversations(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, conversations[1].UnreadCount)
+
+	messages, err := repo.GetDirectMessagesBefore(ctx, "u1", "u2", 0, 10)
+	assert.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, "hi alice", messages[0].Content)
+	assert.NotEmpty(t, messages[0].ReadAt)
+	assert.Empty(t, messages[2].ReadAt)
+}
+
+func TestChatRepository_Moderation(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &ChatRepository{DB: db}
+	msgID, err := repo.SaveMessage(ctx, models.ChatMessage{Room: "lobby", UserID: "u2", Content: "spam"})
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteMessage(ctx, msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, "lobby", deleted.Room)
+	_, err = repo.DeleteMessage(ctx, msgID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	for _, action := range []models.ModerationAction{
+		{Action: "mute", ModeratorID: "m1", TargetID: "u2", ExpiresAt: "2030-01-01 00:00:00"},
+		{Action: "delete_message", ModeratorID: "m1", MessageID: msgID},
+		{Action: "ban", ModeratorID: "m1", TargetID: "u2", Reason: "spam"},
+	} {
+		_, err := repo.SaveModerationAction(ctx, action)
+		require.NoError(t, err)
+	}
+
+	actions, err := repo.GetModerationActions(ctx, "", 10)
+	assert.NoError(t, err)
+	require.Len(t, actions, 3)
+	assert.Equal(t, "ban", actions[0].Action)
+	assert.Equal(t, msgID, actions[1].MessageID)
+
+	targeted, err := repo.GetModerationActions(ctx, "u2", 10)
+	assert.NoError(t, err)
+	assert.Len(t, targeted, 2)
+
+	sanctions, err := repo.GetSanctions(ctx)
+	assert.NoError(t, err)
+	require.Len(t, sanctions, 2)
+	assert.Equal(t, "mute", sanctions[0].Action)
+	assert.Contains(t, sanctions[0].ExpiresAt, "2030-01-01")
+}
@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"mangahub/internal/logging"
+)
+
+// RunRetention prunes chat messages older than retention every interval
+// until done is closed, logging with logger (nil uses the default logger).
+// A zero retention keeps messages forever.
+func RunRetention(repo *ChatRepository, retention, interval time.Duration, logger *slog.Logger, done <-chan struct{}) {
+	if retention <= 0 {
+		return
+	}
+	logger = logging.Or(logger)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		prune(repo, retention, logger)
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func prune(repo *ChatRepository, retention time.Duration, logger *slog.Logger) {
+	ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+	removed, err := repo.PruneOlderThan(ctx, time.Now().Add(-retention))
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to prune chat messages", "error", err)
+		return
+	}
+	if removed > 0 {
+		logger.InfoContext(ctx, "Pruned chat messages", "removed", removed, "retention", retention.String())
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds server settings that can be changed without rebuilding
+type Config struct {
+	// AllowedOrigins lists the browser origins allowed to call the HTTP API
+	// and open WebSocket connections. "https://*.example.com" allows every
+	// subdomain and "*" any origin.
+	AllowedOrigins []string
+	// CORSAllowCredentials lets browsers send cookies and HTTP
+	// authentication with cross-origin requests. Bearer tokens do not need
+	// it. It is ignored when AllowedOrigins contains "*".
+	CORSAllowCredentials bool
+	// CORSMaxAge is how long browsers may cache preflight responses.
+	CORSMaxAge time.Duration
+
+	// ChatHistorySize is how many messages are replayed when joining a room.
+	ChatHistorySize int
+	// ChatRetention is how long chat messages are kept; 0 keeps them forever.
+	ChatRetention time.Duration
+	// ChatMaxLength bounds chat and direct message content in characters.
+	ChatMaxLength int
+	// ChatMessageRate is how many messages per second a connection may send,
+	// with bursts of up to ChatMessageBurst.
+	ChatMessageRate  float64
+	ChatMessageBurst int
+	// ChatBannedWords are masked in chat and direct messages.
+	ChatBannedWords []string
+
+	// RedisAddr is the Redis server WebSocket hubs share messages through,
+	// so several instances can run behind a load balancer. Empty keeps
+	// messages within this process.
+	RedisAddr string
+	// RedisChannel is the pub/sub channel used on RedisAddr.
+	RedisChannel string
+
+	// PresenceReadingWindow is how long after a progress update a user is
+	// shown as currently reading that manga.
+	PresenceReadingWindow time.Duration
+
+	// GRPCFeedSize is how many recent events gRPC watch streams can resume
+	// from.
+	GRPCFeedSize int
+	// GRPCKeepaliveTime is how long a gRPC connection may be idle before the
+	// server pings it, and GRPCKeepaliveTimeout how long it waits for the
+	// answer before closing it.
+	GRPCKeepaliveTime    time.Duration
+	GRPCKeepaliveTimeout time.Duration
+	// GRPCKeepaliveMinTime is the shortest ping interval clients may use;
+	// clients pinging more often are disconnected.
+	GRPCKeepaliveMinTime time.Duration
+	// GRPCReflection enables the gRPC reflection service so tools like
+	// grpcurl can discover the API.
+	GRPCReflection bool
+
+	// HealthCheckInterval is how often subsystem health is refreshed for
+	// the gRPC health service.
+	HealthCheckInterval time.Duration
+
+	// LogLevel is the initial minimum level logged: debug, info, warn or
+	// error. Admins can change it while the server runs.
+	LogLevel string
+	// LogFormat is "json", or "text" for logfmt-style lines.
+	LogFormat string
+
+	// RateLimit is how many requests per second each user, or each client
+	// IP when not logged in, may make to the HTTP and gRPC APIs, with
+	// bursts of up to RateBurst. A rate of 0 disables rate limiting.
+	RateLimit float64
+	RateBurst int
+	// AuthRateLimit and AuthRateBurst are the stricter limits on login and
+	// registration, which guard against password guessing.
+	AuthRateLimit float64
+	AuthRateBurst int
+	// ConnectionRate is how many TCP and WebSocket connections per second
+	// each client IP may open, with bursts of up to ConnectionBurst.
+	ConnectionRate  float64
+	ConnectionBurst int
+	// TrustedProxies are the addresses of reverse proxies whose
+	// X-Forwarded-For header gives the client IP. Other clients cannot
+	// escape their limits by setting the header.
+	TrustedProxies []string
+}
+
+// Load reads the configuration from MANGAHUB_* environment variables,
+// falling back to defaults suitable for local development
+func Load() Config {
+	cfg := Config{
+		AllowedOrigins: getList("MANGAHUB_ALLOWED_ORIGINS", []string{
+			"http://localhost:8080",
+			"http://127.0.0.1:8080",
+			"http://localhost:3000",
+		}),
+		CORSAllowCredentials: getBool("MANGAHUB_CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAge:           getDuration("MANGAHUB_CORS_MAX_AGE", 10*time.Minute),
+
+		ChatHistorySize:  getInt("MANGAHUB_CHAT_HISTORY", 50),
+		ChatRetention:    getDuration("MANGAHUB_CHAT_RETENTION", 30*24*time.Hour),
+		ChatMaxLength:    getInt("MANGAHUB_CHAT_MAX_LENGTH", 1000),
+		ChatMessageRate:  getFloat("MANGAHUB_CHAT_RATE", 1),
+		ChatMessageBurst: getInt("MANGAHUB_CHAT_BURST", 5),
+		ChatBannedWords:  getList("MANGAHUB_CHAT_BANNED_WORDS", nil),
+		RedisAddr:        getString("MANGAHUB_REDIS_ADDR", ""),
+		RedisChannel:     getString("MANGAHUB_REDIS_CHANNEL", "mangahub:websocket"),
+
+		PresenceReadingWindow: getDuration("MANGAHUB_PRESENCE_READING_WINDOW", 30*time.Minute),
+
+		GRPCFeedSize:         getInt("MANGAHUB_GRPC_FEED_SIZE", 1000),
+		GRPCKeepaliveTime:    getDuration("MANGAHUB_GRPC_KEEPALIVE_TIME", 2*time.Minute),
+		GRPCKeepaliveTimeout: getDuration("MANGAHUB_GRPC_KEEPALIVE_TIMEOUT", 20*time.Second),
+		GRPCKeepaliveMinTime: getDuration("MANGAHUB_GRPC_KEEPALIVE_MIN_TIME", 30*time.Second),
+		GRPCReflection:       getBool("MANGAHUB_GRPC_REFLECTION", false),
+
+		HealthCheckInterval: getDuration("MANGAHUB_HEALTH_CHECK_INTERVAL", 10*time.Second),
+
+		LogLevel:  getString("MANGAHUB_LOG_LEVEL", "info"),
+		LogFormat: getString("MANGAHUB_LOG_FORMAT", "json"),
+
+		RateLimit:       getFloat("MANGAHUB_RATE_LIMIT", 10),
+		RateBurst:       getInt("MANGAHUB_RATE_BURST", 20),
+		AuthRateLimit:   getFloat("MANGAHUB_AUTH_RATE_LIMIT", 0.1),
+		AuthRateBurst:   getInt("MANGAHUB_AUTH_RATE_BURST", 5),
+		ConnectionRate:  getFloat("MANGAHUB_CONNECTION_RATE", 1),
+		ConnectionBurst: getInt("MANGAHUB_CONNECTION_BURST", 10),
+		TrustedProxies:  getList("MANGAHUB_TRUSTED_PROXIES", nil),
+	}
+
+	// Credentials for any origin would let every site act as the signed in
+	// user, so the combination is refused rather than echoed
+	if cfg.CORSAllowCredentials && allowsAnyOrigin(cfg.AllowedOrigins) {
+		slog.Warn("Invalid setting: credentials cannot be allowed for any origin, disabling them",
+			"key", "MANGAHUB_CORS_ALLOW_CREDENTIALS", "origins", strings.Join(cfg.AllowedOrigins, ","))
+		cfg.CORSAllowCredentials = false
+	}
+	return cfg
+}
+
+// allowsAnyOrigin reports whether origins contain the "*" wildcard
+func allowsAnyOrigin(origins []string) bool {
+	for _, origin := range origins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// warnInvalid reports a setting that could not be read
+func warnInvalid(key, value string, fallback any) {
+	slog.Warn("Invalid setting, using the default", "key", key, "value", value, "default", fallback)
+}
+
+// getString reads a string, falling back when it is not set
+func getString(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// getInt reads an integer, logging and falling back on invalid values
+func getInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		warnInvalid(key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getBool reads a boolean such as "true" or "1", logging and falling back
+// on invalid values
+func getBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		warnInvalid(key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getFloat reads a decimal number, logging and falling back on invalid values
+func getFloat(key string, fallback float64) float64 {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		warnInvalid(key, value, fallback)
+		return fallback
+	}
+	return f
+}
+
+// getDuration reads a Go duration such as "720h", logging and falling back
+// on invalid values
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		warnInvalid(key, value, fallback.String())
+		return fallback
+	}
+	return d
+}
+
+// getList reads a comma separated list, ignoring empty entries
+func getList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
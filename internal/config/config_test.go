@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_RejectsCredentialsForAnyOrigin(t *testing.T) {
+	t.Setenv("MANGAHUB_CORS_ALLOW_CREDENTIALS", "true")
+
+	t.Setenv("MANGAHUB_ALLOWED_ORIGINS", "https://app.example.com")
+	assert.True(t, Load().CORSAllowCredentials)
+
+	t.Setenv("MANGAHUB_ALLOWED_ORIGINS", "https://app.example.com,*")
+	cfg := Load()
+	assert.Equal(t, []string{"https://app.example.com", "*"}, cfg.AllowedOrigins)
+	assert.False(t, cfg.CORSAllowCredentials, "any origin with credentials is refused")
+}
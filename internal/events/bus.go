@@ -0,0 +1,111 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+
+	"mangahub/internal/logging"
+)
+
+// queueSize is how many events a subscriber may fall behind before further
+// events for it are dropped
+const queueSize = 256
+
+// Bus delivers events to subscribers asynchronously. Each subscriber has its
+// own queue and goroutine, so publishing never blocks and a slow subscriber
+// only delays itself. A nil *Bus discards events, so handlers work without
+// one.
+type Bus struct {
+	// Logger reports failing and slow subscribers; nil uses slog's default
+	Logger *slog.Logger
+
+	subscribers map[int]*subscriber
+	nextID      int
+	mutex       sync.RWMutex
+}
+
+type subscriber struct {
+	name  string
+	queue chan Event
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (s *subscriber) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]*subscriber)}
+}
+
+// Subscribe calls handler for every event published from now on, in order,
+// from a dedicated goroutine. name identifies the subscriber in logs. The
+// returned function unsubscribes.
+func (b *Bus) Subscribe(name string, handler func(Event)) func() {
+	s := &subscriber{name: name, queue: make(chan Event, queueSize), done: make(chan struct{})}
+
+	b.mutex.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = s
+	b.mutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case event := <-s.queue:
+				b.deliver(s.name, handler, event)
+			case <-s.done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		b.mutex.Lock()
+		delete(b.subscribers, id)
+		b.mutex.Unlock()
+		s.stop()
+	}
+}
+
+// deliver calls handler, logging instead of crashing if it panics
+func (b *Bus) deliver(name string, handler func(Event), event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Or(b.Logger).ErrorContext(Context(event), "Event subscriber panicked",
+				"subscriber", name, "event", event.EventType(), "panic", r)
+		}
+	}()
+	handler(event)
+}
+
+// Publish queues an event for every subscriber
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for _, s := range b.subscribers {
+		select {
+		case s.queue <- event:
+		default:
+			logging.Or(b.Logger).WarnContext(Context(event), "Event subscriber is falling behind, dropping event",
+				"subscriber", s.name, "event", event.EventType())
+		}
+	}
+}
+
+// Close unsubscribes everyone
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for id, s := range b.subscribers {
+		s.stop()
+		delete(b.subscribers, id)
+	}
+}
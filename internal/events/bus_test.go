@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"mangahub/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder collects the events a subscriber receives
+type recorder struct {
+	events []Event
+	mutex  sync.Mutex
+}
+
+func (r *recorder) handle(e Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) received() []Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+func TestBus_DeliversToEverySubscriberInOrder(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	var a, b recorder
+	bus.Subscribe("a", a.handle)
+	unsubscribe := bus.Subscribe("b", b.handle)
+
+	bus.Publish(ProgressUpdated{UserID: "u1", MangaID: "naruto", Chapter: 1})
+	bus.Publish(LibraryUpdated{UserID: "u1", MangaID: "naruto", Action: LibraryAdded})
+
+	assert.Eventually(t, func() bool { return len(a.received()) == 2 && len(b.received()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "progress.updated", a.received()[0].EventType())
+	assert.Equal(t, "library.updated", a.received()[1].EventType())
+
+	unsubscribe()
+	bus.Publish(MangaCreated{})
+	assert.Eventually(t, func() bool { return len(a.received()) == 3 }, time.Second, 10*time.Millisecond)
+	assert.Len(t, b.received(), 2)
+}
+
+func TestBus_SurvivesPanickingSubscriber(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	var r recorder
+	bus.Subscribe("broken", func(e Event) {
+		r.handle(e)
+		panic("boom")
+	})
+
+	bus.Publish(MangaCreated{})
+	bus.Publish(MangaCreated{})
+	assert.Eventually(t, func() bool { return len(r.received()) == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestBus_NilDiscardsEvents(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() { bus.Publish(MangaCreated{}) })
+}
+
+func TestContext_CarriesRequestID(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	assert.Equal(t, "req-1", logging.RequestID(Context(ProgressUpdated{Trace: TraceOf(ctx)})))
+	assert.Empty(t, logging.RequestID(Context(ProgressUpdated{})))
+	assert.Empty(t, logging.RequestID(Context(nil)))
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"mangahub/internal/logging"
+	"mangahub/pkg/models"
+)
+
+// Event is a domain event. Subscribers type-switch on the concrete types
+// below.
+type Event interface {
+	// EventType names the event, e.g. "progress.updated"
+	EventType() string
+}
+
+// Trace identifies the request that caused an event. Events embed it so
+// subscribers can log what they do with the request's ID.
+type Trace struct {
+	RequestID string `json:"-"`
+}
+
+// TraceOf returns the Trace of the request ctx serves
+func TraceOf(ctx context.Context) Trace {
+	return Trace{RequestID: logging.RequestID(ctx)}
+}
+
+func (t Trace) trace() Trace { return t }
+
+// Context returns a context carrying the request ID of event, for logging
+// what is done with it
+func Context(event Event) context.Context {
+	ctx := context.Background()
+	if traced, ok := event.(interface{ trace() Trace }); ok && traced.trace().RequestID != "" {
+		ctx = logging.WithRequestID(ctx, traced.trace().RequestID)
+	}
+	return ctx
+}
+
+// Library actions carried by LibraryUpdated
+const (
+	LibraryAdded         = "added"
+	LibraryStatusChanged = "status_changed"
+	LibraryRemoved       = "removed"
+)
+
+// ProgressUpdated is published when a user records reading progress
+type ProgressUpdated struct {
+	Trace
+	UserID    string    `json:"user_id"`
+	MangaID   string    `json:"manga_id"`
+	Chapter   int       `json:"chapter"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (ProgressUpdated) EventType() string { return "progress.updated" }
+
+// LibraryUpdated is published when a user adds, changes or removes a manga
+// in their library
+type LibraryUpdated struct {
+	Trace
+	UserID    string    `json:"user_id"`
+	MangaID   string    `json:"manga_id"`
+	Action    string    `json:"action"`
+	Status    string    `json:"status,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (LibraryUpdated) EventType() string { return "library.updated" }
+
+// MangaCreated is published when a manga is added to the catalog
+type MangaCreated struct {
+	Trace
+	Manga     models.Manga `json:"manga"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+func (MangaCreated) EventType() string { return "manga.created" }
+
+// MangaUpdated is published when a manga in the catalog is edited.
+// PreviousChapters is its chapter count before the edit, so subscribers
+// can tell new chapters were released.
+type MangaUpdated struct {
+	Trace
+	Manga            models.Manga `json:"manga"`
+	PreviousChapters int          `json:"previous_chapters"`
+	Timestamp        time.Time    `json:"timestamp"`
+}
+
+func (MangaUpdated) EventType() string { return "manga.updated" }
+
+// MangaDeleted is published when a manga is removed from the catalog
+type MangaDeleted struct {
+	Trace
+	MangaID   string    `json:"manga_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (MangaDeleted) EventType() string { return "manga.deleted" }
+
+// PresenceChanged is published when a user comes online, goes offline,
+// connects over another transport or starts reading something else
+type PresenceChanged struct {
+	Trace
+	UserID     string   `json:"user_id"`
+	Online     bool     `json:"online"`
+	Transports []string `json:"transports,omitempty"`
+	MangaID    string   `json:"manga_id,omitempty"`
+	Chapter    int      `json:"chapter,omitempty"`
+	// Everyone delivers the change regardless of the user's visibility. It
+	// is only set on the "appears offline" notice sent when a user restricts
+	// their visibility.
+	Everyone  bool      `json:"-"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (PresenceChanged) EventType() string { return "presence.changed" }
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTokenExpired is returned for resume tokens pointing at events the
+	// log no longer holds, or issued before the server restarted
+	ErrTokenExpired = errors.New("resume token expired")
+	// ErrInvalidToken is returned for malformed resume tokens
+	ErrInvalidToken = errors.New("invalid resume token")
+)
+
+// Record is an event with its position in a Log
+type Record struct {
+	Seq   uint64
+	Event Event
+}
+
+// Log keeps the most recent events in order so feeds can resume where a
+// consumer left off. Positions are handed out as opaque resume tokens that
+// only stay valid while the event is still held and the process is running.
+type Log struct {
+	epoch   string
+	size    int
+	records []Record
+	lastSeq uint64
+	changed chan struct{}
+	mutex   sync.Mutex
+}
+
+// NewLog creates a log holding up to size events
+func NewLog(size int) *Log {
+	return &Log{
+		epoch:   uuid.New().String(),
+		size:    size,
+		changed: make(chan struct{}),
+	}
+}
+
+// HandleEvent appends every event. Subscribe it to an events.Bus.
+func (l *Log) HandleEvent(event Event) {
+	l.Append(event)
+}
+
+// Append adds an event and wakes up everyone watching
+func (l *Log) Append(event Event) Record {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.lastSeq++
+	record := Record{Seq: l.lastSeq, Event: event}
+	l.records = append(l.records, record)
+	if len(l.records) > l.size {
+		l.records = l.records[len(l.records)-l.size:]
+	}
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+	return record
+}
+
+// Token returns the resume token for a position
+func (l *Log) Token(seq uint64) string {
+	return l.epoch + "." + strconv.FormatUint(seq, 10)
+}
+
+// Position returns the position a resume token points at. An empty token
+// means the current end of the log, so only new events are read.
+func (l *Log) Position(token string) (uint64, error) {
+	if token == "" {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		return l.lastSeq, nil
+	}
+
+	epoch, value, ok := strings.Cut(token, ".")
+	seq, err := strconv.ParseUint(value, 10, 64)
+	if !ok || err != nil {
+		return 0, ErrInvalidToken
+	}
+	if epoch != l.epoch {
+		return 0, ErrTokenExpired
+	}
+	return seq, nil
+}
+
+// read returns the records after a position and a channel closed on the
+// next append
+func (l *Log) read(after uint64) ([]Record, <-chan struct{}, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if after > l.lastSeq {
+		return nil, nil, ErrTokenExpired
+	}
+	first := l.lastSeq - uint64(len(l.records)) + 1
+	if after+1 < first {
+		return nil, nil, ErrTokenExpired
+	}
+	records := append([]Record(nil), l.records[after+1-first:]...)
+	return records, l.changed, nil
+}
+
+// Watch calls fn for every event after position, in order, waiting for new
+// ones until ctx is done or fn fails. It returns ErrTokenExpired when the
+// log no longer holds the events after position.
+func (l *Log) Watch(ctx context.Context, after uint64, fn func(Record) error) error {
+	for {
+		records, changed, err := l.read(after)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+			after = record.Seq
+		}
+		if len(records) > 0 {
+			continue
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
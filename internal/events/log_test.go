@@ -0,0 +1,81 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect watches the log from a resume token until n records arrived
+func collect(t *testing.T, l *Log, token string, n int) []Record {
+	after, err := l.Position(token)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var records []Record
+	stop := errors.New("stop")
+	err = l.Watch(ctx, after, func(r Record) error {
+		records = append(records, r)
+		if len(records) == n {
+			return stop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, stop)
+	return records
+}
+
+func TestLog_ResumesAfterToken(t *testing.T) {
+	l := NewLog(10)
+	first := l.Append(ProgressUpdated{UserID: "u1", Chapter: 1})
+	l.Append(ProgressUpdated{UserID: "u1", Chapter: 2})
+	l.Append(ProgressUpdated{UserID: "u1", Chapter: 3})
+
+	records := collect(t, l, l.Token(first.Seq), 2)
+	assert.Equal(t, 2, records[0].Event.(ProgressUpdated).Chapter)
+	assert.Equal(t, 3, records[1].Event.(ProgressUpdated).Chapter)
+}
+
+func TestLog_WatchWaitsForNewEvents(t *testing.T) {
+	l := NewLog(10)
+	l.Append(ProgressUpdated{Chapter: 1})
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		l.HandleEvent(ProgressUpdated{Chapter: 2})
+	}()
+
+	// An empty token skips what is already in the log
+	records := collect(t, l, "", 1)
+	assert.Equal(t, 2, records[0].Event.(ProgressUpdated).Chapter)
+}
+
+func TestLog_Tokens(t *testing.T) {
+	l := NewLog(2)
+	first := l.Append(MangaDeleted{MangaID: "a"})
+	l.Append(MangaDeleted{MangaID: "b"})
+	l.Append(MangaDeleted{MangaID: "c"})
+	l.Append(MangaDeleted{MangaID: "d"})
+
+	// "b", the event after the first one, was dropped
+	after, err := l.Position(l.Token(first.Seq))
+	require.NoError(t, err)
+	err = l.Watch(context.Background(), after, func(Record) error { return nil })
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	_, err = l.Position(NewLog(2).Token(first.Seq))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+	_, err = l.Position("garbage")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	after, err = l.Position("")
+	require.NoError(t, err)
+	assert.ErrorIs(t, l.Watch(ctx, after, func(Record) error { return nil }), context.Canceled)
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+
+	"mangahub/api"
+	"mangahub/internal/service"
+)
+
+// AuthServiceServer implements the gRPC AuthService
+type AuthServiceServer struct {
+	api.UnimplementedAuthServiceServer
+	Users *service.UserService
+}
+
+// Register creates an account and returns a token for it
+func (s *AuthServiceServer) Register(ctx context.Context, req *api.RegisterRequest) (*api.AuthResponse, error) {
+	u, err := s.Users.Register(ctx, service.Registration{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	session, err := s.Users.StartSession(ctx, u)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toAuthResponse(session), nil
+}
+
+// Login checks credentials and returns a token
+func (s *AuthServiceServer) Login(ctx context.Context, req *api.LoginRequest) (*api.AuthResponse, error) {
+	session, err := s.Users.Login(ctx, service.Credentials{Username: req.Username, Password: req.Password})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toAuthResponse(session), nil
+}
+
+// Refresh exchanges a valid token for a new one carrying the user's current
+// role
+func (s *AuthServiceServer) Refresh(ctx context.Context, req *api.RefreshRequest) (*api.AuthResponse, error) {
+	session, err := s.Users.Refresh(ctx, req.Token)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toAuthResponse(session), nil
+}
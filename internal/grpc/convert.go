@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"time"
+
+	"mangahub/api"
+	"mangahub/internal/service"
+	"mangahub/pkg/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// serviceCodes maps service error codes to gRPC codes
+var serviceCodes = map[service.Code]codes.Code{
+	service.Internal:         codes.Internal,
+	service.Invalid:          codes.InvalidArgument,
+	service.NotFound:         codes.NotFound,
+	service.Conflict:         codes.AlreadyExists,
+	service.Unauthenticated:  codes.Unauthenticated,
+	service.PermissionDenied: codes.PermissionDenied,
+}
+
+// errorDomain names the service in ErrorInfo details
+const errorDomain = "mangahub"
+
+// toStatus turns an error from the service layer into a gRPC status error.
+// The status carries an ErrorInfo with the service code, and a BadRequest
+// listing the invalid fields of validation errors.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := service.CodeOf(err)
+	st := status.New(serviceCodes[code], err.Error())
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: code.String(), Domain: errorDomain}}
+	if fields := service.FieldsOf(err); len(fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations,
+				&errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+		}
+		details = append(details, badRequest)
+	}
+	if withDetails, detailsErr := st.WithDetails(details...); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func toManga(m models.Manga) *api.Manga {
+	return &api.Manga{
+		Id:            m.ID,
+		Title:         m.Title,
+		Author:        m.Author,
+		Genres:        m.Genres,
+		Status:        m.Status,
+		TotalChapters: int32(m.TotalChapters),
+		Description:   m.Description,
+		CoverUrl:      m.CoverURL,
+	}
+}
+
+func toMangaList(page service.MangaPage) *api.ListMangaResponse {
+	mangas := make([]*api.Manga, 0, len(page.Manga))
+	for _, m := range page.Manga {
+		mangas = append(mangas, toManga(m))
+	}
+	return &api.ListMangaResponse{Mangas: mangas, Total: int32(page.Total)}
+}
+
+func toUserProgress(p models.UserProgress) *api.UserProgress {
+	return &api.UserProgress{
+		Id:        p.ID,
+		UserId:    p.UserID,
+		MangaId:   p.MangaID,
+		Chapter:   int32(p.Chapter),
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func toLibraryEntry(entry models.UserLibrary) *api.LibraryEntry {
+	return &api.LibraryEntry{
+		Id:      entry.ID,
+		UserId:  entry.UserID,
+		MangaId: entry.MangaID,
+		Status:  entry.Status,
+		AddedAt: entry.AddedAt,
+	}
+}
+
+func toUserProfile(u models.User) *api.UserProfile {
+	return &api.UserProfile{
+		Id:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		Role:      u.Role,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func toAuthResponse(session service.Session) *api.AuthResponse {
+	return &api.AuthResponse{
+		Token:     session.Token,
+		UserId:    session.Claims.UserID,
+		Username:  session.Claims.Username,
+		Role:      session.Claims.Role,
+		ExpiresAt: session.Claims.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"mangahub/api"
+	"mangahub/internal/logging"
+	"mangahub/internal/ratelimit"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGateway serves the REST mapping declared by the google.api.http options
+// in mangahub.proto, along with its OpenAPI document at
+// /api/v2/openapi.json. Requests are forwarded to the gRPC services over
+// conn, so both surfaces share one implementation and its interceptors.
+func NewGateway(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.HTTPBodyMarshaler{
+			Marshaler: &runtime.JSONPb{
+				MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+				UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+			},
+		}),
+		// gRPC rate limits apply to the HTTP client rather than the gateway,
+		// and the call logs with the ID of the HTTP request
+		runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
+			md := metadata.MD{}
+			if ip, ok := ratelimit.ClientIP(r.Context()); ok {
+				md.Set(clientIPMetadata, ip)
+			}
+			if id := logging.RequestID(r.Context()); id != "" {
+				md.Set(requestIDMetadata, id)
+			}
+			return md
+		}),
+		// Rate limit headers keep their HTTP names; the request ID header is
+		// already set by the HTTP server
+		runtime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
+			if key == requestIDMetadata {
+				return "", false
+			}
+			if strings.HasPrefix(key, "ratelimit-") || key == "retry-after" {
+				return key, true
+			}
+			return runtime.MetadataHeaderPrefix + key, true
+		}),
+	)
+
+	registrations := []func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error{
+		api.RegisterMangaServiceHandler,
+		api.RegisterAuthServiceHandler,
+		api.RegisterLibraryServiceHandler,
+		api.RegisterUserServiceHandler,
+	}
+	for _, register := range registrations {
+		if err := register(ctx, mux, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	err := mux.HandlePath(http.MethodGet, "/api/v2/openapi.json", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(api.OpenAPI)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/logging"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	gateway, err := NewGateway(context.Background(), ts.conn)
+	require.NoError(t, err)
+
+	call := func(method, path, token, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		gateway.ServeHTTP(w, req)
+		var decoded map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &decoded)
+		return w, decoded
+	}
+
+	w, registered := call("POST", "/api/v2/auth/register", "", `{"username":"alice","password":"secret1"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	token := registered["token"].(string)
+	assert.Equal(t, "alice", registered["username"])
+
+	// The gateway goes through the auth interceptor
+	w, _ = call("POST", "/api/v2/progress", "", `{"manga_id":"naruto","chapter":3}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w, _ = call("POST", "/api/v2/progress", token, `{"manga_id":"naruto","chapter":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, updated := call("POST", "/api/v2/progress", token, `{"manga_id":"naruto","chapter":3}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, true, updated["success"])
+
+	w, progress := call("GET", "/api/v2/progress/naruto", token, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(3), progress["progress"].(map[string]interface{})["chapter"])
+
+	w, _ = call("GET", "/api/v2/manga/missing", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, spec := call("GET", "/api/v2/openapi.json", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2.0", spec["swagger"])
+	assert.Contains(t, spec["paths"], "/api/v2/progress/{manga_id}")
+}
+
+// logBuffer collects log lines written from other goroutines
+type logBuffer struct {
+	buf   bytes.Buffer
+	mutex sync.Mutex
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// TestGatewayRequestID follows the ID of an HTTP request through the
+// gateway to the gRPC call and the sync broadcast of the event it publishes
+func TestGatewayRequestID(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	var logs logBuffer
+	ts.router.Logger = logging.New(&logs, "json", nil)
+	gateway, err := NewGateway(context.Background(), ts.conn)
+	require.NoError(t, err)
+
+	token, err := auth.GenerateToken(models.User{ID: "alice", Username: "alice", Role: models.RoleUser})
+	require.NoError(t, err)
+
+	ctx := logging.WithRequestID(context.Background(), "http-7")
+	req := httptest.NewRequest("POST", "/api/v2/progress", strings.NewReader(`{"manga_id":"naruto","chapter":3}`)).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	gateway.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, w.Header().Values("Grpc-Metadata-X-Request-Id"), "the HTTP server sets the header")
+
+	assert.Eventually(t, func() bool {
+		for _, line := range strings.Split(logs.String(), "\n") {
+			if strings.Contains(line, `"type":"progress_broadcast"`) && strings.Contains(line, `"request_id":"http-7"`) {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond, logs.String())
+}
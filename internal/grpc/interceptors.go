@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/logging"
+	"mangahub/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without a token
+var publicMethods = map[string]bool{
+	"/mangahub.AuthService/Register":      true,
+	"/mangahub.AuthService/Login":         true,
+	"/mangahub.AuthService/Refresh":       true,
+	"/mangahub.MangaService/GetManga":     true,
+	"/mangahub.MangaService/ListManga":    true,
+	"/mangahub.MangaService/SearchManga":  true,
+	"/mangahub.MangaService/WatchCatalog": true,
+}
+
+// publicServices can be called without a token: health checks for load
+// balancers and reflection for tools like grpcurl
+var publicServices = map[string]bool{
+	"grpc.health.v1.Health":                    true,
+	"grpc.reflection.v1.ServerReflection":      true,
+	"grpc.reflection.v1alpha.ServerReflection": true,
+}
+
+// isPublic reports whether a method can be called without a token
+func isPublic(method string) bool {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return publicMethods[method] || publicServices[service]
+}
+
+// authenticate validates the bearer token in the "authorization" metadata
+// and stores its claims in the context. Public methods may be called
+// without one, but a token that is sent must be valid.
+func authenticate(ctx context.Context, method string) (context.Context, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+	}
+	if header == "" {
+		if isPublic(method) {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization format")
+	}
+	claims, err := auth.ParseTokenClaims(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	return auth.WithUser(ctx, claims), nil
+}
+
+// UnaryAuthInterceptor authenticates unary calls
+func UnaryAuthInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates streaming calls
+func StreamAuthInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// contextStream is a ServerStream with the context given by an
+// interceptor, such as one carrying the user
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestIDMetadata carries request IDs in both directions
+const requestIDMetadata = "x-request-id"
+
+// withRequestID gives a call the request ID sent by the client, or a new
+// one, and returns it in the response headers. Calls relayed by the gateway
+// carry the ID of the HTTP request.
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadata); len(values) > 0 {
+		// The gateway adds its value after any sent by the client
+		id = values[len(values)-1]
+	}
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	return logging.WithRequestID(ctx, id)
+}
+
+// UnaryRequestIDInterceptor assigns every unary call a request ID for its
+// logs. It must run first so every other interceptor logs with it.
+func UnaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = withRequestID(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, logging.RequestID(ctx)))
+		return handler(ctx, req)
+	}
+}
+
+// StreamRequestIDInterceptor assigns every stream a request ID, as
+// UnaryRequestIDInterceptor does for unary calls
+func StreamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withRequestID(stream.Context())
+		stream.SetHeader(metadata.Pairs(requestIDMetadata, logging.RequestID(ctx)))
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authorizeUser returns the user a call acts on, as service.ActingUser
+// does, for the streams that do not go through a service
+func authorizeUser(ctx context.Context, requested string) (string, error) {
+	userID, err := service.ActingUser(ctx, requested)
+	return userID, toStatus(err)
+}
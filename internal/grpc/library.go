@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+
+	"mangahub/api"
+	"mangahub/internal/service"
+)
+
+// LibraryServiceServer implements the gRPC LibraryService. Every call acts
+// on the caller's library unless an admin or service account names another
+// user_id.
+type LibraryServiceServer struct {
+	api.UnimplementedLibraryServiceServer
+	Library *service.LibraryService
+}
+
+// Add puts a manga in the library, or changes its status if already there
+func (s *LibraryServiceServer) Add(ctx context.Context, req *api.AddLibraryEntryRequest) (*api.LibraryEntryResponse, error) {
+	entry, err := s.Library.Add(ctx, req.UserId, req.MangaId, req.Status)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.LibraryEntryResponse{Entry: toLibraryEntry(entry)}, nil
+}
+
+// List returns every manga in the library
+func (s *LibraryServiceServer) List(ctx context.Context, req *api.ListLibraryRequest) (*api.ListLibraryResponse, error) {
+	entries, err := s.Library.List(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	grpcEntries := make([]*api.LibraryEntry, 0, len(entries))
+	for _, entry := range entries {
+		grpcEntries = append(grpcEntries, toLibraryEntry(entry))
+	}
+
+	return &api.ListLibraryResponse{
+		Entries: grpcEntries,
+		Total:   int32(len(grpcEntries)),
+	}, nil
+}
+
+// UpdateStatus changes the reading status of a manga in the library
+func (s *LibraryServiceServer) UpdateStatus(ctx context.Context, req *api.UpdateLibraryStatusRequest) (*api.LibraryEntryResponse, error) {
+	entry, err := s.Library.UpdateStatus(ctx, req.UserId, req.MangaId, req.Status)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.LibraryEntryResponse{Entry: toLibraryEntry(entry)}, nil
+}
+
+// Remove takes a manga out of the library
+func (s *LibraryServiceServer) Remove(ctx context.Context, req *api.RemoveLibraryEntryRequest) (*api.RemoveLibraryEntryResponse, error) {
+	if err := s.Library.Remove(ctx, req.UserId, req.MangaId); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.RemoveLibraryEntryResponse{
+		Success: true,
+		Message: "Removed from library successfully",
+	}, nil
+}
@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mangahub/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics counts completed calls and their latency per method and status
+// code. It serves them in the Prometheus text format.
+type Metrics struct {
+	calls map[callKey]*callStats
+	mutex sync.Mutex
+}
+
+type callKey struct {
+	method string
+	code   codes.Code
+}
+
+type callStats struct {
+	count   uint64
+	seconds float64
+}
+
+// NewMetrics creates empty metrics
+func NewMetrics() *Metrics {
+	return &Metrics{calls: make(map[callKey]*callStats)}
+}
+
+// Observe records a completed call
+func (m *Metrics) Observe(method string, code codes.Code, elapsed time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key := callKey{method: method, code: code}
+	stats, ok := m.calls[key]
+	if !ok {
+		stats = &callStats{}
+		m.calls[key] = stats
+	}
+	stats.count++
+	stats.seconds += elapsed.Seconds()
+}
+
+// Count returns how many calls to method completed with code
+func (m *Metrics) Count(method string, code codes.Code) uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if stats, ok := m.calls[callKey{method: method, code: code}]; ok {
+		return stats.count
+	}
+	return 0
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	keys := make([]callKey, 0, len(m.calls))
+	stats := make(map[callKey]callStats, len(m.calls))
+	for key, s := range m.calls {
+		keys = append(keys, key)
+		stats[key] = *s
+	}
+	m.mutex.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP grpc_server_handled_total Total number of RPCs completed on the server.")
+	fmt.Fprintln(w, "# TYPE grpc_server_handled_total counter")
+	for _, key := range keys {
+		fmt.Fprintf(w, "grpc_server_handled_total{%s} %d\n", labels(key), stats[key].count)
+	}
+	fmt.Fprintln(w, "# HELP grpc_server_handling_seconds Time taken by RPCs completed on the server.")
+	fmt.Fprintln(w, "# TYPE grpc_server_handling_seconds summary")
+	for _, key := range keys {
+		fmt.Fprintf(w, "grpc_server_handling_seconds_sum{%s} %g\n", labels(key), stats[key].seconds)
+		fmt.Fprintf(w, "grpc_server_handling_seconds_count{%s} %d\n", labels(key), stats[key].count)
+	}
+}
+
+// labels formats the Prometheus labels of a call
+func labels(key callKey) string {
+	service, method, _ := strings.Cut(strings.TrimPrefix(key.method, "/"), "/")
+	return fmt.Sprintf("grpc_service=%q,grpc_method=%q,grpc_code=%q", service, method, key.code.String())
+}
+
+// UnaryLoggingInterceptor logs every unary call with its status code and
+// latency
+func UnaryLoggingInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor logs every streaming call with its status code
+// and duration once it ends
+func StreamLoggingInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		logCall(stream.Context(), logger, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// serverErrors are the codes logged as errors; the others are the caller's
+// doing
+var serverErrors = map[codes.Code]bool{
+	codes.Unknown:       true,
+	codes.Internal:      true,
+	codes.Unimplemented: true,
+	codes.Unavailable:   true,
+	codes.DataLoss:      true,
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, method string, err error, elapsed time.Duration) {
+	st := status.Convert(err)
+	level := slog.LevelInfo
+	if serverErrors[st.Code()] {
+		level = slog.LevelError
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", st.Code().String()),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", st.Message()))
+	}
+	logging.Or(logger).LogAttrs(ctx, level, "gRPC call", attrs...)
+}
+
+// UnaryMetricsInterceptor records every unary call in m
+func UnaryMetricsInterceptor(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.Observe(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamMetricsInterceptor records every streaming call in m once it ends
+func StreamMetricsInterceptor(m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		m.Observe(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
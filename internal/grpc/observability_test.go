@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"mangahub/api"
+	"mangahub/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsInterceptors(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	client := api.NewMangaServiceClient(ts.conn)
+
+	_, err := client.GetManga(context.Background(), &api.GetMangaRequest{MangaId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetManga(context.Background(), &api.GetMangaRequest{MangaId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	// Calls rejected by the auth interceptor are recorded too
+	_, err = client.GetUserProgress(context.Background(), &api.GetUserProgressRequest{MangaId: "naruto"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	assert.Equal(t, uint64(2), ts.metrics.Count("/mangahub.MangaService/GetManga", codes.NotFound))
+	assert.Equal(t, uint64(1), ts.metrics.Count("/mangahub.MangaService/GetUserProgress", codes.Unauthenticated))
+
+	w := httptest.NewRecorder()
+	ts.metrics.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `grpc_server_handled_total{grpc_service="mangahub.MangaService",grpc_method="GetManga",grpc_code="NotFound"} 2`)
+	assert.Contains(t, w.Body.String(), `grpc_server_handling_seconds_count{grpc_service="mangahub.MangaService",grpc_method="GetManga",grpc_code="NotFound"} 2`)
+}
+
+func TestHealthIsPublic(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	ts.health.SetServingStatus("database", healthpb.HealthCheckResponse_NOT_SERVING)
+	client := healthpb.NewHealthClient(ts.conn)
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "database"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, "json", nil)
+	interceptor := UnaryRequestIDInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/mangahub.MangaService/GetManga"}
+	call := func(ids ...string) string {
+		ctx := context.Background()
+		if len(ids) > 0 {
+			md := metadata.MD{}
+			md.Append(requestIDMetadata, ids...)
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+		var seen string
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			seen = logging.RequestID(ctx)
+			logCall(ctx, logger, info.FullMethod, status.Error(codes.NotFound, "manga not found"), 0)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return seen
+	}
+
+	assert.Equal(t, "client-1", call("client-1"))
+	assert.Contains(t, buf.String(), `"request_id":"client-1"`)
+	assert.Contains(t, buf.String(), `"code":"NotFound"`)
+	// The gateway appends the HTTP request's ID after the client's
+	assert.Equal(t, "http-1", call("client-1", "http-1"))
+	assert.True(t, logging.ValidRequestID(call()))
+	assert.NotEqual(t, "bad id", call("bad id"))
+
+	// The ID is returned to the client
+	ts := serveTest(t, setupTestDB(t))
+	client := api.NewMangaServiceClient(ts.conn)
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDMetadata, "client-2")
+	_, err := client.GetManga(ctx, &api.GetMangaRequest{MangaId: "missing"}, grpc.Header(&header))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, []string{"client-2"}, header.Get(requestIDMetadata))
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// clientIPMetadata carries the IP of the HTTP client whose request the
+// gateway relays. It is only trusted on calls from the gateway.
+const clientIPMetadata = "x-client-ip"
+
+// UnaryRateLimitInterceptor limits each user, or each client IP for calls
+// without a token, to the policy of the method called. It must run after
+// the auth interceptor so the caller is known.
+func UnaryRateLimitInterceptor(store ratelimit.Store, policies ratelimit.Policies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		header, err := takeToken(ctx, store, policies, info.FullMethod)
+		if header != nil {
+			grpc.SetHeader(ctx, header)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor limits the streams opened by each user or
+// client IP, as UnaryRateLimitInterceptor does for unary calls
+func StreamRateLimitInterceptor(store ratelimit.Store, policies ratelimit.Policies) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		header, err := takeToken(stream.Context(), store, policies, info.FullMethod)
+		if header != nil {
+			stream.SetHeader(header)
+		}
+		if err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// takeToken takes a token for the caller of method. It returns the
+// ratelimit-* headers describing the caller's bucket, and a
+// ResourceExhausted error carrying a RetryInfo when it is empty.
+func takeToken(ctx context.Context, store ratelimit.Store, policies ratelimit.Policies, method string) (metadata.MD, error) {
+	limiter := policies.Limiter(store, "grpc", method)
+	if limiter.Limit.Unlimited() {
+		return nil, nil
+	}
+
+	result := limiter.Allow(ctx, callerKey(ctx))
+	if result.Limit.Unlimited() {
+		return nil, nil
+	}
+	window := int(math.Ceil(result.Limit.Window().Seconds()))
+	header := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(result.Limit.Burst),
+		"ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"ratelimit-reset", strconv.Itoa(result.ResetSeconds()),
+		"ratelimit-policy", strconv.Itoa(result.Limit.Burst)+";w="+strconv.Itoa(window),
+	)
+	if result.Allowed {
+		return header, nil
+	}
+
+	header.Set("retry-after", strconv.Itoa(result.RetryAfterSeconds()))
+	st := status.New(codes.ResourceExhausted, "too many requests, retry later")
+	if withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: "rate_limited", Domain: errorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)},
+	); err == nil {
+		st = withDetails
+	}
+	return header, st.Err()
+}
+
+// callerKey identifies the caller by user ID when authenticated, otherwise
+// by IP. Calls relayed by the gateway come from loopback and carry the IP of
+// the HTTP client.
+func callerKey(ctx context.Context) string {
+	if claims, ok := auth.UserFromContext(ctx); ok && claims.UserID != "" {
+		return "user:" + claims.UserID
+	}
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.IsLoopback() {
+		// The gateway adds its value after any sent by the client
+		if values := metadata.ValueFromIncomingContext(ctx, clientIPMetadata); len(values) > 0 {
+			ip = values[len(values)-1]
+		}
+	}
+	return "ip:" + ip
+}
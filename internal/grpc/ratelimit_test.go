@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"mangahub/api"
+	"mangahub/internal/auth"
+	"mangahub/internal/ratelimit"
+	"mangahub/internal/service"
+	"mangahub/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestRateLimitInterceptors(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	store := ratelimit.NewMemoryStore()
+	policies := ratelimit.Policies{
+		Default: ratelimit.Limit{Rate: 1, Burst: 5},
+		Routes:  map[string]ratelimit.Limit{"/mangahub.AuthService/Login": {Rate: 0.1, Burst: 2}},
+	}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryAuthInterceptor(), UnaryRateLimitInterceptor(store, policies)),
+		grpc.ChainStreamInterceptor(StreamAuthInterceptor(), StreamRateLimitInterceptor(store, policies)),
+	)
+	db := setupTestDB(t)
+	api.RegisterAuthServiceServer(server, &AuthServiceServer{Users: &service.UserService{Store: &user.UserRepository{DB: db}}})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := api.NewAuthServiceClient(conn)
+
+	login := func() (metadata.MD, error) {
+		var header metadata.MD
+		_, err := client.Login(context.Background(), &api.LoginRequest{Username: "nobody", Password: "guess"}, grpc.Header(&header))
+		return header, err
+	}
+
+	header, err := login()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "failed logins count too")
+	assert.Equal(t, []string{"2"}, header.Get("ratelimit-limit"))
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-remaining"))
+	assert.Equal(t, []string{"2;w=20"}, header.Get("ratelimit-policy"))
+	_, err = login()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	header, err = login()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"10"}, header.Get("retry-after"))
+	var info *errdetails.ErrorInfo
+	var retry *errdetails.RetryInfo
+	for _, detail := range status.Convert(err).Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	require.NotNil(t, info)
+	assert.Equal(t, "rate_limited", info.Reason)
+	require.NotNil(t, retry)
+	assert.InDelta(t, 10*time.Second, retry.RetryDelay.AsDuration(), float64(time.Second))
+
+	// Methods without a policy of their own use the default limit
+	_, err = client.Register(context.Background(), &api.RegisterRequest{Username: "alice", Password: "secret1"})
+	assert.NoError(t, err)
+}
+
+func TestCallerKey(t *testing.T) {
+	from := func(ip string, md metadata.MD) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000}})
+		return metadata.NewIncomingContext(ctx, md)
+	}
+	relayed := metadata.Pairs(clientIPMetadata, "192.0.2.1", clientIPMetadata, "192.0.2.7")
+
+	assert.Equal(t, "ip:198.51.100.4", callerKey(from("198.51.100.4", nil)))
+	assert.Equal(t, "ip:198.51.100.4", callerKey(from("198.51.100.4", relayed)), "only the gateway may name the client")
+	assert.Equal(t, "ip:192.0.2.7", callerKey(from("127.0.0.1", relayed)), "the gateway's value comes last")
+
+	ctx := auth.WithUser(from("198.51.100.4", nil), auth.Claims{UserID: "u1"})
+	assert.Equal(t, "user:u1", callerKey(ctx))
+}
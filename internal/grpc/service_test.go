@@ -0,0 +1,268 @@
+package grpc
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"testing"
+
+	"mangahub/api"
+	"mangahub/internal/auth"
+	"mangahub/internal/events"
+	"mangahub/internal/library"
+	"mangahub/internal/manga"
+	"mangahub/internal/progress"
+	"mangahub/internal/service"
+	"mangahub/internal/tcp"
+	"mangahub/internal/user"
+	"mangahub/pkg/models"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+	CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		username TEXT UNIQUE NOT NULL,
+		email TEXT UNIQUE,
+		role TEXT NOT NULL DEFAULT 'user',
+		password_hash TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE user_library (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		manga_id TEXT NOT NULL,
+		status TEXT DEFAULT 'plan_to_read',
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, manga_id)
+	);
+	CREATE TABLE manga (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		author TEXT,
+		genres TEXT,
+		status TEXT,
+		total_chapters INTEGER DEFAULT 0,
+		description TEXT,
+		cover_url TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE user_progress (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		manga_id TEXT NOT NULL,
+		chapter INTEGER DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, manga_id)
+	);`)
+	require.NoError(t, err)
+	return db
+}
+
+// testServer is a gRPC server with every service, reachable in memory
+type testServer struct {
+	conn    *grpc.ClientConn
+	server  *grpc.Server
+	manga   *MangaServiceServer
+	events  *events.Bus
+	router  *tcp.Router
+	metrics *Metrics
+	health  *health.Server
+}
+
+// startTestServer serves the gRPC services over an in-memory connection
+func startTestServer(t *testing.T, db *sql.DB) *grpc.ClientConn {
+	return serveTest(t, db).conn
+}
+
+func serveTest(t *testing.T, db *sql.DB) *testServer {
+	listener := bufconn.Listen(1 << 20)
+	metrics := NewMetrics()
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryRequestIDInterceptor(), UnaryMetricsInterceptor(metrics), UnaryAuthInterceptor()),
+		grpc.ChainStreamInterceptor(StreamRequestIDInterceptor(), StreamMetricsInterceptor(metrics), StreamAuthInterceptor()),
+	)
+	bus := events.NewBus()
+	t.Cleanup(bus.Close)
+	feed := events.NewLog(100)
+	bus.Subscribe("feed", feed.HandleEvent)
+
+	router := tcp.NewRouter()
+	bus.Subscribe("router", router.HandleEvent)
+
+	users := &service.UserService{Store: &user.UserRepository{DB: db}}
+	mangaServer := &MangaServiceServer{
+		Manga:    &service.MangaService{Store: &manga.MangaRepository{DB: db}, Events: bus},
+		Progress: &service.ProgressService{Store: &progress.ProgressRepository{DB: db}, Events: bus},
+		Feed:     feed,
+		Shutdown: make(chan struct{}),
+	}
+	api.RegisterMangaServiceServer(server, mangaServer)
+	api.RegisterAuthServiceServer(server, &AuthServiceServer{Users: users})
+	api.RegisterLibraryServiceServer(server, &LibraryServiceServer{Library: &service.LibraryService{Store: &library.LibraryRepository{DB: db}, Events: bus}})
+	api.RegisterUserServiceServer(server, &UserServiceServer{Users: users})
+	api.RegisterSyncServiceServer(server, &SyncServiceServer{Router: router, Shutdown: mangaServer.Shutdown})
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &testServer{conn: conn, server: server, manga: mangaServer, events: bus, router: router, metrics: metrics, health: healthServer}
+}
+
+// asUser returns a context authenticating calls as the given user
+func asUser(t *testing.T, userID, role string) context.Context {
+	token, err := auth.GenerateToken(models.User{ID: userID, Username: userID, Role: role})
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthService(t *testing.T) {
+	conn := startTestServer(t, setupTestDB(t))
+	client := api.NewAuthServiceClient(conn)
+	ctx := context.Background()
+
+	registered, err := client.Register(ctx, &api.RegisterRequest{Username: "alice", Password: "secret1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, registered.Token)
+	assert.Equal(t, "user", registered.Role)
+
+	// Several users may register without an email
+	_, err = client.Register(ctx, &api.RegisterRequest{Username: "bob", Password: "secret1"})
+	require.NoError(t, err)
+
+	_, err = client.Register(ctx, &api.RegisterRequest{Username: "alice", Password: "secret1"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = client.Register(ctx, &api.RegisterRequest{Username: "carol", Password: "short"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Login(ctx, &api.LoginRequest{Username: "alice", Password: "wrong!!"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	loggedIn, err := client.Login(ctx, &api.LoginRequest{Username: "alice", Password: "secret1"})
+	require.NoError(t, err)
+	assert.Equal(t, registered.UserId, loggedIn.UserId)
+
+	refreshed, err := client.Refresh(ctx, &api.RefreshRequest{Token: loggedIn.Token})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", refreshed.Username)
+	assert.NotEmpty(t, refreshed.ExpiresAt)
+	_, err = client.Refresh(ctx, &api.RefreshRequest{Token: "garbage"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	users := api.NewUserServiceClient(conn)
+	aliceCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+refreshed.Token)
+	profile, err := users.GetProfile(aliceCtx, &api.GetProfileRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", profile.User.Username)
+	assert.Empty(t, profile.User.Email)
+	assert.NotEmpty(t, profile.User.CreatedAt)
+	_, err = users.GetProfile(aliceCtx, &api.GetProfileRequest{UserId: "nobody"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = users.GetProfile(asUser(t, "admin", models.RoleAdmin), &api.GetProfileRequest{UserId: "nobody"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestLibraryService(t *testing.T) {
+	client := api.NewLibraryServiceClient(startTestServer(t, setupTestDB(t)))
+	ctx := asUser(t, "u1", models.RoleUser)
+
+	added, err := client.Add(ctx, &api.AddLibraryEntryRequest{UserId: "u1", MangaId: "one-piece"})
+	require.NoError(t, err)
+	assert.Equal(t, "plan_to_read", added.Entry.Status)
+	_, err = client.Add(ctx, &api.AddLibraryEntryRequest{UserId: "u1", MangaId: "naruto", Status: "reading"})
+	require.NoError(t, err)
+	_, err = client.Add(ctx, &api.AddLibraryEntryRequest{UserId: "u1", MangaId: "bleach", Status: "skimming"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, []string{"invalid", "status"}, errorDetails(err), "validation errors name the field")
+
+	updated, err := client.UpdateStatus(ctx, &api.UpdateLibraryStatusRequest{UserId: "u1", MangaId: "one-piece", Status: "completed"})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", updated.Entry.Status)
+	_, err = client.UpdateStatus(asUser(t, "u2", models.RoleUser), &api.UpdateLibraryStatusRequest{MangaId: "one-piece", Status: "completed"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := client.List(ctx, &api.ListLibraryRequest{UserId: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), list.Total)
+
+	_, err = client.Remove(ctx, &api.RemoveLibraryEntryRequest{UserId: "u1", MangaId: "naruto"})
+	require.NoError(t, err)
+	_, err = client.Remove(ctx, &api.RemoveLibraryEntryRequest{UserId: "u1", MangaId: "naruto"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, []string{"not_found"}, errorDetails(err))
+}
+
+// errorDetails returns the ErrorInfo reason and the BadRequest fields of a
+// status error
+func errorDetails(err error) []string {
+	var details []string
+	for _, detail := range status.Convert(err).Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			details = append(details, d.Reason)
+		case *errdetails.BadRequest:
+			for _, violation := range d.FieldViolations {
+				details = append(details, violation.Field)
+			}
+		}
+	}
+	return details
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	client := api.NewMangaServiceClient(startTestServer(t, setupTestDB(t)))
+	alice := asUser(t, "u1", models.RoleUser)
+
+	// Public methods need no token, but one that is sent must be valid
+	_, err := client.ListManga(context.Background(), &api.ListMangaRequest{})
+	require.NoError(t, err)
+	bad := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer garbage")
+	_, err = client.ListManga(bad, &api.ListMangaRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.UpdateProgress(context.Background(), &api.UpdateProgressRequest{MangaId: "naruto", Chapter: 3})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The user comes from the token
+	updated, err := client.UpdateProgress(alice, &api.UpdateProgressRequest{MangaId: "naruto", Chapter: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "u1", updated.Progress.UserId)
+	progress, err := client.GetUserProgress(alice, &api.GetUserProgressRequest{UserId: "u1", MangaId: "naruto"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), progress.Progress.Chapter)
+
+	// Other users' progress is off limits, except to admins and services
+	_, err = client.UpdateProgress(asUser(t, "u2", models.RoleUser), &api.UpdateProgressRequest{UserId: "u1", MangaId: "naruto", Chapter: 99})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.GetUserProgress(asUser(t, "u2", models.RoleModerator), &api.GetUserProgressRequest{UserId: "u1", MangaId: "naruto"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetUserProgress(asUser(t, "admin", models.RoleAdmin), &api.GetUserProgressRequest{UserId: "u1", MangaId: "naruto"})
+	require.NoError(t, err)
+	updated, err = client.UpdateProgress(asUser(t, "sync", models.RoleService), &api.UpdateProgressRequest{UserId: "u1", MangaId: "naruto", Chapter: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "u1", updated.Progress.UserId)
+}
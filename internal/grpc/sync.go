@@ -0,0 +1,186 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"mangahub/api"
+	"mangahub/internal/presence"
+	"mangahub/internal/tcp"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// syncBufferSize is how many messages may wait for a slow Sync client
+// before its stream is closed
+const syncBufferSize = 64
+
+var errSyncBufferFull = errors.New("send buffer full")
+
+// SyncServiceServer implements the gRPC SyncService on the router shared
+// with the TCP server
+type SyncServiceServer struct {
+	api.UnimplementedSyncServiceServer
+	Router *tcp.Router
+	// Shutdown is closed when the server stops, ending every stream so
+	// GracefulStop does not wait on them
+	Shutdown chan struct{}
+}
+
+// Sync routes the messages of one client until it closes the stream, falls
+// too far behind or the server shuts down
+func (s *SyncServiceServer) Sync(stream grpc.BidiStreamingServer[api.SyncRequest, api.SyncResponse]) error {
+	ctx := stream.Context()
+	if _, err := authorizeUser(ctx, ""); err != nil {
+		return err
+	}
+
+	outbox := make(chan *api.SyncResponse, syncBufferSize)
+	closed := make(chan struct{})
+	var closeOnce sync.Once
+	send := func(msg tcp.Message) error {
+		select {
+		case <-closed:
+			return io.ErrClosedPipe
+		default:
+		}
+		select {
+		case outbox <- toSyncResponse(msg):
+			return nil
+		default:
+			return errSyncBufferFull
+		}
+	}
+	session := tcp.NewSession(uuid.New().String(), presence.TransportGRPC, send, func() {
+		closeOnce.Do(func() { close(closed) })
+	})
+	s.Router.Add(session)
+	defer s.Router.Remove(session)
+
+	received := make(chan error, 1)
+	go func() {
+		received <- s.receive(ctx, stream, session)
+	}()
+
+	for {
+		select {
+		case msg := <-outbox:
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case err := <-received:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-closed:
+			return status.Error(codes.ResourceExhausted, "client too slow, sync again")
+		case <-s.Shutdown:
+			return status.Error(codes.Unavailable, "server shutting down")
+		}
+	}
+}
+
+// receive hands the client's requests to the router and queues the replies
+func (s *SyncServiceServer) receive(ctx context.Context, stream grpc.BidiStreamingServer[api.SyncRequest, api.SyncResponse], session *tcp.Session) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		reply := tcp.Message{Type: "error"}
+		msg, err := toSyncMessage(ctx, req)
+		if err != nil {
+			reply.Data = status.Convert(err).Message()
+		} else {
+			reply = s.Router.Handle(session, msg)
+		}
+		if err := session.Send(reply); err != nil {
+			session.Close()
+			return nil
+		}
+	}
+}
+
+// toSyncMessage converts a request to the router's message, checking who it
+// may act for
+func toSyncMessage(ctx context.Context, req *api.SyncRequest) (tcp.Message, error) {
+	switch m := req.Message.(type) {
+	case *api.SyncRequest_Register:
+		userID, err := authorizeUser(ctx, m.Register.UserId)
+		return tcp.Message{Type: "register", UserID: userID}, err
+	case *api.SyncRequest_ProgressUpdate:
+		userID, err := authorizeUser(ctx, m.ProgressUpdate.UserId)
+		if err != nil {
+			return tcp.Message{}, err
+		}
+		if m.ProgressUpdate.MangaId == "" {
+			return tcp.Message{}, status.Error(codes.InvalidArgument, "manga_id is required")
+		}
+		return tcp.Message{
+			Type:    "progress_update",
+			UserID:  userID,
+			MangaID: m.ProgressUpdate.MangaId,
+			Chapter: int(m.ProgressUpdate.Chapter),
+		}, nil
+	case *api.SyncRequest_Ping:
+		return tcp.Message{Type: "ping"}, nil
+	}
+	return tcp.Message{}, status.Error(codes.InvalidArgument, "empty sync request")
+}
+
+// toSyncResponse converts a router message to its typed counterpart
+func toSyncResponse(msg tcp.Message) *api.SyncResponse {
+	switch msg.Type {
+	case "registered":
+		return &api.SyncResponse{Message: &api.SyncResponse_Registered{
+			Registered: &api.SyncRegistered{UserId: msg.UserID, Timestamp: msg.Timestamp},
+		}}
+	case "progress_ack":
+		return &api.SyncResponse{Message: &api.SyncResponse_Ack{
+			Ack: &api.SyncAck{Type: "progress_update", Timestamp: msg.Timestamp},
+		}}
+	case "pong":
+		return &api.SyncResponse{Message: &api.SyncResponse_Pong{
+			Pong: &api.SyncPong{Timestamp: msg.Timestamp},
+		}}
+	case "error":
+		return &api.SyncResponse{Message: &api.SyncResponse_Error{
+			Error: &api.SyncError{Message: fmt.Sprint(msg.Data)},
+		}}
+	}
+	return &api.SyncResponse{Message: &api.SyncResponse_Broadcast{
+		Broadcast: &api.SyncBroadcast{
+			Type:      msg.Type,
+			UserId:    msg.UserID,
+			MangaId:   msg.MangaID,
+			Chapter:   int32(msg.Chapter),
+			Data:      stringMap(msg.Data),
+			Timestamp: msg.Timestamp,
+		},
+	}}
+}
+
+// stringMap flattens the free-form data of a TCP message
+func stringMap(data interface{}) map[string]string {
+	switch d := data.(type) {
+	case nil:
+		return nil
+	case map[string]string:
+		return d
+	case map[string]interface{}:
+		m := make(map[string]string, len(d))
+		for k, v := range d {
+			m[k] = fmt.Sprint(v)
+		}
+		return m
+	}
+	return map[string]string{"value": fmt.Sprint(data)}
+}
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"mangahub/api"
+	"mangahub/internal/events"
+	"mangahub/internal/presence"
+	"mangahub/internal/tcp"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSyncService(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	client := api.NewSyncServiceClient(ts.conn)
+
+	// A TCP client on the same router
+	received := make(chan tcp.Message, 10)
+	tcpSession := tcp.NewSession("tcp-client", presence.TransportTCP, func(msg tcp.Message) error {
+		received <- msg
+		return nil
+	}, func() {})
+	ts.router.Add(tcpSession)
+	ts.router.Handle(tcpSession, tcp.Message{Type: "register", UserID: "alice"})
+
+	stream, err := client.Sync(asUser(t, "alice", models.RoleUser))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&api.SyncRequest{Message: &api.SyncRequest_Register{Register: &api.SyncRegister{}}}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "alice", resp.GetRegistered().UserId)
+
+	require.NoError(t, stream.Send(&api.SyncRequest{Message: &api.SyncRequest_Ping{Ping: &api.SyncPing{}}}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, resp.GetPong())
+
+	// Progress updates are relayed to the user's TCP client
+	require.NoError(t, stream.Send(&api.SyncRequest{Message: &api.SyncRequest_ProgressUpdate{
+		ProgressUpdate: &api.SyncProgressUpdate{MangaId: "naruto", Chapter: 7},
+	}}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "progress_update", resp.GetAck().Type)
+	relayed := <-received
+	assert.Equal(t, "progress_update", relayed.Type)
+	assert.Equal(t, "alice", relayed.UserID)
+	assert.Equal(t, 7, relayed.Chapter)
+
+	// Acting for someone else is refused without closing the stream
+	require.NoError(t, stream.Send(&api.SyncRequest{Message: &api.SyncRequest_Register{Register: &api.SyncRegister{UserId: "bob"}}}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetError().Message)
+
+	// Messages from the user's TCP clients and domain events reach the
+	// stream
+	ts.router.Handle(tcpSession, tcp.Message{Type: "progress_update", UserID: "mallory", MangaID: "bleach", Chapter: 2})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "alice", resp.GetBroadcast().UserId)
+
+	ts.events.Publish(events.LibraryUpdated{UserID: "alice", MangaID: "naruto", Action: events.LibraryAdded, Status: "reading", Timestamp: time.Now()})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	broadcast := resp.GetBroadcast()
+	assert.Equal(t, "library_update", broadcast.Type)
+	assert.Equal(t, map[string]string{"action": "added", "status": "reading"}, broadcast.Data)
+
+	assert.Equal(t, 1, ts.router.Count(presence.TransportGRPC))
+	close(ts.manga.Shutdown)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Eventually(t, func() bool { return ts.router.Count(presence.TransportGRPC) == 0 }, time.Second, 10*time.Millisecond)
+}
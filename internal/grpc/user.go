@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"context"
+
+	"mangahub/api"
+	"mangahub/internal/service"
+)
+
+// UserServiceServer implements the gRPC UserService
+type UserServiceServer struct {
+	api.UnimplementedUserServiceServer
+	Users *service.UserService
+}
+
+// GetProfile retrieves the caller's profile; admins and service accounts may
+// pass another user_id
+func (s *UserServiceServer) GetProfile(ctx context.Context, req *api.GetProfileRequest) (*api.ProfileResponse, error) {
+	u, err := s.Users.Profile(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.ProfileResponse{User: toUserProfile(u)}, nil
+}
@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"mangahub/api"
+	"mangahub/internal/events"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WatchProgress streams the caller's progress updates; admins and service
+// accounts may pass another user_id
+func (s *MangaServiceServer) WatchProgress(req *api.WatchProgressRequest, stream grpc.ServerStreamingServer[api.ProgressEvent]) error {
+	userID, err := authorizeUser(stream.Context(), req.UserId)
+	if err != nil {
+		return err
+	}
+
+	return s.watch(stream, req.ResumeToken, func(record events.Record) error {
+		e, ok := record.Event.(events.ProgressUpdated)
+		if !ok || e.UserID != userID {
+			return nil
+		}
+		return stream.Send(&api.ProgressEvent{
+			ResumeToken: s.Feed.Token(record.Seq),
+			Timestamp:   e.Timestamp.UTC().Format(time.RFC3339),
+			Progress: &api.UserProgress{
+				UserId:    e.UserID,
+				MangaId:   e.MangaID,
+				Chapter:   int32(e.Chapter),
+				UpdatedAt: e.Timestamp.UTC().Format(time.RFC3339),
+			},
+		})
+	})
+}
+
+// WatchCatalog streams manga being created, updated and deleted
+func (s *MangaServiceServer) WatchCatalog(req *api.WatchCatalogRequest, stream grpc.ServerStreamingServer[api.CatalogEvent]) error {
+	return s.watch(stream, req.ResumeToken, func(record events.Record) error {
+		msg := &api.CatalogEvent{ResumeToken: s.Feed.Token(record.Seq)}
+		var timestamp time.Time
+		switch e := record.Event.(type) {
+		case events.MangaCreated:
+			msg.Event = &api.CatalogEvent_Created{Created: toManga(e.Manga)}
+			timestamp = e.Timestamp
+		case events.MangaUpdated:
+			msg.Event = &api.CatalogEvent_Updated{Updated: toManga(e.Manga)}
+			timestamp = e.Timestamp
+		case events.MangaDeleted:
+			msg.Event = &api.CatalogEvent_DeletedMangaId{DeletedMangaId: e.MangaID}
+			timestamp = e.Timestamp
+		default:
+			return nil
+		}
+		msg.Timestamp = timestamp.UTC().Format(time.RFC3339)
+		return stream.Send(msg)
+	})
+}
+
+// watch feeds the events after a resume token to send until the client goes
+// away or the server shuts down. Headers are sent once watching starts, so
+// clients know every later event will be delivered.
+func (s *MangaServiceServer) watch(stream grpc.ServerStream, token string, send func(events.Record) error) error {
+	if s.Feed == nil {
+		return status.Error(codes.Unavailable, "event feed not available")
+	}
+	after, err := s.Feed.Position(token)
+	if errors.Is(err, events.ErrInvalidToken) {
+		return status.Error(codes.InvalidArgument, "invalid resume_token")
+	}
+	if err != nil {
+		return status.Error(codes.OutOfRange, "resume_token expired, watch again without one")
+	}
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.Shutdown:
+			cancel()
+		case <-watchCtx.Done():
+		}
+	}()
+
+	err = s.Feed.Watch(watchCtx, after, send)
+	switch {
+	case errors.Is(err, events.ErrTokenExpired):
+		return status.Error(codes.OutOfRange, "stream fell too far behind, watch again without a resume_token")
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Unavailable, "server shutting down, resume with the last resume_token")
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"mangahub/api"
+	"mangahub/internal/events"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatchProgress(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	client := api.NewMangaServiceClient(ts.conn)
+	alice := asUser(t, "alice", models.RoleUser)
+	bob := asUser(t, "bob", models.RoleUser)
+
+	stream, err := client.WatchProgress(alice, &api.WatchProgressRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	// Only the caller's own progress is streamed
+	_, err = client.UpdateProgress(bob, &api.UpdateProgressRequest{MangaId: "naruto", Chapter: 3})
+	require.NoError(t, err)
+	_, err = client.UpdateProgress(alice, &api.UpdateProgressRequest{MangaId: "naruto", Chapter: 5})
+	require.NoError(t, err)
+	_, err = client.UpdateProgress(alice, &api.UpdateProgressRequest{MangaId: "naruto", Chapter: 6})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "alice", first.Progress.UserId)
+	assert.Equal(t, int32(5), first.Progress.Chapter)
+	assert.NotEmpty(t, first.ResumeToken)
+
+	// Resuming continues after the event that carried the token
+	resumed, err := client.WatchProgress(alice, &api.WatchProgressRequest{ResumeToken: first.ResumeToken})
+	require.NoError(t, err)
+	next, err := resumed.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), next.Progress.Chapter)
+
+	denied, err := client.WatchProgress(alice, &api.WatchProgressRequest{UserId: "bob"})
+	require.NoError(t, err)
+	_, err = denied.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	invalid, err := client.WatchProgress(alice, &api.WatchProgressRequest{ResumeToken: "garbage"})
+	require.NoError(t, err)
+	_, err = invalid.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWatchCatalog(t *testing.T) {
+	ts := serveTest(t, setupTestDB(t))
+	client := api.NewMangaServiceClient(ts.conn)
+
+	// The catalog feed is public
+	stream, err := client.WatchCatalog(t.Context(), &api.WatchCatalogRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	ts.events.Publish(events.MangaCreated{Manga: models.Manga{ID: "naruto", Title: "Naruto"}, Timestamp: time.Now()})
+	ts.events.Publish(events.ProgressUpdated{UserID: "alice", MangaID: "naruto", Chapter: 1})
+	ts.events.Publish(events.MangaUpdated{Manga: models.Manga{ID: "naruto", Title: "Naruto", TotalChapters: 700}, Timestamp: time.Now()})
+	ts.events.Publish(events.MangaDeleted{MangaID: "naruto", Timestamp: time.Now()})
+
+	created, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Naruto", created.GetCreated().Title)
+	updated, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(700), updated.GetUpdated().TotalChapters)
+	deleted, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "naruto", deleted.GetDeletedMangaId())
+
+	// Shutting down ends open streams so GracefulStop does not wait on them
+	close(ts.manga.Shutdown)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	stopped := make(chan struct{})
+	go func() {
+		ts.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("GracefulStop waited on a watch stream")
+	}
+}
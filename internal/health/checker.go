@@ -0,0 +1,136 @@
+// Package health checks whether the server's subsystems are alive and
+// reports the result over HTTP and the standard gRPC health service.
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DefaultTimeout bounds how long a single check may take
+const DefaultTimeout = 2 * time.Second
+
+var errNotRunning = errors.New("not running")
+
+// Check reports whether a subsystem is alive
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check
+type Result struct {
+	Name  string
+	Error error
+}
+
+// Healthy reports whether the check passed
+func (r Result) Healthy() bool {
+	return r.Error == nil
+}
+
+// Checker runs named checks
+type Checker struct {
+	// Timeout bounds each check
+	Timeout time.Duration
+
+	checks map[string]Check
+	mutex  sync.RWMutex
+}
+
+// NewChecker creates a checker without checks
+func NewChecker() *Checker {
+	return &Checker{Timeout: DefaultTimeout, checks: make(map[string]Check)}
+}
+
+// Add registers a check, replacing any check of the same name
+func (c *Checker) Add(name string, check Check) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.checks[name] = check
+}
+
+// Run runs every check concurrently and returns the results ordered by name
+func (c *Checker) Run(ctx context.Context) []Result {
+	c.mutex.RLock()
+	results := make([]Result, 0, len(c.checks))
+	checks := make([]Check, 0, len(c.checks))
+	for name, check := range c.checks {
+		results = append(results, Result{Name: name})
+		checks = append(checks, check)
+	}
+	c.mutex.RUnlock()
+
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+			results[i].Error = checks[i](checkCtx)
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// Healthy reports whether every result passed
+func Healthy(results []Result) bool {
+	for _, result := range results {
+		if !result.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// Update runs the checks and publishes the results on a gRPC health server:
+// each check under its own name, and the server as a whole under "" as
+// serving only while every check passes
+func (c *Checker) Update(ctx context.Context, server *health.Server) []Result {
+	results := c.Run(ctx)
+	for _, result := range results {
+		server.SetServingStatus(result.Name, servingStatus(result.Healthy()))
+	}
+	server.SetServingStatus("", servingStatus(Healthy(results)))
+	return results
+}
+
+// Watch updates server every interval until done is closed
+func (c *Checker) Watch(server *health.Server, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.Update(context.Background(), server)
+	for {
+		select {
+		case <-ticker.C:
+			c.Update(context.Background(), server)
+		case <-done:
+			return
+		}
+	}
+}
+
+func servingStatus(healthy bool) healthpb.HealthCheckResponse_ServingStatus {
+	if healthy {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// Running turns a server's running flag into a check
+func Running(running func() bool) Check {
+	return func(context.Context) error {
+		if !running() {
+			return errNotRunning
+		}
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestChecker_Run(t *testing.T) {
+	checker := NewChecker()
+	checker.Timeout = 50 * time.Millisecond
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.Add("tcp", Running(func() bool { return false }))
+	checker.Add("websocket", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	results := checker.Run(context.Background())
+	require.Len(t, results, 3)
+	assert.Equal(t, "database", results[0].Name)
+	assert.True(t, results[0].Healthy())
+	assert.Equal(t, "tcp", results[1].Name)
+	assert.False(t, results[1].Healthy())
+	// Hanging checks fail once the timeout passes
+	assert.True(t, errors.Is(results[2].Error, context.DeadlineExceeded))
+	assert.False(t, Healthy(results))
+}
+
+func TestChecker_Update(t *testing.T) {
+	server := health.NewServer()
+	healthy := true
+	checker := NewChecker()
+	checker.Add("udp", Running(func() bool { return healthy }))
+
+	status := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	checker.Update(context.Background(), server)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status("udp"))
+
+	healthy = false
+	checker.Update(context.Background(), server)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status("udp"))
+}
@@ -2,62 +2,49 @@ package library
 
 import (
 	"net/http"
-	"mangahub/internal/auth"
-	"mangahub/pkg/models"
+
+	"mangahub/internal/problem"
+	"mangahub/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
+// LibraryHandler serves the caller's library over REST through the
+// LibraryService
 type LibraryHandler struct {
-	Repo *LibraryRepository
+	Service *service.LibraryService
 }
 
-func (h *LibraryHandler) AddToLibrary(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	var req struct {
-		MangaID string `json:"manga_id" binding:"required"`
-		Status  string `json:"status"`
-	}
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
+// AddRequest is the body of AddToLibrary. Status defaults to plan_to_read.
+type AddRequest struct {
+	MangaID string `json:"manga_id" binding:"required"`
+	Status  string `json:"status" enum:"reading,completed,plan_to_read,dropped"`
+}
 
-	if req.Status == "" {
-		req.Status = "plan_to_read"
-	}
+// UpdateStatusRequest is the body of UpdateStatus
+type UpdateStatusRequest struct {
+	Status string `json:"status" binding:"required" enum:"reading,completed,plan_to_read,dropped"`
+}
 
-	library := models.UserLibrary{
-		ID:      uuid.New().String(),
-		UserID:  userID,
-		MangaID: req.MangaID,
-		Status:  req.Status,
+func (h *LibraryHandler) AddToLibrary(c *gin.Context) {
+	var req AddRequest
+	if !problem.BindJSON(c, &req) {
+		return
 	}
 
-	if err := h.Repo.AddToLibrary(library); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to library"})
+	entry, err := h.Service.Add(c.Request.Context(), "", req.MangaID, req.Status)
+	if err != nil {
+		problem.Write(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, library)
+	c.JSON(http.StatusCreated, entry)
 }
 
 func (h *LibraryHandler) GetUserLibrary(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	libraries, err := h.Repo.GetUserLibrary(userID)
+	libraries, err := h.Service.List(c.Request.Context(), "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch library"})
+		problem.Write(c, err)
 		return
 	}
 
@@ -65,23 +52,13 @@ func (h *LibraryHandler) GetUserLibrary(c *gin.Context) {
 }
 
 func (h *LibraryHandler) UpdateStatus(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	mangaID := c.Param("id")
-	var req struct {
-		Status string `json:"status" binding:"required"`
-	}
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	var req UpdateStatusRequest
+	if !problem.BindJSON(c, &req) {
 		return
 	}
 
-	if err := h.Repo.UpdateLibraryStatus(userID, mangaID, req.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
+	if _, err := h.Service.UpdateStatus(c.Request.Context(), "", c.Param("id"), req.Status); err != nil {
+		problem.Write(c, err)
 		return
 	}
 
@@ -89,18 +66,10 @@ func (h *LibraryHandler) UpdateStatus(c *gin.Context) {
 }
 
 func (h *LibraryHandler) RemoveFromLibrary(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	mangaID := c.Param("id")
-	if err := h.Repo.RemoveFromLibrary(userID, mangaID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove from library"})
+	if err := h.Service.Remove(c.Request.Context(), "", c.Param("id")); err != nil {
+		problem.Write(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Removed from library successfully"})
 }
-
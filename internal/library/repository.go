@@ -1,7 +1,9 @@
 package library
 
 import (
+	"context"
 	"database/sql"
+	"mangahub/pkg/database"
 	"mangahub/pkg/models"
 )
 
@@ -9,14 +11,14 @@ type LibraryRepository struct {
 	DB *sql.DB
 }
 
-func (r *LibraryRepository) AddToLibrary(library models.UserLibrary) error {
-	_, err := r.DB.Exec("INSERT OR REPLACE INTO user_library (id, user_id, manga_id, status) VALUES (?, ?, ?, ?)",
+func (r *LibraryRepository) AddToLibrary(ctx context.Context, library models.UserLibrary) error {
+	_, err := r.DB.ExecContext(ctx, "INSERT OR REPLACE INTO user_library (id, user_id, manga_id, status) VALUES (?, ?, ?, ?)",
 		library.ID, library.UserID, library.MangaID, library.Status)
-	return err
+	return database.Translate(err)
 }
 
-func (r *LibraryRepository) GetUserLibrary(userID string) ([]models.UserLibrary, error) {
-	rows, err := r.DB.Query("SELECT id, user_id, manga_id, status, added_at FROM user_library WHERE user_id = ?", userID)
+func (r *LibraryRepository) GetUserLibrary(ctx context.Context, userID string) ([]models.UserLibrary, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, user_id, manga_id, status, added_at FROM user_library WHERE user_id = ?", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -34,13 +36,37 @@ func (r *LibraryRepository) GetUserLibrary(userID string) ([]models.UserLibrary,
 	return libraries, nil
 }
 
-func (r *LibraryRepository) UpdateLibraryStatus(userID, mangaID, status string) error {
-	_, err := r.DB.Exec("UPDATE user_library SET status = ? WHERE user_id = ? AND manga_id = ?", status, userID, mangaID)
-	return err
+// GetLibraryEntry returns one manga of a user's library
+func (r *LibraryRepository) GetLibraryEntry(ctx context.Context, userID, mangaID string) (models.UserLibrary, error) {
+	var l models.UserLibrary
+	err := r.DB.QueryRowContext(ctx, "SELECT id, user_id, manga_id, status, added_at FROM user_library WHERE user_id = ? AND manga_id = ?", userID, mangaID).
+		Scan(&l.ID, &l.UserID, &l.MangaID, &l.Status, &l.AddedAt)
+	return l, err
 }
 
-func (r *LibraryRepository) RemoveFromLibrary(userID, mangaID string) error {
-	_, err := r.DB.Exec("DELETE FROM user_library WHERE user_id = ? AND manga_id = ?", userID, mangaID)
-	return err
+// UpdateLibraryStatus returns sql.ErrNoRows when the manga is not in the
+// user's library
+func (r *LibraryRepository) UpdateLibraryStatus(ctx context.Context, userID, mangaID, status string) error {
+	result, err := r.DB.ExecContext(ctx, "UPDATE user_library SET status = ? WHERE user_id = ? AND manga_id = ?", status, userID, mangaID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RemoveFromLibrary returns sql.ErrNoRows when the manga is not in the
+// user's library
+func (r *LibraryRepository) RemoveFromLibrary(ctx context.Context, userID, mangaID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM user_library WHERE user_id = ? AND manga_id = ?", userID, mangaID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
 
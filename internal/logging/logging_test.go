@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	logger := New(&buf, "json", level).With("server", "http")
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "served", "status", 200)
+	logger.Debug("hidden")
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "debug is only logged once the level is lowered")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "served", record["msg"])
+	assert.Equal(t, "http", record["server"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, float64(200), record["status"])
+
+	var debug map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &debug))
+	assert.Equal(t, "shown", debug["msg"])
+	assert.NotContains(t, debug, "request_id")
+
+	buf.Reset()
+	New(&buf, "text", nil).InfoContext(WithRequestID(context.Background(), "req-2"), "served")
+	assert.Contains(t, buf.String(), "msg=served request_id=req-2")
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in    string
+		level slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{" warn ", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.level, level, tt.in)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+	assert.Empty(t, RequestID(nil))
+	assert.Equal(t, "abc", RequestID(WithRequestID(context.Background(), "abc")))
+
+	assert.True(t, ValidRequestID(NewRequestID()))
+	assert.True(t, ValidRequestID("trace-1.2_3:4"))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID("two words"))
+	assert.False(t, ValidRequestID("line\nbreak"))
+	assert.False(t, ValidRequestID(strings.Repeat("a", 129)))
+}
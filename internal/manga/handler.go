@@ -2,31 +2,32 @@ package manga
 
 import (
 	"net/http"
-	"mangahub/internal/udp"
+
+	"mangahub/internal/problem"
+	"mangahub/internal/service"
 	"mangahub/pkg/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// MangaHandler serves the catalog over REST through the MangaService
 type MangaHandler struct {
-	Repo      *MangaRepository
-	UDPServer *udp.Server
+	Service *service.MangaService
 }
 
 func (h *MangaHandler) GetAllManga(c *gin.Context) {
-	mangas, err := h.Repo.GetAllManga()
+	page, err := h.Service.List(c.Request.Context(), service.MangaQuery{})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch manga"})
+		problem.Write(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, mangas)
+	c.JSON(http.StatusOK, page.Manga)
 }
 
 func (h *MangaHandler) GetMangaByID(c *gin.Context) {
-	id := c.Param("id")
-	manga, err := h.Repo.GetMangaByID(id)
+	manga, err := h.Service.Get(c.Request.Context(), c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Manga not found"})
+		problem.Write(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, manga)
@@ -34,36 +35,52 @@ func (h *MangaHandler) GetMangaByID(c *gin.Context) {
 
 func (h *MangaHandler) CreateManga(c *gin.Context) {
 	var newManga models.Manga
-	if err := c.BindJSON(&newManga); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if !problem.BindJSON(c, &newManga) {
 		return
 	}
 
-	if err := h.Repo.CreateManga(newManga); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create manga"})
+	created, err := h.Service.Create(c.Request.Context(), newManga)
+	if err != nil {
+		problem.Write(c, err)
 		return
 	}
 
-	// Broadcast new manga notification via UDP
-	if h.UDPServer != nil {
-		h.UDPServer.BroadcastNewManga(newManga.ID, newManga.Title)
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateManga replaces the manga in :id with the request body
+func (h *MangaHandler) UpdateManga(c *gin.Context) {
+	var updated models.Manga
+	if !problem.BindJSON(c, &updated) {
+		return
 	}
+	updated.ID = c.Param("id")
 
-	c.JSON(http.StatusCreated, newManga)
+	updated, err := h.Service.Update(c.Request.Context(), updated)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
 }
 
-func (h *MangaHandler) SearchManga(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+// DeleteManga removes the manga in :id from the catalog
+func (h *MangaHandler) DeleteManga(c *gin.Context) {
+	if err := h.Service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		problem.Write(c, err)
 		return
 	}
 
-	mangas, err := h.Repo.SearchManga(query)
+	c.JSON(http.StatusOK, gin.H{"message": "Manga deleted successfully"})
+}
+
+func (h *MangaHandler) SearchManga(c *gin.Context) {
+	page, err := h.Service.Search(c.Request.Context(), service.MangaQuery{Query: c.Query("q")})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search manga"})
+		problem.Write(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, mangas)
+	c.JSON(http.StatusOK, page.Manga)
 }
@@ -1,13 +1,19 @@
 package manga
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"mangahub/internal/auth"
+	"mangahub/internal/events"
+	"mangahub/internal/problem"
+	"mangahub/internal/service"
 	"mangahub/pkg/models"
 )
 
@@ -18,11 +24,11 @@ func TestMangaHandler_SearchManga(t *testing.T) {
 	defer db.Close()
 
 	repo := &MangaRepository{DB: db}
-	handler := &MangaHandler{Repo: repo}
+	handler := &MangaHandler{Service: &service.MangaService{Store: repo}}
 
 	// Seed data
-	repo.CreateManga(models.Manga{ID: "one-piece", Title: "One Piece", Author: "Oda"})
-	repo.CreateManga(models.Manga{ID: "naruto", Title: "Naruto", Author: "Kishimoto"})
+	repo.CreateManga(context.Background(), models.Manga{ID: "one-piece", Title: "One Piece", Author: "Oda"})
+	repo.CreateManga(context.Background(), models.Manga{ID: "naruto", Title: "Naruto", Author: "Kishimoto"})
 
 	// Setup Router
 	r := gin.Default()
@@ -47,4 +53,61 @@ func TestMangaHandler_SearchManga(t *testing.T) {
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "invalid", p.Code)
+	assert.Equal(t, []problem.FieldError{{Field: "query", Message: "query is required"}}, p.Errors)
+}
+
+func TestMangaHandler_UpdateAndDeleteManga(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &MangaRepository{DB: db}
+	bus := events.NewBus()
+	defer bus.Close()
+	published := make(chan events.Event, 2)
+	bus.Subscribe("test", func(e events.Event) { published <- e })
+	handler := &MangaHandler{Service: &service.MangaService{Store: repo, Events: bus}}
+	repo.CreateManga(context.Background(), models.Manga{ID: "naruto", Title: "Naruto", Author: "Kishimoto", TotalChapters: 700})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		admin := auth.Claims{UserID: "admin-1", Role: models.RoleAdmin}
+		c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), admin))
+	})
+	r.PUT("/manga/:id", handler.UpdateManga)
+	r.DELETE("/manga/:id", handler.DeleteManga)
+
+	body := `{"title":"Naruto","author":"Kishimoto","total_chapters":701}`
+	req, _ := http.NewRequest("PUT", "/manga/naruto", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stored, err := repo.GetMangaByID(context.Background(), "naruto")
+	assert.NoError(t, err)
+	assert.Equal(t, 701, stored.TotalChapters)
+	updated := (<-published).(events.MangaUpdated)
+	assert.Equal(t, 700, updated.PreviousChapters)
+	assert.Equal(t, 701, updated.Manga.TotalChapters)
+
+	req, _ = http.NewRequest("PUT", "/manga/bleach", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/manga/naruto", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "naruto", (<-published).(events.MangaDeleted).MangaID)
+
+	req, _ = http.NewRequest("DELETE", "/manga/naruto", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
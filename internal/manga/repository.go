@@ -1,8 +1,10 @@
 package manga
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"mangahub/pkg/database"
 	"mangahub/pkg/models"
 )
 
@@ -10,8 +12,8 @@ type MangaRepository struct {
 	DB *sql.DB
 }
 
-func (r *MangaRepository) GetAllManga() ([]models.Manga, error) {
-	rows, err := r.DB.Query("SELECT id, title, author, genres, status, total_chapters, description, cover_url FROM manga")
+func (r *MangaRepository) GetAllManga(ctx context.Context) ([]models.Manga, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, title, author, genres, status, total_chapters, description, cover_url FROM manga")
 	if err != nil {
 		return nil, err
 	}
@@ -33,10 +35,10 @@ func (r *MangaRepository) GetAllManga() ([]models.Manga, error) {
 	return mangas, nil
 }
 
-func (r *MangaRepository) GetMangaByID(id string) (models.Manga, error) {
+func (r *MangaRepository) GetMangaByID(ctx context.Context, id string) (models.Manga, error) {
 	var m models.Manga
 	var genresJSON sql.NullString
-	err := r.DB.QueryRow("SELECT id, title, author, genres, status, total_chapters, description, cover_url FROM manga WHERE id = ?", id).
+	err := r.DB.QueryRowContext(ctx, "SELECT id, title, author, genres, status, total_chapters, description, cover_url FROM manga WHERE id = ?", id).
 		Scan(&m.ID, &m.Title, &m.Author, &genresJSON, &m.Status, &m.TotalChapters, &m.Description, &m.CoverURL)
 	if err != nil {
 		return m, err
@@ -47,15 +49,15 @@ func (r *MangaRepository) GetMangaByID(id string) (models.Manga, error) {
 	return m, nil
 }
 
-func (r *MangaRepository) CreateManga(manga models.Manga) error {
+func (r *MangaRepository) CreateManga(ctx context.Context, manga models.Manga) error {
 	genresJSON, _ := json.Marshal(manga.Genres)
-	_, err := r.DB.Exec("INSERT INTO manga (id, title, author, genres, status, total_chapters, description, cover_url) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+	_, err := r.DB.ExecContext(ctx, "INSERT INTO manga (id, title, author, genres, status, total_chapters, description, cover_url) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
 		manga.ID, manga.Title, manga.Author, string(genresJSON), manga.Status, manga.TotalChapters, manga.Description, manga.CoverURL)
-	return err
+	return database.Translate(err)
 }
 
-func (r *MangaRepository) SearchManga(query string) ([]models.Manga, error) {
-	rows, err := r.DB.Query("SELECT id, title, author, genres, status, total_chapters, description, cover_url FROM manga WHERE title LIKE ? OR author LIKE ? OR description LIKE ?",
+func (r *MangaRepository) SearchManga(ctx context.Context, query string) ([]models.Manga, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, title, author, genres, status, total_chapters, description, cover_url FROM manga WHERE title LIKE ? OR author LIKE ? OR description LIKE ?",
 		"%"+query+"%", "%"+query+"%", "%"+query+"%")
 	if err != nil {
 		return nil, err
@@ -78,3 +80,31 @@ func (r *MangaRepository) SearchManga(query string) ([]models.Manga, error) {
 	return mangas, nil
 }
 
+
+// UpdateManga replaces every field of a manga. It returns sql.ErrNoRows when
+// the manga does not exist.
+func (r *MangaRepository) UpdateManga(ctx context.Context, manga models.Manga) error {
+	genresJSON, _ := json.Marshal(manga.Genres)
+	result, err := r.DB.ExecContext(ctx, "UPDATE manga SET title = ?, author = ?, genres = ?, status = ?, total_chapters = ?, description = ?, cover_url = ? WHERE id = ?",
+		manga.Title, manga.Author, string(genresJSON), manga.Status, manga.TotalChapters, manga.Description, manga.CoverURL, manga.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteManga removes a manga. It returns sql.ErrNoRows when the manga does
+// not exist.
+func (r *MangaRepository) DeleteManga(ctx context.Context, id string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM manga WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
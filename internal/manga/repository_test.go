@@ -1,6 +1,7 @@
 package manga
 
 import (
+	"context"
 	"database/sql"
 	"mangahub/pkg/models"
 	"testing"
@@ -52,7 +53,7 @@ func TestMangaRepository_CreateManga(t *testing.T) {
 		CoverURL:      "http://example.com/op.jpg",
 	}
 
-	err := repo.CreateManga(manga)
+	err := repo.CreateManga(context.Background(), manga)
 	assert.NoError(t, err)
 
 	// Verify it exists
@@ -79,11 +80,11 @@ func TestMangaRepository_GetMangaByID(t *testing.T) {
 		Description:   "Ninjas...",
 		CoverURL:      "http://example.com/naruto.jpg",
 	}
-	err := repo.CreateManga(manga)
+	err := repo.CreateManga(context.Background(), manga)
 	assert.NoError(t, err)
 
 	// Test Get
-	fetched, err := repo.GetMangaByID("naruto")
+	fetched, err := repo.GetMangaByID(context.Background(), "naruto")
 	assert.NoError(t, err)
 	assert.Equal(t, manga.ID, fetched.ID)
 	assert.Equal(t, manga.Title, fetched.Title)
@@ -101,12 +102,12 @@ func TestMangaRepository_SearchManga(t *testing.T) {
 	m2 := models.Manga{ID: "aot-jr", Title: "Attack on Titan: Junior High", Author: "Isayama", Description: "School"}
 	m3 := models.Manga{ID: "bleach", Title: "Bleach", Author: "Kubo", Description: "Ghosts"}
 
-	repo.CreateManga(m1)
-	repo.CreateManga(m2)
-	repo.CreateManga(m3)
+	repo.CreateManga(context.Background(), m1)
+	repo.CreateManga(context.Background(), m2)
+	repo.CreateManga(context.Background(), m3)
 
 	// Search "Titan"
-	results, err := repo.SearchManga("Titan")
+	results, err := repo.SearchManga(context.Background(), "Titan")
 	assert.NoError(t, err)
 	assert.Len(t, results, 2)
 	
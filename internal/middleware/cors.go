@@ -1,23 +1,146 @@
 package middleware
 
 import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mangahub/internal/problem"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS middleware for handling cross-origin requests
-func CORS() gin.HandlerFunc {
+// CORSPolicy decides which browser origins may call a route and what they
+// may send and read
+type CORSPolicy struct {
+	// AllowedOrigins lists origins such as "https://app.example.com".
+	// "https://*.example.com" allows every subdomain and "*" any origin.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts may read
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and HTTP authentication
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// AllowsOrigin reports whether origin matches one of AllowedOrigins
+func (p CORSPolicy) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(strings.TrimRight(allowed, "/"))
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		scheme, host, found := strings.Cut(allowed, "://*.")
+		if !found {
+			continue
+		}
+		// The wildcard stands for at least one subdomain label
+		prefix := scheme + "://"
+		suffix := "." + host
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+			len(origin) > len(prefix)+len(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSPolicies choose the CORS policy of each route
+type CORSPolicies struct {
+	Default CORSPolicy
+	// Routes overrides the default for route patterns such as
+	// "/api/v1/openapi.json"
+	Routes map[string]CORSPolicy
+}
+
+// For returns the policy of a route pattern
+func (p CORSPolicies) For(route string) CORSPolicy {
+	if policy, ok := p.Routes[route]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+// CORS adds the CORS headers of the route's policy to responses for allowed
+// origins and answers their preflight requests. Preflights only reach it for
+// paths with an OPTIONS route, which HandleOptions registers; other
+// OPTIONS requests are not found as usual.
+func CORS(policies CORSPolicies) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		route := c.FullPath()
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" && route != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		origin := c.GetHeader("Origin")
+		if origin == "" || route == "" {
+			c.Next()
+			return
+		}
+		policy := policies.For(route)
+		if !policy.AllowsOrigin(origin) {
+			if preflight {
+				problem.Abort(c, problem.New(http.StatusForbidden, "Origin not allowed"))
+				return
+			}
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// The origin is echoed rather than "*", which browsers refuse
+		// together with credentials
+		header.Set("Access-Control-Allow-Origin", origin)
+		if policy.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			if policy.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		if len(policy.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+		}
 		c.Next()
 	}
 }
 
+// HandleOptions registers an OPTIONS route for every path of router, so
+// CORS can answer preflight requests for them. Plain OPTIONS requests get
+// the methods of the path in an Allow header. Call it after registering the
+// other routes. Paths with their own OPTIONS route keep it.
+func HandleOptions(router *gin.Engine) {
+	methods := make(map[string][]string)
+	for _, route := range router.Routes() {
+		methods[route.Path] = append(methods[route.Path], route.Method)
+	}
+	for path, allowed := range methods {
+		if slices.Contains(allowed, http.MethodOptions) {
+			continue
+		}
+		allowed = append(allowed, http.MethodOptions)
+		sort.Strings(allowed)
+		allow := strings.Join(allowed, ", ")
+		router.OPTIONS(path, func(c *gin.Context) {
+			c.Header("Allow", allow)
+			c.Status(http.StatusNoContent)
+		})
+	}
+}
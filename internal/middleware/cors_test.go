@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSPolicy_AllowsOrigin(t *testing.T) {
+	policy := CORSPolicy{AllowedOrigins: []string{"http://localhost:3000/", "https://*.example.com"}}
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"http://localhost:3000", true},
+		{"HTTP://LOCALHOST:3000", true},
+		{"http://localhost:8080", false},
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://example.com", false},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://evilexample.com", false},
+		{"https://example.com.evil.test", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, policy.AllowsOrigin(tt.origin), tt.origin)
+	}
+
+	assert.True(t, CORSPolicy{AllowedOrigins: []string{"*"}}.AllowsOrigin("https://anywhere.test"))
+	assert.False(t, CORSPolicy{}.AllowsOrigin("https://anywhere.test"))
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(CORSPolicies{
+		Default: CORSPolicy{
+			AllowedOrigins:   []string{"https://app.example.com"},
+			AllowedMethods:   []string{"GET", "POST"},
+			ExposedHeaders:   []string{"RateLimit-Remaining"},
+			AllowCredentials: true,
+		},
+		Routes: map[string]CORSPolicy{
+			"/public": {AllowedOrigins: []string{"*"}},
+		},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/private", ok)
+	router.GET("/public", ok)
+	HandleOptions(router)
+
+	get := func(path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/private", "https://app.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "RateLimit-Remaining", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, []string{"Origin"}, w.Header().Values("Vary"))
+
+	// Other origins are served without CORS headers, so browsers hide the
+	// response from them
+	w = get("/private", "https://evil.test")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, []string{"Origin"}, w.Header().Values("Vary"), "caches must not reuse the response for other origins")
+
+	w = get("/private", "")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Routes can have their own policy
+	w = get("/public", "https://evil.test")
+	assert.Equal(t, "https://evil.test", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/private", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, w.Header().Values("Vary"))
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/logging"
+	"mangahub/internal/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries request IDs in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an ID, reusing a valid X-Request-ID sent by
+// the client, and returns it in the response. The ID travels in the request
+// context, so the services, repositories, gRPC gateway calls and the events
+// a request publishes log with it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AccessLog logs every request once it is served: at error level for
+// server errors, otherwise at info level. It runs after RequestID so its
+// lines carry the request ID.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID := auth.GetUserID(c); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		logging.Or(logger).LogAttrs(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
+
+// Recovery answers requests whose handler panicked with a 500 problem and
+// logs the panic with its stack
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.Or(logger).ErrorContext(c.Request.Context(), "Handler panicked",
+					"panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+				problem.Abort(c, problem.New(http.StatusInternalServerError, "internal error"))
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mangahub/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+	})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("client-42")
+	assert.Equal(t, "client-42", w.Header().Get(RequestIDHeader))
+	assert.Equal(t, "client-42", w.Body.String(), "handlers see the ID in the request context")
+
+	w = get("")
+	assert.True(t, logging.ValidRequestID(w.Header().Get(RequestIDHeader)))
+	assert.Equal(t, w.Header().Get(RequestIDHeader), w.Body.String())
+
+	// IDs that could forge log lines are replaced
+	w = get("bad id\n")
+	assert.NotEqual(t, "bad id\n", w.Header().Get(RequestIDHeader))
+	assert.True(t, logging.ValidRequestID(w.Header().Get(RequestIDHeader)))
+}
+
+func TestAccessLogAndRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := logging.New(&buf, "json", nil)
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logger), Recovery(logger))
+	router.GET("/manga/:id", func(c *gin.Context) {
+		c.Set("user_id", "alice")
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	serve := func(path string) (*httptest.ResponseRecorder, []map[string]interface{}) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var records []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+		return w, records
+	}
+
+	w, records := serve("/manga/berserk")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, records, 1)
+	assert.Equal(t, "HTTP request", records[0]["msg"])
+	assert.Equal(t, slog.LevelInfo.String(), records[0]["level"])
+	assert.Equal(t, "req-1", records[0]["request_id"])
+	assert.Equal(t, "GET", records[0]["method"])
+	assert.Equal(t, "/manga/berserk", records[0]["path"])
+	assert.Equal(t, "/manga/:id", records[0]["route"])
+	assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+	assert.Equal(t, float64(2), records[0]["bytes"])
+	assert.Equal(t, "alice", records[0]["user_id"])
+
+	w, records = serve("/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"internal"`)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Handler panicked", records[0]["msg"])
+	assert.Equal(t, "boom", records[0]["panic"])
+	assert.Equal(t, "req-1", records[0]["request_id"])
+	assert.Equal(t, slog.LevelError.String(), records[1]["level"], "server errors are logged as errors")
+	assert.Equal(t, float64(http.StatusInternalServerError), records[1]["status"])
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/problem"
+	"mangahub/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits each client to the policy of the route it calls. Clients
+// with a valid bearer token are limited by user ID, others by IP, so users
+// behind a shared address do not use up each other's requests. Policies are
+// looked up by method and route pattern, as in "POST /api/v1/auth/login".
+func RateLimit(store ratelimit.Store, policies ratelimit.Policies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		limiter := policies.Limiter(store, "http", route)
+		if limiter.Limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		result := limiter.Allow(c.Request.Context(), clientKey(c))
+		setRateLimitHeaders(c.Writer.Header(), result)
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(result.RetryAfterSeconds()))
+			problem.Abort(c, problem.New(http.StatusTooManyRequests, "Too many requests, retry later"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// ClientIP records the client IP gin resolved, trusting X-Forwarded-For only
+// from the router's trusted proxies, in the request context. Handlers that
+// limit by IP outside gin, like the WebSocket upgrade and the gRPC gateway,
+// read it with ratelimit.ClientIP.
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(ratelimit.WithClientIP(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders describes result with the RateLimit-* headers of the
+// IETF rate limit fields draft
+func setRateLimitHeaders(header http.Header, result ratelimit.Result) {
+	if result.Limit.Unlimited() {
+		return
+	}
+	window := int(math.Ceil(result.Limit.Window().Seconds()))
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit.Burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(result.ResetSeconds()))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.Limit.Burst, window))
+}
+
+// clientKey identifies the caller by user ID when the request has a valid
+// token, otherwise by IP
+func clientKey(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		if claims, err := auth.ParseTokenClaims(token); err == nil && claims.UserID != "" {
+			return "user:" + claims.UserID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/problem"
+	"mangahub/internal/ratelimit"
+	"mangahub/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(ratelimit.NewMemoryStore(), ratelimit.Policies{
+		Default: ratelimit.Limit{Rate: 1, Burst: 3},
+		Routes:  map[string]ratelimit.Limit{"POST /login": {Rate: 0.1, Burst: 1}},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/login", ok)
+	router.GET("/manga/:id", ok)
+
+	token, err := auth.GenerateToken(models.User{ID: "u1", Username: "alice"})
+	require.NoError(t, err)
+	call := func(method, path, ip, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call("POST", "/login", "10.0.0.1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "1;w=10", w.Header().Get("RateLimit-Policy"))
+
+	// Login is stricter than the routes using the default limit
+	w = call("POST", "/login", "10.0.0.1", "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "rate_limited", p.Code)
+
+	// The default bucket is shared by every route using it, whatever the
+	// path parameters
+	assert.Equal(t, http.StatusOK, call("GET", "/manga/berserk", "10.0.0.1", "").Code)
+	assert.Equal(t, http.StatusOK, call("GET", "/manga/naruto", "10.0.0.1", "").Code)
+	w = call("GET", "/manga/berserk", "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, call("GET", "/manga/berserk", "10.0.0.1", "").Code)
+
+	// Other IPs and logged in users have their own buckets, wherever the
+	// user connects from
+	assert.Equal(t, http.StatusOK, call("GET", "/manga/berserk", "10.0.0.2", "").Code)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, call("GET", "/manga/berserk", "10.0.0.1", token).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, call("GET", "/manga/berserk", "10.0.0.3", token).Code)
+}
+
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies([]string{"10.0.0.1"}))
+	router.Use(ClientIP())
+	router.GET("/", func(c *gin.Context) {
+		ip, _ := ratelimit.ClientIP(c.Request.Context())
+		c.String(http.StatusOK, ip)
+	})
+
+	call := func(remoteAddr string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "192.0.2.7")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "192.0.2.7", call("10.0.0.1:1234"), "trusted proxies forward the client IP")
+	assert.Equal(t, "10.0.0.2", call("10.0.0.2:1234"), "other clients cannot claim another IP")
+}
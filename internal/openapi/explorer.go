@@ -0,0 +1,22 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+)
+
+// explorer.html has no dependencies so the docs work offline
+//
+//go:embed explorer.html
+var explorerPage string
+
+// Explorer serves a page that lists the operations of the document at
+// specURL and sends requests to them
+func Explorer(specURL string) http.Handler {
+	page := strings.ReplaceAll(explorerPage, "SPEC_URL", specURL)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Group registers routes on a gin router group and documents them in a Spec
+type Group struct {
+	router *gin.RouterGroup
+	spec   *Spec
+	tag    string
+	auth   bool
+}
+
+// Router documents the routes registered through the returned Group on
+// router
+func (s *Spec) Router(router *gin.RouterGroup) *Group {
+	return &Group{router: router, spec: s}
+}
+
+// Group creates a group under path. Its routes are tagged with tag, or
+// with the parent's tag if tag is empty.
+func (g *Group) Group(path, tag string, handlers ...gin.HandlerFunc) *Group {
+	if tag == "" {
+		tag = g.tag
+	}
+	return &Group{router: g.router.Group(path, handlers...), spec: g.spec, tag: tag, auth: g.auth}
+}
+
+// Authenticated creates a group on the same path whose routes need a
+// bearer token, checked by handlers
+func (g *Group) Authenticated(handlers ...gin.HandlerFunc) *Group {
+	return &Group{router: g.router.Group("", handlers...), spec: g.spec, tag: g.tag, auth: true}
+}
+
+// Handle registers and documents a route
+func (g *Group) Handle(method, path string, route Route, handlers ...gin.HandlerFunc) {
+	route.Auth = route.Auth || g.auth
+	g.router.Handle(method, path, handlers...)
+	g.spec.Add(method, g.fullPath(path), g.tag, route)
+}
+
+// GET registers and documents a GET route
+func (g *Group) GET(path string, route Route, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, path, route, handlers...)
+}
+
+// POST registers and documents a POST route
+func (g *Group) POST(path string, route Route, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, path, route, handlers...)
+}
+
+// PUT registers and documents a PUT route
+func (g *Group) PUT(path string, route Route, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, path, route, handlers...)
+}
+
+// DELETE registers and documents a DELETE route
+func (g *Group) DELETE(path string, route Route, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, path, route, handlers...)
+}
+
+func (g *Group) fullPath(path string) string {
+	full := strings.TrimSuffix(g.router.BasePath(), "/") + path
+	if full == "" {
+		return "/"
+	}
+	return full
+}
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema describes t. Named structs go into the components and are
+// referred to, so recursive types terminate.
+func (s *Spec) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	}
+	// interfaces and anything else may hold any value
+	return &Schema{}
+}
+
+// ref adds the named struct t to the components and refers to it
+func (s *Spec) ref(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = title(t.Name())
+		if _, taken := s.schemas[name]; taken {
+			// the same name in another package
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = title(pkg) + name
+		}
+		s.names[t] = name
+		s.schemas[name] = &Schema{Type: "object"}
+		*s.schemas[name] = *s.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object describes the JSON fields of the struct t, including promoted
+// fields of embedded structs
+func (s *Spec) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(t, object)
+	return object
+}
+
+func (s *Spec) fields(t reflect.Type, object *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			// encoding/json promotes the fields of embedded structs, even
+			// unexported ones
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.fields(embedded, object)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schema(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			switch {
+			case rule == "required":
+				object.Required = append(object.Required, name)
+			case strings.HasPrefix(rule, "min="):
+				if min, err := strconv.ParseFloat(rule[len("min="):], 64); err == nil && property.Type != "string" {
+					property.Minimum = &min
+				}
+			}
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		object.Properties[name] = property
+	}
+}
+
+// title capitalizes a Go identifier, so unexported types get schema names
+// like the exported ones
+func title(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
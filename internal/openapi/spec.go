@@ -0,0 +1,292 @@
+// Package openapi describes the REST API as an OpenAPI 3 document. Routes
+// are registered on gin through a Group, which documents each one from the
+// Go types it binds and returns, so the document cannot fall behind the
+// router.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mangahub/internal/problem"
+)
+
+// Version is the OpenAPI version of the documents
+const Version = "3.0.3"
+
+// bearerAuth names the security scheme of authenticated routes
+const bearerAuth = "bearerAuth"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path, keyed by lower case method
+type PathItem map[string]*Operation
+
+// Components holds the schemas operations refer to
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how callers authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Operation is a documented method on a path
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body an operation accepts
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a possible reply of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema describes a JSON value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// MessageResponse is the body of replies that only confirm an action
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// Param documents a query parameter
+type Param struct {
+	Name        string
+	Description string
+	// Type is a JSON schema type, string by default
+	Type     string
+	Required bool
+}
+
+// Route documents one route
+type Route struct {
+	Summary     string
+	Description string
+	// Auth marks a route needing a bearer token. Routes in an
+	// Authenticated group have it already.
+	Auth  bool
+	Query []Param
+	// Body is a value of the type the request body is bound to
+	Body interface{}
+	// Status is the status of a successful reply, 200 by default
+	Status int
+	// Response is a value of the type of a successful reply; nil means an
+	// empty reply
+	Response interface{}
+	// ContentType of a successful reply, application/json by default
+	ContentType string
+	// Errors are the error statuses, replied with a problem.Problem. 401 is
+	// added for authenticated routes.
+	Errors []int
+}
+
+// Spec collects the documented routes of an API
+type Spec struct {
+	info    Info
+	paths   map[string]PathItem
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	mutex   sync.Mutex
+}
+
+// New creates an empty spec
+func New(info Info) *Spec {
+	return &Spec{
+		info:    info,
+		paths:   make(map[string]PathItem),
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Add documents a route. path is a gin path such as /manga/:id.
+func (s *Spec) Add(method, path string, tag string, route Route) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	op := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   make(map[string]Response),
+	}
+	if tag != "" {
+		op.Tags = []string{tag}
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := pathParam(segment); ok {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	for _, param := range route.Query {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &Schema{Type: paramType},
+		})
+	}
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: s.schema(reflect.TypeOf(route.Body))}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		success.Content = map[string]MediaType{contentType: {Schema: s.schema(reflect.TypeOf(route.Response))}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errors := route.Errors
+	if route.Auth {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+		errors = append([]int{http.StatusUnauthorized}, errors...)
+	}
+	for _, code := range errors {
+		op.Responses[strconv.Itoa(code)] = Response{
+			Description: http.StatusText(code),
+			Content:     map[string]MediaType{problem.ContentType: {Schema: s.schema(reflect.TypeOf(problem.Problem{}))}},
+		}
+	}
+
+	item := s.paths[openAPIPath(path)]
+	if item == nil {
+		item = make(PathItem)
+		s.paths[openAPIPath(path)] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Has reports whether a route is documented. path is a gin path.
+func (s *Spec) Has(method, path string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.paths[openAPIPath(path)][strings.ToLower(method)]
+	return ok
+}
+
+// Document returns the OpenAPI document of every route added so far
+func (s *Spec) Document() Document {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   s.paths,
+		Components: Components{
+			Schemas: s.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+}
+
+// ServeHTTP replies with the document as JSON
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(s.Document())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// pathParam returns the name of a gin path parameter segment
+func pathParam(segment string) (string, bool) {
+	if name, ok := strings.CutPrefix(segment, ":"); ok {
+		return name, true
+	}
+	return strings.CutPrefix(segment, "*")
+}
+
+// openAPIPath turns /manga/:id into /manga/{id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := pathParam(segment); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
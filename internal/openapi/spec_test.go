@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mangahub/internal/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type base struct {
+	ID        string    `json:"id" binding:"required"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type node struct {
+	base
+	Name     string            `json:"name" binding:"required"`
+	Count    int               `json:"count" binding:"min=1"`
+	Kind     string            `json:"kind" enum:"a,b"`
+	Children []node            `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestSpec_Schema(t *testing.T) {
+	s := New(Info{Title: "test", Version: "1"})
+	s.Add(http.MethodPost, "/nodes/:id", "nodes", Route{Body: node{}, Response: []node{}})
+
+	op := s.Document().Paths["/nodes/{id}"]["post"]
+	require.NotNil(t, op)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}, op.Parameters[0])
+	assert.Equal(t, "#/components/schemas/Node", op.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "array", op.Responses["200"].Content["application/json"].Schema.Type)
+
+	schema := s.Document().Components.Schemas["Node"]
+	require.NotNil(t, schema)
+	assert.ElementsMatch(t, []string{"id", "created_at", "name", "count", "kind", "children", "labels"}, keys(schema.Properties))
+	assert.ElementsMatch(t, []string{"id", "name"}, schema.Required)
+	assert.Equal(t, "date-time", schema.Properties["created_at"].Format)
+	assert.Equal(t, 1.0, *schema.Properties["count"].Minimum)
+	assert.Equal(t, []string{"a", "b"}, schema.Properties["kind"].Enum)
+	assert.Equal(t, "#/components/schemas/Node", schema.Properties["children"].Items.Ref, "recursive types are referred to")
+	assert.Equal(t, "string", schema.Properties["labels"].AdditionalProperties.Type)
+}
+
+func TestSpec_Auth(t *testing.T) {
+	s := New(Info{})
+	s.Add(http.MethodDelete, "/nodes/:id", "", Route{Auth: true, Errors: []int{http.StatusNotFound}})
+
+	op := s.Document().Paths["/nodes/{id}"]["delete"]
+	assert.Equal(t, []map[string][]string{{bearerAuth: {}}}, op.Security)
+	assert.ElementsMatch(t, []string{"200", "401", "404"}, keys(op.Responses))
+	assert.Equal(t, "#/components/schemas/Problem", op.Responses["404"].Content[problem.ContentType].Schema.Ref)
+}
+
+func TestGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	s := New(Info{})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	root := s.Router(&router.RouterGroup)
+	root.GET("/", Route{}, ok)
+	api := root.Group("/api", "api")
+	api.GET("", Route{}, ok)
+	api.Authenticated(func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }).PUT("/:id", Route{}, ok)
+	router.GET("/undocumented", ok)
+
+	assert.True(t, s.Has(http.MethodGet, "/"))
+	assert.True(t, s.Has(http.MethodGet, "/api"))
+	assert.True(t, s.Has(http.MethodPut, "/api/:id"))
+	assert.False(t, s.Has(http.MethodGet, "/undocumented"))
+	op := s.Document().Paths["/api/{id}"]["put"]
+	assert.Equal(t, []string{"api"}, op.Tags)
+	assert.NotEmpty(t, op.Security)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/1", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "group handlers run")
+}
+
+func keys[V any](m map[string]V) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,218 @@
+package presence
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/problem"
+	"mangahub/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PresenceHandler struct {
+	Tracker *Tracker
+	Repo    *PresenceRepository
+}
+
+// VisibilityRequest is the body of SetVisibility and its reply
+type VisibilityRequest struct {
+	Visibility string `json:"visibility" binding:"required" enum:"public,friends,hidden"`
+}
+
+// AddFriendRequest is the body of AddFriend. One of the fields is required.
+type AddFriendRequest struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// AddFriendResponse is the reply of AddFriend
+type AddFriendResponse struct {
+	Message string `json:"message"`
+	UserID  string `json:"user_id"`
+}
+
+// ListOnline returns the online users the caller may see, with what they
+// are reading
+func (h *PresenceHandler) ListOnline(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	online, err := h.Tracker.VisibleTo(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch presence"))
+		return
+	}
+	if !h.addUsernames(c, online) {
+		return
+	}
+
+	c.JSON(http.StatusOK, online)
+}
+
+// GetPresence returns the presence of the user in :id. Users hiding from
+// the caller appear offline.
+func (h *PresenceHandler) GetPresence(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	presence, err := h.Tracker.StatusFor(c.Request.Context(), userID, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		problem.Abort(c, problem.New(http.StatusNotFound, "User not found"))
+		return
+	}
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch presence"))
+		return
+	}
+	list := []models.Presence{presence}
+	if !h.addUsernames(c, list) {
+		return
+	}
+
+	c.JSON(http.StatusOK, list[0])
+}
+
+// SetVisibility changes who may see the caller online
+func (h *PresenceHandler) SetVisibility(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	var req VisibilityRequest
+	if !problem.BindJSON(c, &req) {
+		return
+	}
+	switch req.Visibility {
+	case models.VisibilityPublic, models.VisibilityFriends, models.VisibilityHidden:
+	default:
+		message := "Visibility must be one of public, friends or hidden"
+		problem.Abort(c, problem.New(http.StatusBadRequest, message, problem.FieldError{Field: "visibility", Message: message}))
+		return
+	}
+
+	if err := h.Repo.SetVisibility(c.Request.Context(), userID, req.Visibility); err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to update visibility"))
+		return
+	}
+	h.Tracker.VisibilityChanged(c.Request.Context(), userID)
+
+	c.JSON(http.StatusOK, req)
+}
+
+// ListFriends returns the caller's friends list
+func (h *PresenceHandler) ListFriends(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	friends, err := h.Repo.GetFriends(c.Request.Context(), userID)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch friends"))
+		return
+	}
+
+	c.JSON(http.StatusOK, friends)
+}
+
+// AddFriend puts a user, given by user_id or username, on the caller's
+// friends list
+func (h *PresenceHandler) AddFriend(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	var req AddFriendRequest
+	if !problem.BindJSON(c, &req) {
+		return
+	}
+	if req.UserID == "" && req.Username == "" {
+		message := "Either user_id or username is required"
+		problem.Abort(c, problem.New(http.StatusBadRequest, message,
+			problem.FieldError{Field: "user_id", Message: message}, problem.FieldError{Field: "username", Message: message}))
+		return
+	}
+
+	friendID := req.UserID
+	if friendID == "" {
+		id, err := h.Repo.GetUserIDByUsername(c.Request.Context(), req.Username)
+		if errors.Is(err, sql.ErrNoRows) {
+			problem.Abort(c, problem.New(http.StatusNotFound, "User not found"))
+			return
+		}
+		if err != nil {
+			problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to add friend"))
+			return
+		}
+		friendID = id
+	}
+	if friendID == userID {
+		problem.Abort(c, problem.New(http.StatusBadRequest, "You cannot add yourself as a friend"))
+		return
+	}
+
+	err := h.Repo.AddFriend(c.Request.Context(), userID, friendID)
+	if errors.Is(err, sql.ErrNoRows) {
+		problem.Abort(c, problem.New(http.StatusNotFound, "User not found"))
+		return
+	}
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to add friend"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, AddFriendResponse{Message: "Friend added successfully", UserID: friendID})
+}
+
+// RemoveFriend takes the user in :id off the caller's friends list
+func (h *PresenceHandler) RemoveFriend(c *gin.Context) {
+	userID := auth.GetUserID(c)
+	if userID == "" {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
+		return
+	}
+
+	err := h.Repo.RemoveFriend(c.Request.Context(), userID, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		problem.Abort(c, problem.New(http.StatusNotFound, "Friend not found"))
+		return
+	}
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to remove friend"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend removed successfully"})
+}
+
+// addUsernames fills in the usernames of a presence list, replying with 500
+// and returning false when they cannot be loaded
+func (h *PresenceHandler) addUsernames(c *gin.Context, list []models.Presence) bool {
+	ids := make([]string, len(list))
+	for i, presence := range list {
+		ids[i] = presence.UserID
+	}
+	usernames, err := h.Repo.GetUsernames(c.Request.Context(), ids)
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusInternalServerError, "Failed to fetch presence"))
+		return false
+	}
+	for i := range list {
+		list[i].Username = usernames[list[i].UserID]
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package presence
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"mangahub/pkg/models"
+)
+
+type PresenceRepository struct {
+	DB *sql.DB
+}
+
+// GetVisibility returns a user's visibility setting
+func (r *PresenceRepository) GetVisibility(ctx context.Context, userID string) (string, error) {
+	var visibility string
+	err := r.DB.QueryRowContext(ctx, "SELECT presence_visibility FROM users WHERE id = ?", userID).Scan(&visibility)
+	return visibility, err
+}
+
+// SetVisibility changes a user's visibility setting. It returns
+// sql.ErrNoRows when the user does not exist.
+func (r *PresenceRepository) SetVisibility(ctx context.Context, userID, visibility string) error {
+	result, err := r.DB.ExecContext(ctx, "UPDATE users SET presence_visibility = ? WHERE id = ?", visibility, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetFriendIDs returns the IDs of the users on a user's friends list
+func (r *PresenceRepository) GetFriendIDs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT friend_id FROM friends WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetFriends returns a user's friends list ordered by username
+func (r *PresenceRepository) GetFriends(ctx context.Context, userID string) ([]models.Friend, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT f.friend_id, u.username, f.created_at
+		FROM friends f JOIN users u ON u.id = f.friend_id
+		WHERE f.user_id = ?
+		ORDER BY u.username`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	friends := []models.Friend{}
+	for rows.Next() {
+		var friend models.Friend
+		if err := rows.Scan(&friend.UserID, &friend.Username, &friend.AddedAt); err != nil {
+			return nil, err
+		}
+		friends = append(friends, friend)
+	}
+	return friends, rows.Err()
+}
+
+// AddFriend puts friendID on a user's friends list. Adding someone twice is
+// a no-op. It returns sql.ErrNoRows when friendID does not exist.
+func (r *PresenceRepository) AddFriend(ctx context.Context, userID, friendID string) error {
+	var exists int
+	if err := r.DB.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = ?", friendID).Scan(&exists); err != nil {
+		return err
+	}
+	_, err := r.DB.ExecContext(ctx, "INSERT OR IGNORE INTO friends (user_id, friend_id) VALUES (?, ?)", userID, friendID)
+	return err
+}
+
+// RemoveFriend takes friendID off a user's friends list. It returns
+// sql.ErrNoRows when friendID was not on it.
+func (r *PresenceRepository) RemoveFriend(ctx context.Context, userID, friendID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM friends WHERE user_id = ? AND friend_id = ?", userID, friendID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetUsernames maps the given user IDs to usernames, skipping unknown IDs
+func (r *PresenceRepository) GetUsernames(ctx context.Context, userIDs []string) (map[string]string, error) {
+	usernames := make(map[string]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return usernames, nil
+	}
+
+	args := make([]interface{}, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(userIDs)), ",")
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, username FROM users WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		usernames[id] = username
+	}
+	return usernames, rows.Err()
+}
+
+// GetUserIDByUsername looks up a user's ID
+func (r *PresenceRepository) GetUserIDByUsername(ctx context.Context, username string) (string, error) {
+	var id string
+	err := r.DB.QueryRowContext(ctx, "SELECT id FROM users WHERE username = ?", username).Scan(&id)
+	return id, err
+}
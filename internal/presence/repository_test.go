@@ -0,0 +1,84 @@
+package presence
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"mangahub/pkg/models"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+	CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		username TEXT UNIQUE NOT NULL,
+		presence_visibility TEXT NOT NULL DEFAULT 'public'
+	);
+	CREATE TABLE friends (
+		user_id TEXT NOT NULL,
+		friend_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, friend_id)
+	);
+	INSERT INTO users (id, username) VALUES ('u1', 'alice'), ('u2', 'bob'), ('u3', 'carol');`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestPresenceRepository_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo := &PresenceRepository{DB: setupTestDB(t)}
+
+	visibility, err := repo.GetVisibility(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, models.VisibilityPublic, visibility)
+
+	require.NoError(t, repo.SetVisibility(ctx, "u1", models.VisibilityFriends))
+	visibility, err = repo.GetVisibility(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, models.VisibilityFriends, visibility)
+
+	assert.ErrorIs(t, repo.SetVisibility(ctx, "nobody", models.VisibilityHidden), sql.ErrNoRows)
+	_, err = repo.GetVisibility(ctx, "nobody")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPresenceRepository_Friends(t *testing.T) {
+	ctx := context.Background()
+	repo := &PresenceRepository{DB: setupTestDB(t)}
+
+	require.NoError(t, repo.AddFriend(ctx, "u1", "u3"))
+	require.NoError(t, repo.AddFriend(ctx, "u1", "u2"))
+	require.NoError(t, repo.AddFriend(ctx, "u1", "u2"), "adding twice is a no-op")
+	assert.ErrorIs(t, repo.AddFriend(ctx, "u1", "nobody"), sql.ErrNoRows)
+
+	friends, err := repo.GetFriends(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, friends, 2)
+	assert.Equal(t, "bob", friends[0].Username)
+	assert.Equal(t, "carol", friends[1].Username)
+
+	// Friendship is one-way
+	ids, err := repo.GetFriendIDs(ctx, "u2")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	require.NoError(t, repo.RemoveFriend(ctx, "u1", "u3"))
+	assert.ErrorIs(t, repo.RemoveFriend(ctx, "u1", "u3"), sql.ErrNoRows)
+	ids, err = repo.GetFriendIDs(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, ids)
+
+	usernames, err := repo.GetUsernames(ctx, []string{"u1", "u3", "nobody"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"u1": "alice", "u3": "carol"}, usernames)
+}
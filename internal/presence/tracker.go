@@ -0,0 +1,292 @@
+package presence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/pkg/models"
+)
+
+// Transports a user can be connected through
+const (
+	TransportTCP       = "tcp"
+	TransportUDP       = "udp"
+	TransportWebSocket = "websocket"
+	TransportGRPC      = "grpc"
+)
+
+// DefaultReadingWindow is how long after a progress update a user still
+// counts as reading that manga
+const DefaultReadingWindow = 30 * time.Minute
+
+// Store holds each user's visibility setting and friends list
+type Store interface {
+	GetVisibility(ctx context.Context, userID string) (string, error)
+	GetFriendIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// Tracker aggregates the connections of every user across the TCP, UDP and
+// WebSocket servers, together with what they are currently reading. A nil
+// *Tracker ignores connections and reports everyone offline, so servers work
+// without one.
+type Tracker struct {
+	// ReadingWindow is how long progress counts as currently reading
+	ReadingWindow time.Duration
+	// Store decides who may see whom; when nil everyone is public
+	Store Store
+	// Events receives a PresenceChanged for every change
+	Events *events.Bus
+
+	users map[string]*userState
+	mutex sync.Mutex
+}
+
+type userState struct {
+	connections map[string]int
+	reading     *models.Reading
+	readingAt   time.Time
+	lastSeen    time.Time
+}
+
+// NewTracker creates a tracker
+func NewTracker(store Store, bus *events.Bus) *Tracker {
+	return &Tracker{
+		ReadingWindow: DefaultReadingWindow,
+		Store:         store,
+		Events:        bus,
+		users:         make(map[string]*userState),
+	}
+}
+
+// state returns the state of a user, creating it. Must hold the mutex.
+func (t *Tracker) state(userID string) *userState {
+	state, ok := t.users[userID]
+	if !ok {
+		state = &userState{connections: make(map[string]int)}
+		t.users[userID] = state
+	}
+	return state
+}
+
+// Connect records a new connection of a user over transport
+func (t *Tracker) Connect(userID, transport string) {
+	if t == nil || userID == "" {
+		return
+	}
+	t.mutex.Lock()
+	state := t.state(userID)
+	state.connections[transport]++
+	changed := state.connections[transport] == 1
+	presence := t.presence(userID, state)
+	t.mutex.Unlock()
+
+	if changed {
+		t.publish(context.Background(), presence, false)
+	}
+}
+
+// Disconnect records that a connection of a user over transport closed
+func (t *Tracker) Disconnect(userID, transport string) {
+	if t == nil || userID == "" {
+		return
+	}
+	t.mutex.Lock()
+	state, ok := t.users[userID]
+	if !ok || state.connections[transport] == 0 {
+		t.mutex.Unlock()
+		return
+	}
+	state.connections[transport]--
+	changed := state.connections[transport] == 0
+	if changed {
+		delete(state.connections, transport)
+	}
+	if len(state.connections) == 0 {
+		state.lastSeen = time.Now()
+	}
+	presence := t.presence(userID, state)
+	t.mutex.Unlock()
+
+	if changed {
+		t.publish(context.Background(), presence, false)
+	}
+}
+
+// HandleEvent derives what users are reading from their progress.
+// Subscribe it to an events.Bus.
+func (t *Tracker) HandleEvent(event events.Event) {
+	e, ok := event.(events.ProgressUpdated)
+	if !ok || t == nil {
+		return
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	t.mutex.Lock()
+	state := t.state(e.UserID)
+	state.reading = &models.Reading{
+		MangaID: e.MangaID,
+		Chapter: e.Chapter,
+		Since:   e.Timestamp.UTC().Format(time.RFC3339),
+	}
+	state.readingAt = e.Timestamp
+	presence := t.presence(e.UserID, state)
+	t.mutex.Unlock()
+
+	if presence.Online {
+		t.publish(events.Context(event), presence, false)
+	}
+}
+
+// VisibilityChanged tells the tracker a user changed their visibility.
+// Everyone is told the user went offline, then those who may still see
+// them get their actual presence.
+func (t *Tracker) VisibilityChanged(ctx context.Context, userID string) {
+	if t == nil {
+		return
+	}
+	presence := t.Status(userID)
+	if !presence.Online {
+		return
+	}
+	t.publish(ctx, models.Presence{UserID: userID}, true)
+	t.publish(ctx, presence, false)
+}
+
+// publish announces a presence change, traced to the request in ctx
+func (t *Tracker) publish(ctx context.Context, presence models.Presence, everyone bool) {
+	e := events.PresenceChanged{
+		Trace:      events.TraceOf(ctx),
+		UserID:     presence.UserID,
+		Online:     presence.Online,
+		Transports: presence.Transports,
+		Everyone:   everyone,
+		Timestamp:  time.Now(),
+	}
+	if presence.Reading != nil {
+		e.MangaID = presence.Reading.MangaID
+		e.Chapter = presence.Reading.Chapter
+	}
+	t.Events.Publish(e)
+}
+
+// presence builds a user's presence. Must hold the mutex.
+func (t *Tracker) presence(userID string, state *userState) models.Presence {
+	presence := models.Presence{UserID: userID, Online: len(state.connections) > 0}
+	if !presence.Online {
+		if !state.lastSeen.IsZero() {
+			presence.LastSeen = state.lastSeen.UTC().Format(time.RFC3339)
+		}
+		return presence
+	}
+
+	for transport := range state.connections {
+		presence.Transports = append(presence.Transports, transport)
+	}
+	sort.Strings(presence.Transports)
+
+	if state.reading != nil && time.Since(state.readingAt) < t.ReadingWindow {
+		reading := *state.reading
+		presence.Reading = &reading
+	}
+	return presence
+}
+
+// Status returns a user's presence, regardless of their visibility
+func (t *Tracker) Status(userID string) models.Presence {
+	if t == nil {
+		return models.Presence{UserID: userID}
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	state, ok := t.users[userID]
+	if !ok {
+		return models.Presence{UserID: userID}
+	}
+	return t.presence(userID, state)
+}
+
+// Online returns the presence of every online user, regardless of their
+// visibility, ordered by user ID
+func (t *Tracker) Online() []models.Presence {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var online []models.Presence
+	for userID, state := range t.users {
+		if len(state.connections) > 0 {
+			online = append(online, t.presence(userID, state))
+		}
+	}
+	sort.Slice(online, func(i, j int) bool { return online[i].UserID < online[j].UserID })
+	return online
+}
+
+// Audience returns who may see a user's presence: everyone, or only the
+// users listed. A hidden user has an empty audience.
+func (t *Tracker) Audience(ctx context.Context, userID string) (everyone bool, userIDs []string, err error) {
+	if t == nil || t.Store == nil {
+		return true, nil, nil
+	}
+	visibility, err := t.Store.GetVisibility(ctx, userID)
+	if err != nil {
+		return false, nil, err
+	}
+	switch visibility {
+	case models.VisibilityFriends:
+		userIDs, err = t.Store.GetFriendIDs(ctx, userID)
+		return false, userIDs, err
+	case models.VisibilityHidden:
+		return false, nil, nil
+	}
+	return true, nil, nil
+}
+
+// CanSee reports whether viewerID may see the presence of userID
+func (t *Tracker) CanSee(ctx context.Context, viewerID, userID string) (bool, error) {
+	if viewerID == userID {
+		return true, nil
+	}
+	everyone, audience, err := t.Audience(ctx, userID)
+	if err != nil || everyone {
+		return everyone, err
+	}
+	for _, id := range audience {
+		if id == viewerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// StatusFor returns a user's presence as viewerID sees it: users who hide
+// from the viewer appear offline
+func (t *Tracker) StatusFor(ctx context.Context, viewerID, userID string) (models.Presence, error) {
+	visible, err := t.CanSee(ctx, viewerID, userID)
+	if err != nil || !visible {
+		return models.Presence{UserID: userID}, err
+	}
+	return t.Status(userID), nil
+}
+
+// VisibleTo returns the online users viewerID may see
+func (t *Tracker) VisibleTo(ctx context.Context, viewerID string) ([]models.Presence, error) {
+	visible := []models.Presence{}
+	for _, presence := range t.Online() {
+		ok, err := t.CanSee(ctx, viewerID, presence.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, presence)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,149 @@
+package presence
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/internal/logging"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore keeps visibility and friends lists in memory
+type memoryStore struct {
+	visibility map[string]string
+	friends    map[string][]string
+	// requestID is the request ID of the last lookup
+	requestID string
+}
+
+func (s *memoryStore) GetVisibility(ctx context.Context, userID string) (string, error) {
+	s.requestID = logging.RequestID(ctx)
+	if v, ok := s.visibility[userID]; ok {
+		return v, nil
+	}
+	return models.VisibilityPublic, nil
+}
+
+func (s *memoryStore) GetFriendIDs(_ context.Context, userID string) ([]string, error) {
+	return s.friends[userID], nil
+}
+
+// subscribe records the presence changes published on bus
+func subscribe(t *testing.T, bus *events.Bus) func() []events.PresenceChanged {
+	var (
+		changes []events.PresenceChanged
+		mutex   sync.Mutex
+	)
+	t.Cleanup(bus.Subscribe("test", func(e events.Event) {
+		if change, ok := e.(events.PresenceChanged); ok {
+			mutex.Lock()
+			changes = append(changes, change)
+			mutex.Unlock()
+		}
+	}))
+	return func() []events.PresenceChanged {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]events.PresenceChanged(nil), changes...)
+	}
+}
+
+func TestTracker_AggregatesTransports(t *testing.T) {
+	bus := events.NewBus()
+	defer bus.Close()
+	changes := subscribe(t, bus)
+	tracker := NewTracker(nil, bus)
+
+	tracker.Connect("u1", TransportWebSocket)
+	tracker.Connect("u1", TransportWebSocket)
+	tracker.Connect("u1", TransportTCP)
+	assert.Equal(t, []string{TransportTCP, TransportWebSocket}, tracker.Status("u1").Transports)
+
+	// Closing one of two WebSocket connections changes nothing
+	tracker.Disconnect("u1", TransportWebSocket)
+	tracker.Disconnect("u1", TransportTCP)
+	assert.True(t, tracker.Status("u1").Online)
+	assert.Equal(t, []string{TransportWebSocket}, tracker.Status("u1").Transports)
+
+	tracker.Disconnect("u1", TransportWebSocket)
+	status := tracker.Status("u1")
+	assert.False(t, status.Online)
+	assert.NotEmpty(t, status.LastSeen)
+	assert.Empty(t, tracker.Online())
+
+	// Unknown connections are ignored
+	tracker.Disconnect("u1", TransportUDP)
+
+	require.Eventually(t, func() bool { return len(changes()) == 4 }, time.Second, 10*time.Millisecond)
+	online := []bool{}
+	for _, change := range changes() {
+		online = append(online, change.Online)
+	}
+	assert.Equal(t, []bool{true, true, true, false}, online)
+}
+
+func TestTracker_CurrentlyReading(t *testing.T) {
+	tracker := NewTracker(nil, nil)
+	tracker.ReadingWindow = time.Minute
+
+	tracker.HandleEvent(events.ProgressUpdated{UserID: "u1", MangaID: "naruto", Chapter: 5, Timestamp: time.Now()})
+	assert.Nil(t, tracker.Status("u1").Reading, "offline users are not reading")
+
+	tracker.Connect("u1", TransportUDP)
+	reading := tracker.Status("u1").Reading
+	require.NotNil(t, reading)
+	assert.Equal(t, "naruto", reading.MangaID)
+	assert.Equal(t, 5, reading.Chapter)
+
+	// Old progress does not count
+	tracker.HandleEvent(events.ProgressUpdated{UserID: "u1", MangaID: "bleach", Chapter: 1, Timestamp: time.Now().Add(-time.Hour)})
+	assert.Nil(t, tracker.Status("u1").Reading)
+}
+
+func TestTracker_Visibility(t *testing.T) {
+	store := &memoryStore{
+		visibility: map[string]string{"u2": models.VisibilityFriends, "u3": models.VisibilityHidden},
+		friends:    map[string][]string{"u2": {"u1"}},
+	}
+	tracker := NewTracker(store, nil)
+	for _, id := range []string{"u1", "u2", "u3", "u4"} {
+		tracker.Connect(id, TransportWebSocket)
+	}
+
+	ids := func(viewer string) []string {
+		visible, err := tracker.VisibleTo(context.Background(), viewer)
+		require.NoError(t, err)
+		var ids []string
+		for _, p := range visible {
+			ids = append(ids, p.UserID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"u1", "u2", "u4"}, ids("u1"))
+	assert.Equal(t, []string{"u1", "u3", "u4"}, ids("u3"), "users always see themselves")
+	assert.Equal(t, []string{"u1", "u4"}, ids("u4"))
+
+	hidden, err := tracker.StatusFor(context.Background(), "u1", "u3")
+	require.NoError(t, err)
+	assert.False(t, hidden.Online)
+
+	everyone, audience, err := tracker.Audience(logging.WithRequestID(context.Background(), "req-1"), "u2")
+	require.NoError(t, err)
+	assert.False(t, everyone)
+	assert.Equal(t, []string{"u1"}, audience)
+	assert.Equal(t, "req-1", store.requestID, "lookups run with the caller's context")
+}
+
+func TestTracker_NilIsOffline(t *testing.T) {
+	var tracker *Tracker
+	tracker.Connect("u1", TransportTCP)
+	tracker.HandleEvent(events.ProgressUpdated{UserID: "u1"})
+	assert.False(t, tracker.Status("u1").Online)
+	assert.Empty(t, tracker.Online())
+}
@@ -0,0 +1,68 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// BindJSON binds the request body to obj, replying with a validation
+// problem naming the invalid fields and returning false when it cannot
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		Abort(c, New(http.StatusBadRequest, "Invalid request body"))
+		return false
+	}
+	fields := make([]FieldError, 0, len(invalid))
+	messages := make([]string, 0, len(invalid))
+	for _, fe := range invalid {
+		field := FieldError{Field: jsonName(obj, fe.StructField()), Message: message(fe)}
+		fields = append(fields, field)
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+	Abort(c, New(http.StatusBadRequest, strings.Join(messages, "; "), fields...))
+	return false
+}
+
+// jsonName returns the JSON name of a field of the struct obj points to
+func jsonName(obj interface{}, name string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return name
+	}
+	field, ok := t.FieldByName(name)
+	if !ok {
+		return name
+	}
+	if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+		return tag
+	}
+	return name
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + fe.Param()
+	}
+	return "is invalid"
+}
@@ -0,0 +1,87 @@
+// Package problem writes REST errors as RFC 7807 problem+json bodies
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType of problem bodies
+const ContentType = "application/problem+json"
+
+// FieldError explains why one field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is the body of every REST error reply
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Code is a stable identifier of the kind of error, such as not_found
+	Code string `json:"code"`
+	// Errors lists the invalid fields of validation errors
+	Errors []FieldError `json:"errors,omitempty"`
+	// Error repeats Detail for clients written against the earlier
+	// {"error": "..."} bodies
+	Error string `json:"error"`
+}
+
+// Problemer is implemented by errors that know how they are reported
+type Problemer interface {
+	Problem() Problem
+}
+
+// Codes of the problems created here, for errors that are not Problemers
+var codes = map[int]string{
+	http.StatusBadRequest:          "invalid",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusTooManyRequests:     "rate_limited",
+	http.StatusInternalServerError: "internal",
+}
+
+// New creates a problem with the given status
+func New(status int, detail string, fields ...FieldError) Problem {
+	code, ok := codes[status]
+	if !ok {
+		code = "error"
+	}
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: fields,
+		Error:  detail,
+	}
+}
+
+// From reports err as a problem. Errors that are not Problemers become
+// internal errors without details.
+func From(err error) Problem {
+	var p Problemer
+	if errors.As(err, &p) {
+		return p.Problem()
+	}
+	return New(http.StatusInternalServerError, "internal error")
+}
+
+// Abort replies with p and stops the handler chain
+func Abort(c *gin.Context, p Problem) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Write replies with the problem reporting err
+func Write(c *gin.Context, err error) {
+	Abort(c, From(err))
+}
@@ -0,0 +1,84 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type teapot struct{}
+
+func (teapot) Error() string { return "short and stout" }
+
+func (teapot) Problem() Problem {
+	return New(http.StatusTeapot, "short and stout")
+}
+
+func TestFrom(t *testing.T) {
+	p := From(errors.New("disk on fire"))
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+	assert.Equal(t, "internal", p.Code)
+	assert.NotContains(t, p.Detail, "disk", "unexpected errors are not shown to clients")
+
+	p = From(errors.Join(errors.New("wrapped"), teapot{}))
+	assert.Equal(t, http.StatusTeapot, p.Status)
+	assert.Equal(t, "I'm a teapot", p.Title)
+	assert.Equal(t, "short and stout", p.Error)
+}
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type request struct {
+		MangaID string `json:"manga_id" binding:"required"`
+		Chapter int    `json:"chapter" binding:"required,min=1"`
+	}
+	router := gin.New()
+	router.POST("/", func(c *gin.Context) {
+		var req request
+		if !BindJSON(c, &req) {
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		fields []FieldError
+	}{
+		{"valid", `{"manga_id": "berserk", "chapter": 3}`, http.StatusOK, nil},
+		{"malformed", `{"manga_id": `, http.StatusBadRequest, nil},
+		{"missing fields", `{}`, http.StatusBadRequest, []FieldError{
+			{Field: "manga_id", Message: "is required"},
+			{Field: "chapter", Message: "is required"},
+		}},
+		{"below minimum", `{"manga_id": "berserk", "chapter": -1}`, http.StatusBadRequest, []FieldError{
+			{Field: "chapter", Message: "must be at least 1"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+			var p Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+			assert.Equal(t, "invalid", p.Code)
+			assert.Equal(t, tt.fields, p.Errors)
+			assert.Equal(t, p.Detail, p.Error)
+		})
+	}
+}
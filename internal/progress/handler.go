@@ -2,78 +2,44 @@ package progress
 
 import (
 	"net/http"
-	"mangahub/internal/auth"
-	"mangahub/internal/tcp"
-	"mangahub/internal/udp"
-	"mangahub/pkg/models"
+
+	"mangahub/internal/problem"
+	"mangahub/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
+// ProgressHandler serves the caller's reading progress over REST through
+// the ProgressService
 type ProgressHandler struct {
-	Repo      *ProgressRepository
-	TCPServer *tcp.Server
-	UDPServer *udp.Server
+	Service *service.ProgressService
 }
 
-func (h *ProgressHandler) UpdateProgress(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+// UpdateRequest is the body of UpdateProgress
+type UpdateRequest struct {
+	MangaID string `json:"manga_id" binding:"required"`
+	Chapter int    `json:"chapter" binding:"required,min=1"`
+}
 
-	var req struct {
-		MangaID string `json:"manga_id" binding:"required"`
-		Chapter int    `json:"chapter" binding:"required"`
-	}
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+func (h *ProgressHandler) UpdateProgress(c *gin.Context) {
+	var req UpdateRequest
+	if !problem.BindJSON(c, &req) {
 		return
 	}
 
-	progress := models.UserProgress{
-		ID:      uuid.New().String(),
-		UserID:  userID,
-		MangaID: req.MangaID,
-		Chapter: req.Chapter,
-	}
-
-	if err := h.Repo.UpdateProgress(progress); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update progress"})
+	progress, err := h.Service.Update(c.Request.Context(), "", req.MangaID, req.Chapter)
+	if err != nil {
+		problem.Write(c, err)
 		return
 	}
 
-	// Broadcast progress update via TCP and UDP
-	if h.TCPServer != nil {
-		h.TCPServer.BroadcastProgress(userID, req.MangaID, req.Chapter)
-	}
-
-	if h.UDPServer != nil {
-		h.UDPServer.BroadcastUpdate(
-			"Progress updated",
-			map[string]interface{}{
-				"user_id":  userID,
-				"manga_id": req.MangaID,
-				"chapter":  req.Chapter,
-			},
-		)
-	}
-
 	c.JSON(http.StatusOK, progress)
 }
 
 func (h *ProgressHandler) GetUserProgress(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	progresses, err := h.Repo.GetUserProgress(userID)
+	progresses, err := h.Service.List(c.Request.Context(), "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+		problem.Write(c, err)
 		return
 	}
 
@@ -81,19 +47,11 @@ func (h *ProgressHandler) GetUserProgress(c *gin.Context) {
 }
 
 func (h *ProgressHandler) GetMangaProgress(c *gin.Context) {
-	userID := auth.GetUserID(c)
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	mangaID := c.Param("id")
-	progress, err := h.Repo.GetMangaProgress(userID, mangaID)
+	progress, err := h.Service.Get(c.Request.Context(), "", c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Progress not found"})
+		problem.Write(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, progress)
 }
-
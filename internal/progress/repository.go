@@ -1,7 +1,9 @@
 package progress
 
 import (
+	"context"
 	"database/sql"
+	"mangahub/pkg/database"
 	"mangahub/pkg/models"
 )
 
@@ -9,14 +11,14 @@ type ProgressRepository struct {
 	DB *sql.DB
 }
 
-func (r *ProgressRepository) UpdateProgress(progress models.UserProgress) error {
-	_, err := r.DB.Exec("INSERT OR REPLACE INTO user_progress (id, user_id, manga_id, chapter) VALUES (?, ?, ?, ?)",
+func (r *ProgressRepository) UpdateProgress(ctx context.Context, progress models.UserProgress) error {
+	_, err := r.DB.ExecContext(ctx, "INSERT OR REPLACE INTO user_progress (id, user_id, manga_id, chapter) VALUES (?, ?, ?, ?)",
 		progress.ID, progress.UserID, progress.MangaID, progress.Chapter)
-	return err
+	return database.Translate(err)
 }
 
-func (r *ProgressRepository) GetUserProgress(userID string) ([]models.UserProgress, error) {
-	rows, err := r.DB.Query("SELECT id, user_id, manga_id, chapter, updated_at FROM user_progress WHERE user_id = ?", userID)
+func (r *ProgressRepository) GetUserProgress(ctx context.Context, userID string) ([]models.UserProgress, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, user_id, manga_id, chapter, updated_at FROM user_progress WHERE user_id = ?", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -34,9 +36,9 @@ func (r *ProgressRepository) GetUserProgress(userID string) ([]models.UserProgre
 	return progresses, nil
 }
 
-func (r *ProgressRepository) GetMangaProgress(userID, mangaID string) (models.UserProgress, error) {
+func (r *ProgressRepository) GetMangaProgress(ctx context.Context, userID, mangaID string) (models.UserProgress, error) {
 	var p models.UserProgress
-	err := r.DB.QueryRow("SELECT id, user_id, manga_id, chapter, updated_at FROM user_progress WHERE user_id = ? AND manga_id = ?", userID, mangaID).
+	err := r.DB.QueryRowContext(ctx, "SELECT id, user_id, manga_id, chapter, updated_at FROM user_progress WHERE user_id = ? AND manga_id = ?", userID, mangaID).
 		Scan(&p.ID, &p.UserID, &p.MangaID, &p.Chapter, &p.UpdatedAt)
 	return p, err
 }
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// bucket is the token bucket of one key
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore keeps buckets in this process
+type MemoryStore struct {
+	buckets map[string]*bucket
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take removes a token from the bucket of key
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	burst := float64(limit.Burst)
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	result := Result{Limit: limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((burst - b.tokens) / limit.Rate)
+	return result, nil
+}
+
+// Cleanup forgets buckets that have refilled, which behave like new ones
+func (s *MemoryStore) Cleanup() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.limit.Window() {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RunCleanup calls Cleanup every interval until done is closed
+func (s *MemoryStore) RunCleanup(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Cleanup()
+		case <-done:
+			return
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
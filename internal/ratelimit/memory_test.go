@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mangahub/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore returns a MemoryStore whose clock only moves when advanced
+func testStore() (*MemoryStore, func(time.Duration)) {
+	store := NewMemoryStore()
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+	return store, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	store, advance := testStore()
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(ctx, "alice", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "alice", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// Other keys have their own bucket
+	result, _ = store.Take(ctx, "bob", limit)
+	assert.True(t, result.Allowed)
+
+	// Tokens come back at the rate, up to the burst
+	advance(1500 * time.Millisecond)
+	result, _ = store.Take(ctx, "alice", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, _ = store.Take(ctx, "alice", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, 1, result.RetryAfterSeconds())
+
+	advance(time.Hour)
+	result, _ = store.Take(ctx, "alice", limit)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStore_Cleanup(t *testing.T) {
+	store, advance := testStore()
+	ctx := context.Background()
+
+	store.Take(ctx, "fast", Limit{Rate: 1, Burst: 2})
+	store.Take(ctx, "slow", Limit{Rate: 0.1, Burst: 2})
+
+	advance(5 * time.Second)
+	store.Cleanup()
+	assert.NotContains(t, store.buckets, "fast", "a refilled bucket is forgotten")
+	assert.Contains(t, store.buckets, "slow")
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+
+	var nilLimiter *Limiter
+	assert.True(t, nilLimiter.Allow(ctx, "alice").Allowed)
+
+	unlimited := &Limiter{Store: failingStore{}, Name: "test"}
+	assert.True(t, unlimited.Allow(ctx, "alice").Allowed)
+
+	// An unavailable store does not lock everyone out; the failure is
+	// logged with the request's ID
+	var logs bytes.Buffer
+	failing := &Limiter{Store: failingStore{}, Name: "test", Limit: Limit{Rate: 1, Burst: 1},
+		Logger: logging.New(&logs, "text", nil)}
+	assert.True(t, failing.Allow(logging.WithRequestID(ctx, "req-1"), "alice").Allowed)
+	assert.Contains(t, logs.String(), "Rate limit store failed")
+	assert.Contains(t, logs.String(), "request_id=req-1")
+
+	store, _ := testStore()
+	login := &Limiter{Store: store, Name: "login", Limit: Limit{Rate: 1, Burst: 1}}
+	search := &Limiter{Store: store, Name: "search", Limit: Limit{Rate: 1, Burst: 1}}
+	assert.True(t, login.Allow(ctx, "alice").Allowed)
+	assert.False(t, login.Allow(ctx, "alice").Allowed)
+	assert.True(t, search.Allow(ctx, "alice").Allowed, "limiters have separate buckets")
+}
+
+func TestPolicies_Limiter(t *testing.T) {
+	store := NewMemoryStore()
+	policies := Policies{
+		Default: Limit{Rate: 10, Burst: 20},
+		Routes:  map[string]Limit{"POST /login": {Rate: 0.1, Burst: 5}},
+	}
+
+	login := policies.Limiter(store, "http", "POST /login")
+	assert.Equal(t, "http:POST /login", login.Name)
+	assert.Equal(t, Limit{Rate: 0.1, Burst: 5}, login.Limit)
+
+	list := policies.Limiter(store, "http", "GET /manga")
+	assert.Equal(t, "http:default", list.Name)
+	assert.Equal(t, Limit{Rate: 10, Burst: 20}, list.Limit)
+}
@@ -0,0 +1,121 @@
+// Package ratelimit limits how often clients may act with token buckets kept
+// in a Store. The HTTP middleware, gRPC interceptors and the TCP and
+// WebSocket servers all use it.
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"mangahub/internal/logging"
+)
+
+// Limit is a token bucket policy: the bucket holds up to Burst tokens and
+// refills at Rate tokens per second. A Rate of zero means unlimited.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited reports whether the limit lets everything through
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Window is how long an empty bucket takes to fill up
+func (l Limit) Window() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// Result is the state of a bucket after taking a token from it
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is how many tokens are left
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token when not Allowed
+	RetryAfter time.Duration
+}
+
+// ResetSeconds is Reset rounded up to whole seconds, as headers report it
+func (r Result) ResetSeconds() int {
+	return int(math.Ceil(r.Reset.Seconds()))
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds
+func (r Result) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
+
+// Store keeps token buckets by key. MemoryStore keeps them in this process;
+// a store shared between server instances, such as one backed by Redis, lets
+// several instances behind a load balancer enforce one limit.
+type Store interface {
+	// Take removes a token from the bucket of key, creating a full bucket
+	// for new keys
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter applies one Limit to the keys it is given
+type Limiter struct {
+	Store Store
+	// Name prefixes bucket keys so each policy has its own buckets
+	Name  string
+	Limit Limit
+	// Logger logs store failures; nil uses the default logger
+	Logger *slog.Logger
+}
+
+// Allow takes a token for key. A nil or unlimited Limiter allows
+// everything. Store errors are logged and let the request through, so an
+// unavailable shared store does not take the service down with it.
+func (l *Limiter) Allow(ctx context.Context, key string) Result {
+	if l == nil || l.Limit.Unlimited() {
+		return Result{Allowed: true}
+	}
+	result, err := l.Store.Take(ctx, l.Name+":"+key, l.Limit)
+	if err != nil {
+		logging.Or(l.Logger).ErrorContext(ctx, "Rate limit store failed", "limiter", l.Name, "error", err)
+		return Result{Allowed: true}
+	}
+	return result
+}
+
+// Policies choose the Limit of each route
+type Policies struct {
+	// Default applies to routes without their own limit. Its bucket is
+	// shared by all of them.
+	Default Limit
+	// Routes holds stricter or looser limits, keyed by route such as
+	// "POST /api/v1/auth/login" or a gRPC method name
+	Routes map[string]Limit
+	// Logger is given to the limiters; nil uses the default logger
+	Logger *slog.Logger
+}
+
+// Limiter returns the limiter of a route. Its buckets are named after
+// prefix and the route, or "default" for routes using the default limit.
+func (p Policies) Limiter(store Store, prefix, route string) *Limiter {
+	if limit, ok := p.Routes[route]; ok {
+		return &Limiter{Store: store, Name: prefix + ":" + route, Limit: limit, Logger: p.Logger}
+	}
+	return &Limiter{Store: store, Name: prefix + ":default", Limit: p.Default, Logger: p.Logger}
+}
+
+type clientIPKey struct{}
+
+// WithClientIP records the IP of the client of a request, as seen by the
+// HTTP server that knows which proxies to trust
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the IP recorded by WithClientIP
+func ClientIP(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(string)
+	return ip, ok
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"mangahub/internal/auth"
+)
+
+// caller returns the authenticated user making a call, as stored in ctx by
+// auth.WithUser
+func caller(ctx context.Context) (auth.Claims, error) {
+	claims, ok := auth.UserFromContext(ctx)
+	if !ok || claims.UserID == "" {
+		return auth.Claims{}, errorf(Unauthenticated, "authentication required")
+	}
+	return claims, nil
+}
+
+// ActingUser returns the user a call acts on: the caller, unless requested
+// names someone else. Only admins and service accounts may act on other
+// users.
+func ActingUser(ctx context.Context, requested string) (string, error) {
+	claims, err := caller(ctx)
+	if err != nil {
+		return "", err
+	}
+	if requested == "" || requested == claims.UserID {
+		return claims.UserID, nil
+	}
+	if auth.CanActForAnyUser(claims.Role) {
+		return requested, nil
+	}
+	return "", errorf(PermissionDenied, "cannot act on behalf of another user")
+}
+
+// requireRole only lets callers with one of roles through
+func requireRole(ctx context.Context, roles ...string) error {
+	claims, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if claims.Role == role {
+			return nil
+		}
+	}
+	return errorf(PermissionDenied, "insufficient permissions")
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"mangahub/internal/logging"
+	"mangahub/internal/problem"
+)
+
+// Code classifies an Error so every transport can report it its own way
+type Code int
+
+// Error codes, mapped to HTTP statuses by HTTPStatus and to gRPC codes by
+// the gRPC servers. The zero Code means no error.
+const (
+	Invalid Code = iota + 1
+	NotFound
+	Conflict
+	Unauthenticated
+	PermissionDenied
+	Internal
+)
+
+var codeNames = map[Code]string{
+	Invalid:          "invalid",
+	NotFound:         "not_found",
+	Conflict:         "conflict",
+	Unauthenticated:  "unauthenticated",
+	PermissionDenied: "permission_denied",
+	Internal:         "internal",
+}
+
+// String returns the identifier clients see for the code, such as not_found
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+// Error is returned by the services for requests that cannot be carried out.
+// Message is safe to show to clients. Invalid errors list the fields at
+// fault.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []problem.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Problem reports the error as a REST problem
+func (e *Error) Problem() problem.Problem {
+	p := problem.New(HTTPStatus(e), e.Message, e.Fields...)
+	p.Code = e.Code.String()
+	return p
+}
+
+func errorf(code Code, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// invalid reports the invalid fields of a request, each message naming its
+// field
+func invalid(fields ...problem.FieldError) error {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return &Error{Code: Invalid, Message: strings.Join(messages, "; "), Fields: fields}
+}
+
+// field describes an invalid field for invalid
+func field(name, format string, args ...interface{}) problem.FieldError {
+	return problem.FieldError{Field: name, Message: fmt.Sprintf(format, args...)}
+}
+
+// FieldsOf returns the invalid fields of an Invalid error
+func FieldsOf(err error) []problem.FieldError {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Fields
+	}
+	return nil
+}
+
+// internal logs an unexpected error with logger and hides it behind message
+func internal(ctx context.Context, logger *slog.Logger, message string, err error) error {
+	logging.Or(logger).ErrorContext(ctx, message, "error", err)
+	return &Error{Code: Internal, Message: message}
+}
+
+// CodeOf returns the Code of an Error, Internal for any other error and
+// zero for nil
+func CodeOf(err error) Code {
+	if err == nil {
+		return 0
+	}
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code
+	}
+	return Internal
+}
+
+// HTTPStatus returns the HTTP status reporting err
+func HTTPStatus(err error) int {
+	switch CodeOf(err) {
+	case Invalid:
+		return http.StatusBadRequest
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	case PermissionDenied:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
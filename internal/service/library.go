@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/pkg/database"
+	"mangahub/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// LibraryStatuses are the reading statuses a library entry can have
+var LibraryStatuses = []string{"reading", "completed", "plan_to_read", "dropped"}
+
+// DefaultLibraryStatus is the status of a manga added without one
+const DefaultLibraryStatus = "plan_to_read"
+
+// LibraryStore is the storage LibraryService needs, implemented by
+// library.LibraryRepository
+type LibraryStore interface {
+	AddToLibrary(ctx context.Context, entry models.UserLibrary) error
+	GetUserLibrary(ctx context.Context, userID string) ([]models.UserLibrary, error)
+	GetLibraryEntry(ctx context.Context, userID, mangaID string) (models.UserLibrary, error)
+	UpdateLibraryStatus(ctx context.Context, userID, mangaID, status string) error
+	RemoveFromLibrary(ctx context.Context, userID, mangaID string) error
+}
+
+// LibraryService manages the manga in users' libraries. Every method acts on
+// the caller's library unless an admin or service account names another
+// user; see ActingUser.
+type LibraryService struct {
+	Store LibraryStore
+	// Events receives a LibraryUpdated for every change
+	Events *events.Bus
+	// Logger logs unexpected errors; nil uses the default logger
+	Logger *slog.Logger
+}
+
+// ValidateLibraryStatus checks status is one of LibraryStatuses
+func ValidateLibraryStatus(status string) error {
+	for _, valid := range LibraryStatuses {
+		if status == valid {
+			return nil
+		}
+	}
+	return invalid(field("status", "invalid status %q, must be one of %v", status, LibraryStatuses))
+}
+
+// Add puts a manga in the library, or changes its status if already there.
+// An empty status means DefaultLibraryStatus.
+func (s *LibraryService) Add(ctx context.Context, userID, mangaID, status string) (models.UserLibrary, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return models.UserLibrary{}, err
+	}
+	if mangaID == "" {
+		return models.UserLibrary{}, invalid(field("manga_id", "manga_id is required"))
+	}
+	if status == "" {
+		status = DefaultLibraryStatus
+	}
+	if err := ValidateLibraryStatus(status); err != nil {
+		return models.UserLibrary{}, err
+	}
+
+	entry := models.UserLibrary{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		MangaID: mangaID,
+		Status:  status,
+	}
+	err = s.Store.AddToLibrary(ctx, entry)
+	if errors.Is(err, database.ErrReference) {
+		return models.UserLibrary{}, errorf(NotFound, "manga not found")
+	}
+	if err != nil {
+		return models.UserLibrary{}, internal(ctx, s.Logger, "failed to add to library", err)
+	}
+	s.publish(ctx, userID, mangaID, events.LibraryAdded, status)
+
+	return s.entry(ctx, userID, mangaID)
+}
+
+// List returns every manga in the library
+func (s *LibraryService) List(ctx context.Context, userID string) ([]models.UserLibrary, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.Store.GetUserLibrary(ctx, userID)
+	if err != nil {
+		return nil, internal(ctx, s.Logger, "failed to fetch library", err)
+	}
+	return entries, nil
+}
+
+// UpdateStatus changes the reading status of a manga in the library
+func (s *LibraryService) UpdateStatus(ctx context.Context, userID, mangaID, status string) (models.UserLibrary, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return models.UserLibrary{}, err
+	}
+	if mangaID == "" {
+		return models.UserLibrary{}, invalid(field("manga_id", "manga_id is required"))
+	}
+	if status == "" {
+		return models.UserLibrary{}, invalid(field("status", "status is required"))
+	}
+	if err := ValidateLibraryStatus(status); err != nil {
+		return models.UserLibrary{}, err
+	}
+
+	err = s.Store.UpdateLibraryStatus(ctx, userID, mangaID, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.UserLibrary{}, errorf(NotFound, "manga not in library")
+	}
+	if err != nil {
+		return models.UserLibrary{}, internal(ctx, s.Logger, "failed to update status", err)
+	}
+	s.publish(ctx, userID, mangaID, events.LibraryStatusChanged, status)
+
+	return s.entry(ctx, userID, mangaID)
+}
+
+// Remove takes a manga out of the library
+func (s *LibraryService) Remove(ctx context.Context, userID, mangaID string) error {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if mangaID == "" {
+		return invalid(field("manga_id", "manga_id is required"))
+	}
+
+	err = s.Store.RemoveFromLibrary(ctx, userID, mangaID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorf(NotFound, "manga not in library")
+	}
+	if err != nil {
+		return internal(ctx, s.Logger, "failed to remove from library", err)
+	}
+	s.publish(ctx, userID, mangaID, events.LibraryRemoved, "")
+	return nil
+}
+
+// entry loads a library entry after it changed
+func (s *LibraryService) entry(ctx context.Context, userID, mangaID string) (models.UserLibrary, error) {
+	entry, err := s.Store.GetLibraryEntry(ctx, userID, mangaID)
+	if err != nil {
+		return models.UserLibrary{}, internal(ctx, s.Logger, "failed to load library entry", err)
+	}
+	return entry, nil
+}
+
+// publish announces a change to a user's library
+func (s *LibraryService) publish(ctx context.Context, userID, mangaID, action, status string) {
+	s.Events.Publish(events.LibraryUpdated{
+		Trace:     events.TraceOf(ctx),
+		UserID:    userID,
+		MangaID:   mangaID,
+		Action:    action,
+		Status:    status,
+		Timestamp: time.Now(),
+	})
+}
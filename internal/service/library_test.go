@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryService_Validation(t *testing.T) {
+	alice := as("alice", models.RoleUser)
+	tests := []struct {
+		name   string
+		call   func(s *LibraryService) error
+		code   Code
+		status string
+	}{
+		{"add with default status", func(s *LibraryService) error {
+			_, err := s.Add(alice, "", "berserk", "")
+			return err
+		}, 0, DefaultLibraryStatus},
+		{"add reading", func(s *LibraryService) error {
+			_, err := s.Add(alice, "", "berserk", "reading")
+			return err
+		}, 0, "reading"},
+		{"add unknown status", func(s *LibraryService) error {
+			_, err := s.Add(alice, "", "berserk", "on_hold")
+			return err
+		}, Invalid, ""},
+		{"add unknown manga", func(s *LibraryService) error {
+			_, err := s.Add(alice, "", missingManga, "reading")
+			return err
+		}, NotFound, ""},
+		{"add without manga", func(s *LibraryService) error {
+			_, err := s.Add(alice, "", "", "reading")
+			return err
+		}, Invalid, ""},
+		{"add anonymously", func(s *LibraryService) error {
+			_, err := s.Add(anonymous, "", "berserk", "reading")
+			return err
+		}, Unauthenticated, ""},
+		{"add for another user", func(s *LibraryService) error {
+			_, err := s.Add(alice, "bob", "berserk", "reading")
+			return err
+		}, PermissionDenied, ""},
+		{"update missing", func(s *LibraryService) error {
+			_, err := s.UpdateStatus(alice, "", "monster", "completed")
+			return err
+		}, NotFound, ""},
+		{"update without status", func(s *LibraryService) error {
+			_, err := s.UpdateStatus(alice, "", "berserk", "")
+			return err
+		}, Invalid, ""},
+		{"remove missing", func(s *LibraryService) error {
+			return s.Remove(alice, "", "monster")
+		}, NotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newLibraryStore()
+			s := &LibraryService{Store: store}
+			err := tt.call(s)
+			if tt.code != 0 {
+				assert.Equal(t, tt.code, CodeOf(err))
+				assert.Empty(t, store.entries, "nothing is stored")
+				return
+			}
+			require.NoError(t, err)
+			entry, err := store.GetLibraryEntry(context.Background(), "alice", "berserk")
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, entry.Status)
+		})
+	}
+}
+
+func TestLibraryService_Events(t *testing.T) {
+	bus, next := recordEvents(t)
+	s := &LibraryService{Store: newLibraryStore(), Events: bus}
+	admin := as("root", models.RoleAdmin)
+
+	entry, err := s.Add(admin, "bob", "berserk", "reading")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", entry.UserID)
+	assert.Equal(t, events.LibraryUpdated{UserID: "bob", MangaID: "berserk", Action: events.LibraryAdded, Status: "reading"},
+		withoutTime(next()))
+
+	entry, err = s.UpdateStatus(admin, "bob", "berserk", "completed")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", entry.Status)
+	assert.Equal(t, events.LibraryStatusChanged, next().(events.LibraryUpdated).Action)
+
+	require.NoError(t, s.Remove(admin, "bob", "berserk"))
+	assert.Equal(t, events.LibraryRemoved, next().(events.LibraryUpdated).Action)
+
+	entries, err := s.List(admin, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// withoutTime clears the timestamp of a LibraryUpdated so it can be compared
+func withoutTime(e events.Event) events.LibraryUpdated {
+	updated := e.(events.LibraryUpdated)
+	updated.Timestamp = time.Time{}
+	return updated
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/internal/problem"
+	"mangahub/pkg/database"
+	"mangahub/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// MangaStore is the storage MangaService needs, implemented by
+// manga.MangaRepository
+type MangaStore interface {
+	GetAllManga(ctx context.Context) ([]models.Manga, error)
+	GetMangaByID(ctx context.Context, id string) (models.Manga, error)
+	SearchManga(ctx context.Context, query string) ([]models.Manga, error)
+	CreateManga(ctx context.Context, manga models.Manga) error
+	UpdateManga(ctx context.Context, manga models.Manga) error
+	DeleteManga(ctx context.Context, id string) error
+}
+
+// MangaService reads and edits the catalog. Anyone may read it; creating
+// needs an account and editing or deleting needs an admin.
+type MangaService struct {
+	Store MangaStore
+	// Events receives MangaCreated, MangaUpdated and MangaDeleted
+	Events *events.Bus
+	// Logger logs unexpected errors; nil uses the default logger
+	Logger *slog.Logger
+}
+
+// MangaQuery selects manga from the catalog. Empty fields match everything.
+type MangaQuery struct {
+	// Query matches titles and authors
+	Query  string
+	Genre  string
+	Status string
+	// Page counts from 1 and holds Limit manga. Zero returns every match.
+	Page  int
+	Limit int
+}
+
+// MangaPage is the part of the matching manga on the requested page
+type MangaPage struct {
+	Manga      []models.Manga
+	Total      int
+	Page       int
+	TotalPages int
+}
+
+// Get returns a manga by ID
+func (s *MangaService) Get(ctx context.Context, id string) (models.Manga, error) {
+	if id == "" {
+		return models.Manga{}, invalid(field("manga_id", "manga_id is required"))
+	}
+	m, err := s.Store.GetMangaByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Manga{}, errorf(NotFound, "manga not found")
+	}
+	if err != nil {
+		return models.Manga{}, internal(ctx, s.Logger, "failed to fetch manga", err)
+	}
+	return m, nil
+}
+
+// List returns the manga matching q, searching only when q.Query is set
+func (s *MangaService) List(ctx context.Context, q MangaQuery) (MangaPage, error) {
+	var mangas []models.Manga
+	var err error
+	if q.Query != "" {
+		mangas, err = s.Store.SearchManga(ctx, q.Query)
+	} else {
+		mangas, err = s.Store.GetAllManga(ctx)
+	}
+	if err != nil {
+		return MangaPage{}, internal(ctx, s.Logger, "failed to fetch manga", err)
+	}
+	return paginate(filterManga(mangas, q), q.Page, q.Limit), nil
+}
+
+// Search is List for a query that must not be empty
+func (s *MangaService) Search(ctx context.Context, q MangaQuery) (MangaPage, error) {
+	if q.Query == "" {
+		return MangaPage{}, invalid(field("query", "query is required"))
+	}
+	return s.List(ctx, q)
+}
+
+// Create adds a manga to the catalog, under a new ID unless m has one
+func (s *MangaService) Create(ctx context.Context, m models.Manga) (models.Manga, error) {
+	if _, err := caller(ctx); err != nil {
+		return models.Manga{}, err
+	}
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	if err := validateManga(m); err != nil {
+		return models.Manga{}, err
+	}
+	if _, err := s.Store.GetMangaByID(ctx, m.ID); err == nil {
+		return models.Manga{}, errorf(Conflict, "manga %q already exists", m.ID)
+	}
+
+	err := s.Store.CreateManga(ctx, m)
+	if errors.Is(err, database.ErrDuplicate) {
+		// created since the check above
+		return models.Manga{}, errorf(Conflict, "manga %q already exists", m.ID)
+	}
+	if err != nil {
+		return models.Manga{}, internal(ctx, s.Logger, "failed to create manga", err)
+	}
+	s.Events.Publish(events.MangaCreated{Trace: events.TraceOf(ctx), Manga: m, Timestamp: time.Now()})
+	return m, nil
+}
+
+// Update replaces a manga in the catalog
+func (s *MangaService) Update(ctx context.Context, m models.Manga) (models.Manga, error) {
+	if err := requireRole(ctx, models.RoleAdmin); err != nil {
+		return models.Manga{}, err
+	}
+	if err := validateManga(m); err != nil {
+		return models.Manga{}, err
+	}
+	previous, err := s.Get(ctx, m.ID)
+	if err != nil {
+		return models.Manga{}, err
+	}
+
+	if err := s.Store.UpdateManga(ctx, m); err != nil {
+		return models.Manga{}, internal(ctx, s.Logger, "failed to update manga", err)
+	}
+	s.Events.Publish(events.MangaUpdated{
+		Trace:            events.TraceOf(ctx),
+		Manga:            m,
+		PreviousChapters: previous.TotalChapters,
+		Timestamp:        time.Now(),
+	})
+	return m, nil
+}
+
+// Delete removes a manga from the catalog
+func (s *MangaService) Delete(ctx context.Context, id string) error {
+	if err := requireRole(ctx, models.RoleAdmin); err != nil {
+		return err
+	}
+	if id == "" {
+		return invalid(field("manga_id", "manga_id is required"))
+	}
+
+	err := s.Store.DeleteManga(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorf(NotFound, "manga not found")
+	}
+	if err != nil {
+		return internal(ctx, s.Logger, "failed to delete manga", err)
+	}
+	s.Events.Publish(events.MangaDeleted{Trace: events.TraceOf(ctx), MangaID: id, Timestamp: time.Now()})
+	return nil
+}
+
+// validateManga reports every invalid field of m at once
+func validateManga(m models.Manga) error {
+	var fields []problem.FieldError
+	if m.ID == "" {
+		fields = append(fields, field("id", "id is required"))
+	}
+	if strings.TrimSpace(m.Title) == "" {
+		fields = append(fields, field("title", "title is required"))
+	}
+	if m.TotalChapters < 0 {
+		fields = append(fields, field("total_chapters", "total_chapters cannot be negative"))
+	}
+	if len(fields) > 0 {
+		return invalid(fields...)
+	}
+	return nil
+}
+
+// filterManga keeps the manga in the genre and status of q
+func filterManga(mangas []models.Manga, q MangaQuery) []models.Manga {
+	filtered := []models.Manga{}
+	for _, m := range mangas {
+		if q.Genre != "" && !hasGenre(m, q.Genre) {
+			continue
+		}
+		if q.Status != "" && !strings.EqualFold(m.Status, q.Status) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
+
+func hasGenre(m models.Manga, genre string) bool {
+	for _, g := range m.Genres {
+		if strings.EqualFold(g, genre) {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate returns one page of mangas. A page past the end is empty.
+func paginate(mangas []models.Manga, page, limit int) MangaPage {
+	total := len(mangas)
+	if page < 1 || limit < 1 {
+		return MangaPage{Manga: mangas, Total: total, Page: 1, TotalPages: 1}
+	}
+
+	totalPages := (total + limit - 1) / limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+	return MangaPage{Manga: mangas[start:end], Total: total, Page: page, TotalPages: totalPages}
+}
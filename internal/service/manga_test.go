@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"mangahub/internal/events"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var catalog = []models.Manga{
+	{ID: "berserk", Title: "Berserk", Author: "Miura", Genres: []string{"Dark Fantasy"}, Status: "ongoing"},
+	{ID: "monster", Title: "Monster", Author: "Urasawa", Genres: []string{"Thriller"}, Status: "completed"},
+	{ID: "naruto", Title: "Naruto", Author: "Kishimoto", Genres: []string{"Action", "Adventure"}, Status: "completed"},
+	{ID: "one-piece", Title: "One Piece", Author: "Oda", Genres: []string{"Action", "Adventure"}, Status: "ongoing"},
+}
+
+func mangaIDs(mangas []models.Manga) []string {
+	ids := []string{}
+	for _, m := range mangas {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestMangaService_List(t *testing.T) {
+	s := &MangaService{Store: newMangaStore(catalog...)}
+
+	tests := []struct {
+		name       string
+		query      MangaQuery
+		want       []string
+		total      int
+		totalPages int
+	}{
+		{"everything", MangaQuery{}, []string{"berserk", "monster", "naruto", "one-piece"}, 4, 1},
+		{"genre ignores case", MangaQuery{Genre: "action"}, []string{"naruto", "one-piece"}, 2, 1},
+		{"status", MangaQuery{Status: "Completed"}, []string{"monster", "naruto"}, 2, 1},
+		{"search and filter", MangaQuery{Query: "o", Status: "ongoing"}, []string{"one-piece"}, 1, 1},
+		{"first page", MangaQuery{Page: 1, Limit: 3}, []string{"berserk", "monster", "naruto"}, 4, 2},
+		{"last page", MangaQuery{Page: 2, Limit: 3}, []string{"one-piece"}, 4, 2},
+		{"past the end", MangaQuery{Page: 3, Limit: 3}, []string{}, 4, 2},
+		{"no match", MangaQuery{Genre: "romance", Page: 1, Limit: 3}, []string{}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.List(anonymous, tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mangaIDs(page.Manga))
+			assert.Equal(t, tt.total, page.Total)
+			assert.Equal(t, tt.totalPages, page.TotalPages)
+		})
+	}
+
+	_, err := s.Search(anonymous, MangaQuery{Genre: "action"})
+	assert.Equal(t, Invalid, CodeOf(err))
+}
+
+func TestMangaService_Get(t *testing.T) {
+	s := &MangaService{Store: newMangaStore(catalog...)}
+
+	m, err := s.Get(anonymous, "monster")
+	require.NoError(t, err)
+	assert.Equal(t, "Urasawa", m.Author)
+
+	_, err = s.Get(anonymous, "bleach")
+	assert.Equal(t, NotFound, CodeOf(err))
+	_, err = s.Get(anonymous, "")
+	assert.Equal(t, Invalid, CodeOf(err))
+}
+
+func TestMangaService_Edit(t *testing.T) {
+	admin := as("root", models.RoleAdmin)
+	user := as("alice", models.RoleUser)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		edit func(s *MangaService, ctx context.Context) error
+		code Code
+	}{
+		{"create", user, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Create(ctx, models.Manga{ID: "bleach", Title: "Bleach"})
+			return err
+		}, 0},
+		{"create anonymously", anonymous, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Create(ctx, models.Manga{ID: "bleach", Title: "Bleach"})
+			return err
+		}, Unauthenticated},
+		{"create without title", user, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Create(ctx, models.Manga{ID: "bleach"})
+			return err
+		}, Invalid},
+		{"create existing", user, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Create(ctx, models.Manga{ID: "naruto", Title: "Naruto"})
+			return err
+		}, Conflict},
+		{"update", admin, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Update(ctx, models.Manga{ID: "naruto", Title: "Naruto", TotalChapters: 700})
+			return err
+		}, 0},
+		{"update as user", user, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Update(ctx, models.Manga{ID: "naruto", Title: "Naruto", TotalChapters: 700})
+			return err
+		}, PermissionDenied},
+		{"update negative chapters", admin, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Update(ctx, models.Manga{ID: "naruto", Title: "Naruto", TotalChapters: -1})
+			return err
+		}, Invalid},
+		{"update missing", admin, func(s *MangaService, ctx context.Context) error {
+			_, err := s.Update(ctx, models.Manga{ID: "bleach", Title: "Bleach"})
+			return err
+		}, NotFound},
+		{"delete", admin, func(s *MangaService, ctx context.Context) error {
+			return s.Delete(ctx, "naruto")
+		}, 0},
+		{"delete as user", user, func(s *MangaService, ctx context.Context) error {
+			return s.Delete(ctx, "naruto")
+		}, PermissionDenied},
+		{"delete missing", admin, func(s *MangaService, ctx context.Context) error {
+			return s.Delete(ctx, "bleach")
+		}, NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MangaService{Store: newMangaStore(catalog...)}
+			err := tt.edit(s, tt.ctx)
+			if tt.code != 0 {
+				assert.Equal(t, tt.code, CodeOf(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMangaService_Events(t *testing.T) {
+	bus, next := recordEvents(t)
+	s := &MangaService{Store: newMangaStore(models.Manga{ID: "naruto", Title: "Naruto", TotalChapters: 699}), Events: bus}
+	admin := as("root", models.RoleAdmin)
+
+	created, err := s.Create(admin, models.Manga{Title: "Bleach"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID, "an ID is generated")
+	assert.Equal(t, created, next().(events.MangaCreated).Manga)
+
+	_, err = s.Update(admin, models.Manga{ID: "naruto", Title: "Naruto", TotalChapters: 700})
+	require.NoError(t, err)
+	updated := next().(events.MangaUpdated)
+	assert.Equal(t, 699, updated.PreviousChapters)
+	assert.Equal(t, 700, updated.Manga.TotalChapters)
+
+	require.NoError(t, s.Delete(admin, "naruto"))
+	assert.Equal(t, "naruto", next().(events.MangaDeleted).MangaID)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/pkg/database"
+	"mangahub/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// ProgressStore is the storage ProgressService needs, implemented by
+// progress.ProgressRepository
+type ProgressStore interface {
+	UpdateProgress(ctx context.Context, progress models.UserProgress) error
+	GetUserProgress(ctx context.Context, userID string) ([]models.UserProgress, error)
+	GetMangaProgress(ctx context.Context, userID, mangaID string) (models.UserProgress, error)
+}
+
+// ProgressService records how far users have read. Every method acts on the
+// caller unless an admin or service account names another user; see
+// ActingUser.
+type ProgressService struct {
+	Store ProgressStore
+	// Events receives a ProgressUpdated for every update
+	Events *events.Bus
+	// Logger logs unexpected errors; nil uses the default logger
+	Logger *slog.Logger
+}
+
+// ValidateChapter checks chapter is a chapter number
+func ValidateChapter(chapter int) error {
+	if chapter < 1 {
+		return invalid(field("chapter", "chapter must be at least 1"))
+	}
+	return nil
+}
+
+// Update records the chapter a user has read up to in a manga
+func (s *ProgressService) Update(ctx context.Context, userID, mangaID string, chapter int) (models.UserProgress, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return models.UserProgress{}, err
+	}
+	if mangaID == "" {
+		return models.UserProgress{}, invalid(field("manga_id", "manga_id is required"))
+	}
+	if err := ValidateChapter(chapter); err != nil {
+		return models.UserProgress{}, err
+	}
+
+	progress := models.UserProgress{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		MangaID: mangaID,
+		Chapter: chapter,
+	}
+	err = s.Store.UpdateProgress(ctx, progress)
+	if errors.Is(err, database.ErrReference) {
+		return models.UserProgress{}, errorf(NotFound, "manga not found")
+	}
+	if err != nil {
+		return models.UserProgress{}, internal(ctx, s.Logger, "failed to update progress", err)
+	}
+	s.Events.Publish(events.ProgressUpdated{
+		Trace:     events.TraceOf(ctx),
+		UserID:    userID,
+		MangaID:   mangaID,
+		Chapter:   chapter,
+		Timestamp: time.Now(),
+	})
+
+	return progress, nil
+}
+
+// List returns a user's progress in every manga
+func (s *ProgressService) List(ctx context.Context, userID string) ([]models.UserProgress, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.Store.GetUserProgress(ctx, userID)
+	if err != nil {
+		return nil, internal(ctx, s.Logger, "failed to fetch progress", err)
+	}
+	return progress, nil
+}
+
+// Get returns a user's progress in one manga
+func (s *ProgressService) Get(ctx context.Context, userID, mangaID string) (models.UserProgress, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return models.UserProgress{}, err
+	}
+	if mangaID == "" {
+		return models.UserProgress{}, invalid(field("manga_id", "manga_id is required"))
+	}
+
+	progress, err := s.Store.GetMangaProgress(ctx, userID, mangaID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.UserProgress{}, errorf(NotFound, "progress not found")
+	}
+	if err != nil {
+		return models.UserProgress{}, internal(ctx, s.Logger, "failed to fetch progress", err)
+	}
+	return progress, nil
+}
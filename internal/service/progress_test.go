@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"mangahub/internal/events"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressService_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		userID  string
+		mangaID string
+		chapter int
+		code    Code
+		owner   string
+	}{
+		{"own progress", as("alice", models.RoleUser), "", "berserk", 12, 0, "alice"},
+		{"service account for a user", as("sync", models.RoleService), "bob", "berserk", 3, 0, "bob"},
+		{"chapter zero", as("alice", models.RoleUser), "", "berserk", 0, Invalid, ""},
+		{"negative chapter", as("alice", models.RoleUser), "", "berserk", -4, Invalid, ""},
+		{"without manga", as("alice", models.RoleUser), "", "", 1, Invalid, ""},
+		{"unknown manga", as("alice", models.RoleUser), "", missingManga, 1, NotFound, ""},
+		{"anonymously", anonymous, "", "berserk", 1, Unauthenticated, ""},
+		{"for another user", as("alice", models.RoleUser), "bob", "berserk", 1, PermissionDenied, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus, next := recordEvents(t)
+			store := newProgressStore()
+			s := &ProgressService{Store: store, Events: bus}
+
+			progress, err := s.Update(tt.ctx, tt.userID, tt.mangaID, tt.chapter)
+			if tt.code != 0 {
+				assert.Equal(t, tt.code, CodeOf(err))
+				assert.Empty(t, store.progress, "nothing is stored")
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, progress.ID)
+			assert.Equal(t, tt.owner, progress.UserID)
+
+			event := next().(events.ProgressUpdated)
+			assert.Equal(t, tt.owner, event.UserID)
+			assert.Equal(t, tt.chapter, event.Chapter)
+		})
+	}
+}
+
+func TestProgressService_Get(t *testing.T) {
+	s := &ProgressService{Store: newProgressStore()}
+	alice := as("alice", models.RoleUser)
+
+	_, err := s.Get(alice, "", "berserk")
+	assert.Equal(t, NotFound, CodeOf(err))
+
+	_, err = s.Update(alice, "", "berserk", 5)
+	require.NoError(t, err)
+	progress, err := s.Get(alice, "", "berserk")
+	require.NoError(t, err)
+	assert.Equal(t, 5, progress.Chapter)
+
+	all, err := s.List(alice, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	_, err = s.List(as("bob", models.RoleUser), "alice")
+	assert.Equal(t, PermissionDenied, CodeOf(err))
+}
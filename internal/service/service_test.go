@@ -0,0 +1,336 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/events"
+	"mangahub/internal/logging"
+	"mangahub/internal/problem"
+	"mangahub/pkg/database"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// as returns a context carrying a caller, as the transports build it
+func as(userID, role string) context.Context {
+	return auth.WithUser(context.Background(), auth.Claims{UserID: userID, Username: userID, Role: role})
+}
+
+// anonymous is a context without a caller
+var anonymous = context.Background()
+
+// recordEvents returns a bus and a function waiting for the next event
+// published on it
+func recordEvents(t *testing.T) (*events.Bus, func() events.Event) {
+	bus := events.NewBus()
+	t.Cleanup(bus.Close)
+	published := make(chan events.Event, 16)
+	bus.Subscribe("test", func(e events.Event) { published <- e })
+
+	return bus, func() events.Event {
+		select {
+		case e := <-published:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("no event published")
+			return nil
+		}
+	}
+}
+
+func TestActingUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requested string
+		want      string
+		code      Code
+	}{
+		{"anonymous", anonymous, "", "", Unauthenticated},
+		{"caller by default", as("alice", models.RoleUser), "", "alice", 0},
+		{"caller by name", as("alice", models.RoleUser), "alice", "alice", 0},
+		{"user for another", as("alice", models.RoleUser), "bob", "", PermissionDenied},
+		{"moderator for another", as("alice", models.RoleModerator), "bob", "", PermissionDenied},
+		{"admin for another", as("root", models.RoleAdmin), "bob", "bob", 0},
+		{"service for another", as("sync", models.RoleService), "bob", "bob", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, err := ActingUser(tt.ctx, tt.requested)
+			if tt.code != 0 {
+				assert.Equal(t, tt.code, CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, userID)
+		})
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errorf(Invalid, "bad"), http.StatusBadRequest},
+		{errorf(NotFound, "gone"), http.StatusNotFound},
+		{errorf(Conflict, "taken"), http.StatusConflict},
+		{errorf(Unauthenticated, "who"), http.StatusUnauthorized},
+		{errorf(PermissionDenied, "no"), http.StatusForbidden},
+		{errorf(Internal, "oops"), http.StatusInternalServerError},
+		{sql.ErrConnDone, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, HTTPStatus(tt.err), tt.err.Error())
+	}
+}
+
+func TestError_Problem(t *testing.T) {
+	err := invalid(field("id", "id is required"), field("title", "title is required"))
+	p := problem.From(err)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, "invalid", p.Code)
+	assert.Equal(t, "id is required; title is required", p.Detail)
+	assert.Equal(t, []problem.FieldError{{Field: "id", Message: "id is required"}, {Field: "title", Message: "title is required"}}, p.Errors)
+
+	p = problem.From(errorf(NotFound, "manga not found"))
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "not_found", p.Code)
+	assert.Empty(t, p.Errors)
+}
+
+func TestInternal_LogsWithInjectedLogger(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	err := internal(ctx, logging.New(&logs, "text", nil), "failed to fetch manga", sql.ErrConnDone)
+
+	assert.Equal(t, Internal, CodeOf(err))
+	assert.NotContains(t, err.Error(), sql.ErrConnDone.Error(), "the cause is only logged")
+	assert.Contains(t, logs.String(), "failed to fetch manga")
+	assert.Contains(t, logs.String(), "request_id=req-1")
+}
+
+// missingManga is a manga the fake stores reject like a foreign key would
+const missingManga = "missing"
+
+var errForeignKey = errors.New("FOREIGN KEY constraint failed")
+
+// mangaStore keeps the catalog in memory
+type mangaStore struct {
+	manga map[string]models.Manga
+}
+
+func newMangaStore(mangas ...models.Manga) *mangaStore {
+	s := &mangaStore{manga: make(map[string]models.Manga)}
+	for _, m := range mangas {
+		s.manga[m.ID] = m
+	}
+	return s
+}
+
+func (s *mangaStore) GetAllManga(ctx context.Context) ([]models.Manga, error) {
+	mangas := make([]models.Manga, 0, len(s.manga))
+	for _, m := range s.manga {
+		mangas = append(mangas, m)
+	}
+	sort.Slice(mangas, func(i, j int) bool { return mangas[i].ID < mangas[j].ID })
+	return mangas, nil
+}
+
+func (s *mangaStore) GetMangaByID(ctx context.Context, id string) (models.Manga, error) {
+	m, ok := s.manga[id]
+	if !ok {
+		return models.Manga{}, sql.ErrNoRows
+	}
+	return m, nil
+}
+
+func (s *mangaStore) SearchManga(ctx context.Context, query string) ([]models.Manga, error) {
+	all, _ := s.GetAllManga(ctx)
+	var found []models.Manga
+	for _, m := range all {
+		if strings.Contains(strings.ToLower(m.Title+" "+m.Author), strings.ToLower(query)) {
+			found = append(found, m)
+		}
+	}
+	return found, nil
+}
+
+func (s *mangaStore) CreateManga(ctx context.Context, m models.Manga) error {
+	s.manga[m.ID] = m
+	return nil
+}
+
+func (s *mangaStore) UpdateManga(ctx context.Context, m models.Manga) error {
+	if _, ok := s.manga[m.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	s.manga[m.ID] = m
+	return nil
+}
+
+func (s *mangaStore) DeleteManga(ctx context.Context, id string) error {
+	if _, ok := s.manga[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.manga, id)
+	return nil
+}
+
+// libraryStore keeps libraries in memory, keyed by user and manga
+type libraryStore struct {
+	entries map[[2]string]models.UserLibrary
+}
+
+func newLibraryStore() *libraryStore {
+	return &libraryStore{entries: make(map[[2]string]models.UserLibrary)}
+}
+
+func (s *libraryStore) AddToLibrary(ctx context.Context, entry models.UserLibrary) error {
+	if entry.MangaID == missingManga {
+		return database.Translate(errForeignKey)
+	}
+	key := [2]string{entry.UserID, entry.MangaID}
+	if existing, ok := s.entries[key]; ok {
+		entry.ID = existing.ID
+	}
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *libraryStore) GetUserLibrary(ctx context.Context, userID string) ([]models.UserLibrary, error) {
+	var entries []models.UserLibrary
+	for key, entry := range s.entries {
+		if key[0] == userID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *libraryStore) GetLibraryEntry(ctx context.Context, userID, mangaID string) (models.UserLibrary, error) {
+	entry, ok := s.entries[[2]string{userID, mangaID}]
+	if !ok {
+		return models.UserLibrary{}, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (s *libraryStore) UpdateLibraryStatus(ctx context.Context, userID, mangaID, status string) error {
+	key := [2]string{userID, mangaID}
+	entry, ok := s.entries[key]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	entry.Status = status
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *libraryStore) RemoveFromLibrary(ctx context.Context, userID, mangaID string) error {
+	key := [2]string{userID, mangaID}
+	if _, ok := s.entries[key]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.entries, key)
+	return nil
+}
+
+// progressStore keeps progress in memory, keyed by user and manga
+type progressStore struct {
+	progress map[[2]string]models.UserProgress
+}
+
+func newProgressStore() *progressStore {
+	return &progressStore{progress: make(map[[2]string]models.UserProgress)}
+}
+
+func (s *progressStore) UpdateProgress(ctx context.Context, p models.UserProgress) error {
+	if p.MangaID == missingManga {
+		return database.Translate(errForeignKey)
+	}
+	s.progress[[2]string{p.UserID, p.MangaID}] = p
+	return nil
+}
+
+func (s *progressStore) GetUserProgress(ctx context.Context, userID string) ([]models.UserProgress, error) {
+	var progress []models.UserProgress
+	for key, p := range s.progress {
+		if key[0] == userID {
+			progress = append(progress, p)
+		}
+	}
+	return progress, nil
+}
+
+func (s *progressStore) GetMangaProgress(ctx context.Context, userID, mangaID string) (models.UserProgress, error) {
+	p, ok := s.progress[[2]string{userID, mangaID}]
+	if !ok {
+		return models.UserProgress{}, sql.ErrNoRows
+	}
+	return p, nil
+}
+
+// userStore keeps accounts in memory
+type userStore struct {
+	users map[string]models.User
+	mutex sync.Mutex
+}
+
+func newUserStore() *userStore {
+	return &userStore{users: make(map[string]models.User)}
+}
+
+func (s *userStore) CreateUser(ctx context.Context, u models.User) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.users[u.ID] = u
+	return nil
+}
+
+func (s *userStore) find(match func(models.User) bool) (models.User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if match(u) {
+			return u, nil
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
+
+func (s *userStore) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	return s.find(func(u models.User) bool { return u.Username == username })
+}
+
+func (s *userStore) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	return s.find(func(u models.User) bool { return u.Email == email })
+}
+
+func (s *userStore) GetUserByID(ctx context.Context, id string) (models.User, error) {
+	return s.find(func(u models.User) bool { return u.ID == id })
+}
+
+func (s *userStore) UpdatePassword(ctx context.Context, id, hash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.PasswordHash = hash
+	s.users[id] = u
+	return nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/problem"
+	"mangahub/pkg/database"
+	"mangahub/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// MinPasswordLength is the shortest password an account can be registered
+// with
+const MinPasswordLength = 6
+
+// UserStore is the storage UserService needs, implemented by
+// user.UserRepository
+type UserStore interface {
+	CreateUser(ctx context.Context, user models.User) error
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetUserByID(ctx context.Context, id string) (models.User, error)
+	UpdatePassword(ctx context.Context, id string, newHash string) error
+}
+
+// UserService registers users, checks their credentials and issues tokens
+type UserService struct {
+	Store UserStore
+	// Logger logs unexpected errors; nil uses the default logger
+	Logger *slog.Logger
+}
+
+// Registration is a new account. Email is optional.
+type Registration struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// Credentials identify a user by username, or by email when Username is
+// empty
+type Credentials struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// Session is a token issued to a user, with the claims it carries
+type Session struct {
+	Token  string
+	Claims auth.Claims
+}
+
+// ValidatePassword checks a password is long enough to register with
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return invalid(field("password", "password must be at least %d characters", MinPasswordLength))
+	}
+	return nil
+}
+
+// ValidatePasswordStrength enforces the stronger policy for changed
+// passwords: at least 8 characters with upper and lower case letters and
+// digits
+func ValidatePasswordStrength(password string) error {
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range password {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		}
+	}
+	if len(password) < 8 || !hasUpper || !hasLower || !hasDigit {
+		return invalid(field("new_password", "password must be at least 8 characters with mixed case and numbers"))
+	}
+	return nil
+}
+
+// Register creates an account with the user role
+func (s *UserService) Register(ctx context.Context, r Registration) (models.User, error) {
+	var fields []problem.FieldError
+	if r.Username == "" {
+		fields = append(fields, field("username", "username is required"))
+	}
+	if r.Password == "" {
+		fields = append(fields, field("password", "password is required"))
+	}
+	if len(fields) > 0 {
+		return models.User{}, invalid(fields...)
+	}
+	if err := ValidatePassword(r.Password); err != nil {
+		return models.User{}, err
+	}
+	if _, err := s.Store.GetUserByUsername(ctx, r.Username); err == nil {
+		return models.User{}, errorf(Conflict, "username already exists")
+	}
+	if r.Email != "" {
+		if _, err := s.Store.GetUserByEmail(ctx, r.Email); err == nil {
+			return models.User{}, errorf(Conflict, "email already registered")
+		}
+	}
+
+	hash, err := auth.HashPassword(r.Password)
+	if err != nil {
+		return models.User{}, internal(ctx, s.Logger, "failed to hash password", err)
+	}
+	u := models.User{
+		ID:           uuid.New().String(),
+		Username:     r.Username,
+		Email:        r.Email,
+		Role:         models.RoleUser,
+		PasswordHash: hash,
+	}
+	err = s.Store.CreateUser(ctx, u)
+	if errors.Is(err, database.ErrDuplicate) {
+		// registered since the checks above
+		return models.User{}, errorf(Conflict, "username or email already taken")
+	}
+	if err != nil {
+		return models.User{}, internal(ctx, s.Logger, "failed to create user", err)
+	}
+	return u, nil
+}
+
+// Login checks credentials and issues a token
+func (s *UserService) Login(ctx context.Context, c Credentials) (Session, error) {
+	var fields []problem.FieldError
+	if c.Username == "" && c.Email == "" {
+		fields = append(fields, field("username", "username or email is required"))
+	}
+	if c.Password == "" {
+		fields = append(fields, field("password", "password is required"))
+	}
+	if len(fields) > 0 {
+		return Session{}, invalid(fields...)
+	}
+
+	var u models.User
+	var err error
+	if c.Username != "" {
+		u, err = s.Store.GetUserByUsername(ctx, c.Username)
+	} else {
+		u, err = s.Store.GetUserByEmail(ctx, c.Email)
+	}
+	if err != nil || auth.CheckPassword(u.PasswordHash, c.Password) != nil {
+		return Session{}, errorf(Unauthenticated, "invalid credentials")
+	}
+	return s.StartSession(ctx, u)
+}
+
+// Refresh exchanges a valid token for a new one. The user is looked up
+// again so role changes take effect.
+func (s *UserService) Refresh(ctx context.Context, token string) (Session, error) {
+	if token == "" {
+		return Session{}, invalid(field("token", "token is required"))
+	}
+	claims, err := auth.ParseTokenClaims(token)
+	if err != nil {
+		return Session{}, errorf(Unauthenticated, "invalid or expired token")
+	}
+	u, err := s.Store.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return Session{}, errorf(Unauthenticated, "user no longer exists")
+	}
+	return s.StartSession(ctx, u)
+}
+
+// Profile returns a user; see ActingUser for whose
+func (s *UserService) Profile(ctx context.Context, userID string) (models.User, error) {
+	userID, err := ActingUser(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	u, err := s.Store.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, errorf(NotFound, "user not found")
+	}
+	if err != nil {
+		return models.User{}, internal(ctx, s.Logger, "failed to fetch user", err)
+	}
+	return u, nil
+}
+
+// ChangePassword replaces the caller's password after checking the current
+// one
+func (s *UserService) ChangePassword(ctx context.Context, current, password string) error {
+	claims, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ValidatePasswordStrength(password); err != nil {
+		return err
+	}
+
+	u, err := s.Store.GetUserByID(ctx, claims.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorf(NotFound, "user not found")
+	}
+	if err != nil {
+		return internal(ctx, s.Logger, "failed to fetch user", err)
+	}
+	if auth.CheckPassword(u.PasswordHash, current) != nil {
+		return errorf(PermissionDenied, "invalid current password")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return internal(ctx, s.Logger, "failed to hash password", err)
+	}
+	if err := s.Store.UpdatePassword(ctx, u.ID, hash); err != nil {
+		return internal(ctx, s.Logger, "failed to update password", err)
+	}
+	return nil
+}
+
+// StartSession issues a token for a user
+func (s *UserService) StartSession(ctx context.Context, u models.User) (Session, error) {
+	token, err := auth.GenerateToken(u)
+	if err != nil {
+		return Session{}, internal(ctx, s.Logger, "failed to generate token", err)
+	}
+	claims, err := auth.ParseTokenClaims(token)
+	if err != nil {
+		return Session{}, internal(ctx, s.Logger, "failed to generate token", err)
+	}
+	return Session{Token: token, Claims: claims}, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+
+	"mangahub/internal/auth"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		password string
+		valid    bool
+		strong   bool
+	}{
+		{"", false, false},
+		{"short", false, false},
+		{"secret", true, false},
+		{"password1", true, false},
+		{"PASSWORD1", true, false},
+		{"Password", true, false},
+		{"Pass1", false, false},
+		{"Password1", true, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, ValidatePassword(tt.password) == nil, tt.password)
+		assert.Equal(t, tt.strong, ValidatePasswordStrength(tt.password) == nil, tt.password)
+	}
+}
+
+func TestUserService_Register(t *testing.T) {
+	s := &UserService{Store: newUserStore()}
+	_, err := s.Register(anonymous, Registration{Username: "alice", Email: "alice@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		r    Registration
+		code Code
+	}{
+		{"missing username", Registration{Password: "secret"}, Invalid},
+		{"missing password", Registration{Username: "bob"}, Invalid},
+		{"short password", Registration{Username: "bob", Password: "abc"}, Invalid},
+		{"taken username", Registration{Username: "alice", Password: "secret"}, Conflict},
+		{"taken email", Registration{Username: "bob", Email: "alice@example.com", Password: "secret"}, Conflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Register(anonymous, tt.r)
+			assert.Equal(t, tt.code, CodeOf(err))
+		})
+	}
+}
+
+func TestUserService_Login(t *testing.T) {
+	s := &UserService{Store: newUserStore()}
+	registered, err := s.Register(anonymous, Registration{Username: "alice", Email: "alice@example.com", Password: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleUser, registered.Role)
+
+	tests := []struct {
+		name string
+		c    Credentials
+		code Code
+	}{
+		{"by username", Credentials{Username: "alice", Password: "secret"}, 0},
+		{"by email", Credentials{Email: "alice@example.com", Password: "secret"}, 0},
+		{"wrong password", Credentials{Username: "alice", Password: "guess"}, Unauthenticated},
+		{"unknown user", Credentials{Username: "bob", Password: "secret"}, Unauthenticated},
+		{"no password", Credentials{Username: "alice"}, Invalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := s.Login(anonymous, tt.c)
+			if tt.code != 0 {
+				assert.Equal(t, tt.code, CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, registered.ID, session.Claims.UserID)
+			assert.False(t, session.Claims.ExpiresAt.IsZero())
+
+			refreshed, err := s.Refresh(anonymous, session.Token)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", refreshed.Claims.Username)
+		})
+	}
+
+	_, err = s.Refresh(anonymous, "not-a-token")
+	assert.Equal(t, Unauthenticated, CodeOf(err))
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	s := &UserService{Store: newUserStore()}
+	u, err := s.Register(anonymous, Registration{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	alice := auth.WithUser(anonymous, auth.Claims{UserID: u.ID, Role: models.RoleUser})
+
+	assert.Equal(t, Unauthenticated, CodeOf(s.ChangePassword(anonymous, "secret", "Password1")))
+	assert.Equal(t, Invalid, CodeOf(s.ChangePassword(alice, "secret", "weak")))
+	assert.Equal(t, PermissionDenied, CodeOf(s.ChangePassword(alice, "wrong", "Password1")))
+	require.NoError(t, s.ChangePassword(alice, "secret", "Password1"))
+
+	_, err = s.Login(anonymous, Credentials{Username: "alice", Password: "Password1"})
+	assert.NoError(t, err)
+
+	profile, err := s.Profile(alice, "")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", profile.Username)
+	_, err = s.Profile(as("root", models.RoleAdmin), "nobody")
+	assert.Equal(t, NotFound, CodeOf(err))
+}
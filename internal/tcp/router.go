@@ -0,0 +1,280 @@
+package tcp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/internal/logging"
+	"mangahub/internal/presence"
+)
+
+// Session is a client connection routed by a Router, whatever transport it
+// arrived on
+type Session struct {
+	ID        string
+	Transport string
+	UserID    string
+	LastSeen  time.Time
+
+	send  func(Message) error
+	close func()
+}
+
+// NewSession creates a session that delivers messages with send and is
+// dropped with close when delivery fails
+func NewSession(id, transport string, send func(Message) error, close func()) *Session {
+	return &Session{
+		ID:        id,
+		Transport: transport,
+		LastSeen:  time.Now(),
+		send:      send,
+		close:     close,
+	}
+}
+
+// Send delivers a message to the session's client
+func (s *Session) Send(msg Message) error {
+	return s.send(msg)
+}
+
+// Close disconnects the session's client
+func (s *Session) Close() {
+	s.close()
+}
+
+// Router implements the sync protocol shared by the TCP server and the gRPC
+// SyncService: registration, progress relays, pings and domain events are
+// handled the same way for every session, so clients on either transport
+// see each other's messages.
+type Router struct {
+	// Presence is told which users are connected; may be nil
+	Presence *presence.Tracker
+	// Logger logs failed sends and event broadcasts; nil uses the default
+	// logger
+	Logger *slog.Logger
+
+	sessions map[string]*Session
+	mutex    sync.RWMutex
+}
+
+// NewRouter creates a router without sessions
+func NewRouter() *Router {
+	return &Router{sessions: make(map[string]*Session)}
+}
+
+// Add starts routing messages to a session
+func (r *Router) Add(session *Session) {
+	r.mutex.Lock()
+	r.sessions[session.ID] = session
+	r.mutex.Unlock()
+}
+
+// Remove stops routing messages to a session. Removing a session twice is a
+// no-op.
+func (r *Router) Remove(session *Session) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, registered := r.sessions[session.ID]; registered {
+		delete(r.sessions, session.ID)
+		r.Presence.Disconnect(session.UserID, session.Transport)
+	}
+}
+
+// CloseAll closes and removes the sessions of a transport
+func (r *Router) CloseAll(transport string) {
+	r.mutex.Lock()
+	var closing []*Session
+	for id, session := range r.sessions {
+		if session.Transport == transport {
+			delete(r.sessions, id)
+			r.Presence.Disconnect(session.UserID, session.Transport)
+			closing = append(closing, session)
+		}
+	}
+	r.mutex.Unlock()
+
+	for _, session := range closing {
+		session.Close()
+	}
+}
+
+// Handle processes a message received from a session and returns the reply.
+// The user ID of a register message must be one the transport authenticated:
+// it becomes the session's identity in presence and for per-user events.
+func (r *Router) Handle(session *Session, msg Message) Message {
+	session.LastSeen = time.Now()
+	msg.Timestamp = time.Now().Format(time.RFC3339)
+
+	switch msg.Type {
+	case "register":
+		if msg.UserID == "" {
+			return Message{
+				Type:      "error",
+				Data:      "Register requires an authenticated user",
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+		}
+		r.mutex.Lock()
+		if _, registered := r.sessions[session.ID]; registered && session.UserID != msg.UserID {
+			r.Presence.Disconnect(session.UserID, session.Transport)
+			r.Presence.Connect(msg.UserID, session.Transport)
+		}
+		session.UserID = msg.UserID
+		r.mutex.Unlock()
+		return Message{
+			Type:      "registered",
+			UserID:    msg.UserID,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+
+	case "progress_update":
+		if session.UserID == "" {
+			return Message{
+				Type:      "error",
+				Data:      "Register before sending progress updates",
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+		}
+		// Relay the progress update to the user's other sessions, whatever
+		// user ID the client claimed. Reading activity is private: other
+		// users see it through presence, which honors visibility.
+		msg.UserID = session.UserID
+		r.sendMessage(context.Background(), msg, func(s *Session) bool {
+			return s.UserID == session.UserID && s.ID != session.ID
+		})
+		return Message{
+			Type:      "progress_ack",
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+
+	case "ping":
+		return Message{
+			Type:      "pong",
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+	}
+
+	return Message{
+		Type:      "error",
+		Data:      "Unknown message type",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// Broadcast sends a message to every session except excludeID
+func (r *Router) Broadcast(msg Message, excludeID string) {
+	r.broadcast(context.Background(), msg, excludeID)
+}
+
+// SendToUser sends a message to the sessions registered as userID
+func (r *Router) SendToUser(msg Message, userID string) {
+	r.sendToUser(context.Background(), msg, userID)
+}
+
+func (r *Router) broadcast(ctx context.Context, msg Message, excludeID string) int {
+	return r.sendMessage(ctx, msg, func(session *Session) bool { return session.ID != excludeID })
+}
+
+func (r *Router) sendToUser(ctx context.Context, msg Message, userID string) int {
+	return r.sendMessage(ctx, msg, func(session *Session) bool { return session.UserID == userID })
+}
+
+// sendMessage sends a message to every session for which match returns
+// true, dropping sessions that fail to take it, and returns how many took
+// it. Failures are logged with ctx.
+func (r *Router) sendMessage(ctx context.Context, msg Message, match func(*Session) bool) int {
+	r.mutex.RLock()
+	sessions := make([]*Session, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		if match(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	r.mutex.RUnlock()
+
+	sent := 0
+	for _, session := range sessions {
+		if err := session.Send(msg); err != nil {
+			logging.Or(r.Logger).WarnContext(ctx, "Dropping sync client after failed send",
+				"transport", session.Transport, "session_id", session.ID, "type", msg.Type, "error", err)
+			r.Remove(session)
+			session.Close()
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// HandleEvent forwards domain events to sessions. Catalog changes go to
+// everyone; progress and library changes only to the user's own sessions.
+// Subscribe it to an events.Bus. Each forward is logged with the ID of the
+// request that caused the event.
+func (r *Router) HandleEvent(event events.Event) {
+	ctx := events.Context(event)
+	var msg Message
+	var sent int
+	switch e := event.(type) {
+	case events.ProgressUpdated:
+		msg = Message{
+			Type:      "progress_broadcast",
+			UserID:    e.UserID,
+			MangaID:   e.MangaID,
+			Chapter:   e.Chapter,
+			Timestamp: e.Timestamp.Format(time.RFC3339),
+		}
+		sent = r.sendToUser(ctx, msg, e.UserID)
+	case events.LibraryUpdated:
+		msg = Message{
+			Type:      "library_update",
+			UserID:    e.UserID,
+			MangaID:   e.MangaID,
+			Data:      map[string]string{"action": e.Action, "status": e.Status},
+			Timestamp: e.Timestamp.Format(time.RFC3339),
+		}
+		sent = r.sendToUser(ctx, msg, e.UserID)
+	case events.MangaCreated:
+		msg = Message{
+			Type:      "new_manga",
+			MangaID:   e.Manga.ID,
+			Data:      map[string]string{"title": e.Manga.Title},
+			Timestamp: e.Timestamp.Format(time.RFC3339),
+		}
+		sent = r.broadcast(ctx, msg, "")
+	case events.MangaUpdated:
+		msg = Message{
+			Type:      "manga_updated",
+			MangaID:   e.Manga.ID,
+			Chapter:   e.Manga.TotalChapters,
+			Data:      map[string]string{"title": e.Manga.Title},
+			Timestamp: e.Timestamp.Format(time.RFC3339),
+		}
+		sent = r.broadcast(ctx, msg, "")
+	case events.MangaDeleted:
+		msg = Message{
+			Type:      "manga_deleted",
+			MangaID:   e.MangaID,
+			Timestamp: e.Timestamp.Format(time.RFC3339),
+		}
+		sent = r.broadcast(ctx, msg, "")
+	default:
+		return
+	}
+	logging.Or(r.Logger).InfoContext(ctx, "Sync event broadcast", "type", msg.Type, "sessions", sent)
+}
+
+// Count returns the number of sessions of a transport
+func (r *Router) Count(transport string) int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	n := 0
+	for _, session := range r.sessions {
+		if session.Transport == transport {
+			n++
+		}
+	}
+	return n
+}
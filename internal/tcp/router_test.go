@@ -0,0 +1,93 @@
+package tcp
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/internal/presence"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_RegisterTracksAuthenticatedPresence(t *testing.T) {
+	router := NewRouter()
+	router.Presence = presence.NewTracker(nil, nil)
+	session := NewSession("s1", presence.TransportTCP, func(Message) error { return nil }, func() {})
+	router.Add(session)
+
+	reply := router.Handle(session, Message{Type: "register"})
+	assert.Equal(t, "error", reply.Type, "sessions without an identity stay anonymous")
+	assert.Empty(t, session.UserID)
+
+	reply = router.Handle(session, Message{Type: "register", UserID: "alice"})
+	assert.Equal(t, "registered", reply.Type)
+	assert.True(t, router.Presence.Status("alice").Online)
+
+	router.Handle(session, Message{Type: "register", UserID: "bob"})
+	assert.False(t, router.Presence.Status("alice").Online, "re-registering moves the connection to the new identity")
+	assert.True(t, router.Presence.Status("bob").Online)
+}
+
+func TestRouter_RelaysProgressAsSessionUser(t *testing.T) {
+	router := NewRouter()
+	received := make(chan Message, 10)
+	sender := NewSession("s1", presence.TransportTCP, func(Message) error { return nil }, func() {})
+	device := NewSession("s2", presence.TransportTCP, func(msg Message) error {
+		received <- msg
+		return nil
+	}, func() {})
+	router.Add(sender)
+	router.Add(device)
+	router.Handle(device, Message{Type: "register", UserID: "bob"})
+
+	reply := router.Handle(sender, Message{Type: "progress_update", UserID: "bob", MangaID: "naruto", Chapter: 3})
+	assert.Equal(t, "error", reply.Type, "unregistered sessions cannot relay progress")
+	assert.Empty(t, received)
+
+	router.Handle(sender, Message{Type: "register", UserID: "bob"})
+	reply = router.Handle(sender, Message{Type: "progress_update", UserID: "alice", MangaID: "naruto", Chapter: 3})
+	assert.Equal(t, "progress_ack", reply.Type)
+	relayed := <-received
+	assert.Equal(t, "bob", relayed.UserID, "the claimed user ID is replaced by the session's")
+	assert.Equal(t, 3, relayed.Chapter)
+}
+
+// hiddenStore marks every user hidden
+type hiddenStore struct{}
+
+func (hiddenStore) GetVisibility(context.Context, string) (string, error) {
+	return models.VisibilityHidden, nil
+}
+func (hiddenStore) GetFriendIDs(context.Context, string) ([]string, error) { return nil, nil }
+
+func TestRouter_KeepsProgressPrivate(t *testing.T) {
+	router := NewRouter()
+	router.Presence = presence.NewTracker(hiddenStore{}, nil)
+	sessions := map[string]chan Message{}
+	for _, user := range []string{"alice", "alice", "bob"} {
+		received := make(chan Message, 10)
+		session := NewSession(user+strconv.Itoa(len(sessions)), presence.TransportTCP, func(msg Message) error {
+			received <- msg
+			return nil
+		}, func() {})
+		router.Add(session)
+		router.Handle(session, Message{Type: "register", UserID: user})
+		sessions[session.ID] = received
+	}
+
+	// A hidden user's reading activity reaches only their own sessions,
+	// whether it comes from an event or from one of their clients
+	router.HandleEvent(events.ProgressUpdated{UserID: "alice", MangaID: "naruto", Chapter: 4, Timestamp: time.Now()})
+	assert.Equal(t, "progress_broadcast", (<-sessions["alice0"]).Type)
+	assert.Equal(t, "progress_broadcast", (<-sessions["alice1"]).Type)
+	assert.Empty(t, sessions["bob2"])
+
+	router.Handle(router.sessions["alice0"], Message{Type: "progress_update", MangaID: "naruto", Chapter: 5})
+	assert.Equal(t, 5, (<-sessions["alice1"]).Chapter)
+	assert.Empty(t, sessions["alice0"], "the sender does not get its own update back")
+	assert.Empty(t, sessions["bob2"])
+}
@@ -1,12 +1,20 @@
 package tcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
 	"net"
-	"sync"
+	"sync/atomic"
 	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/events"
+	"mangahub/internal/logging"
+	"mangahub/internal/presence"
+	"mangahub/internal/ratelimit"
 )
 
 // Message represents a JSON message protocol
@@ -17,22 +25,25 @@ type Message struct {
 	Chapter   int         `json:"chapter,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
 	Timestamp string      `json:"timestamp"`
-}
-
-// Client represents a TCP client connection
-type Client struct {
-	ID       string
-	Conn     net.Conn
-	UserID   string
-	LastSeen time.Time
+	// Token is the login token a TCP client registers with
+	Token string `json:"token,omitempty"`
 }
 
 // Server represents the TCP server
 type Server struct {
-	Address  string
-	clients  map[string]*Client
-	mutex    sync.RWMutex
+	Address string
+	// Router handles the messages of every connection. Share it with the
+	// gRPC SyncService so clients of both see the same messages.
+	Router *Router
+	// ConnectionLimiter limits how often each client IP may connect; nil
+	// accepts every connection
+	ConnectionLimiter *ratelimit.Limiter
+	// Logger logs the server and its connections; nil uses the default
+	// logger
+	Logger *slog.Logger
+
 	listener net.Listener
+	running  atomic.Bool
 	done     chan bool
 }
 
@@ -40,7 +51,7 @@ type Server struct {
 func NewServer(address string) *Server {
 	return &Server{
 		Address: address,
-		clients: make(map[string]*Client),
+		Router:  NewRouter(),
 		done:    make(chan bool),
 	}
 }
@@ -52,8 +63,9 @@ func (s *Server) Start() error {
 		return err
 	}
 	s.listener = listener
+	s.running.Store(true)
 
-	log.Printf("TCP Server listening on %s", s.Address)
+	logging.Or(s.Logger).Info("TCP server listening", "address", s.Address)
 
 	go s.acceptConnections()
 	return nil
@@ -61,22 +73,19 @@ func (s *Server) Start() error {
 
 // Stop stops the TCP server
 func (s *Server) Stop() {
+	s.running.Store(false)
 	close(s.done)
 	if s.listener != nil {
 		s.listener.Close()
 	}
 
-	s.mutex.Lock()
-	for _, client := range s.clients {
-		client.Conn.Close()
-	}
-	s.clients = make(map[string]*Client)
-	s.mutex.Unlock()
+	s.Router.CloseAll(presence.TransportTCP)
 
-	log.Println("TCP Server stopped")
+	logging.Or(s.Logger).Info("TCP server stopped")
 }
 
 func (s *Server) acceptConnections() {
+	logger := logging.Or(s.Logger)
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
@@ -84,162 +93,120 @@ func (s *Server) acceptConnections() {
 			case <-s.done:
 				return
 			default:
-				log.Printf("Error accepting connection: %v", err)
+				logger.Error("Failed to accept TCP connection", "error", err)
 				continue
 			}
 		}
 
-		clientID := conn.RemoteAddr().String()
-		client := &Client{
-			ID:       clientID,
-			Conn:     conn,
-			LastSeen: time.Now(),
+		// Every connection gets an ID correlating its log lines
+		ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+		host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+		if result := s.ConnectionLimiter.Allow(ctx, host); !result.Allowed {
+			logger.WarnContext(ctx, "Rejected TCP connection: too many connections", "remote_addr", conn.RemoteAddr().String())
+			conn.Close()
+			continue
 		}
 
-		s.mutex.Lock()
-		s.clients[clientID] = client
-		s.mutex.Unlock()
+		session := newConnSession(conn)
+		s.Router.Add(session)
 
-		log.Printf("New TCP client connected: %s", clientID)
+		logger.InfoContext(ctx, "TCP client connected", "session_id", session.ID)
 
-		go s.handleClient(client)
+		go s.handleClient(ctx, conn, session)
+	}
+}
+
+// newConnSession creates the session of a TCP connection, writing one JSON
+// message per line
+func newConnSession(conn net.Conn) *Session {
+	send := func(msg Message) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		_, err = conn.Write(append(data, '\n'))
+		return err
 	}
+	return NewSession(conn.RemoteAddr().String(), presence.TransportTCP, send, func() { conn.Close() })
 }
 
-func (s *Server) handleClient(client *Client) {
+func (s *Server) handleClient(ctx context.Context, conn net.Conn, session *Session) {
+	logger := logging.Or(s.Logger).With("session_id", session.ID)
 	defer func() {
-		s.mutex.Lock()
-		delete(s.clients, client.ID)
-		s.mutex.Unlock()
-		if err := client.Conn.Close(); err != nil {
-			log.Printf("Error closing connection for %s: %v", client.ID, err)
+		s.Router.Remove(session)
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			logger.ErrorContext(ctx, "Failed to close TCP connection", "error", err)
 		}
-		log.Printf("TCP client disconnected: %s", client.ID)
+		logger.InfoContext(ctx, "TCP client disconnected", "user_id", session.UserID)
 	}()
 
 	// Set connection deadline for read operations
-	client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	
-	decoder := json.NewDecoder(client.Conn)
-	encoder := json.NewEncoder(client.Conn)
+	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+
+	decoder := json.NewDecoder(conn)
 
 	for {
 		var msg Message
 		if err := decoder.Decode(&msg); err != nil {
 			// Check if it's a network error or timeout
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-				log.Printf("TCP client %s read timeout, disconnecting", client.ID)
+				logger.InfoContext(ctx, "TCP client read timeout, disconnecting")
 			} else if err == io.EOF {
-				log.Printf("TCP client %s closed connection", client.ID)
+				logger.DebugContext(ctx, "TCP client closed connection")
 			} else {
-				log.Printf("Error decoding message from %s: %v", client.ID, err)
+				logger.WarnContext(ctx, "Failed to decode TCP message", "error", err)
 			}
 			return
 		}
 
 		// Reset read deadline after successful read
-		client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-
-		client.LastSeen = time.Now()
-		msg.Timestamp = time.Now().Format(time.RFC3339)
-
-		// Handle different message types
-		switch msg.Type {
-		case "register":
-			client.UserID = msg.UserID
-			response := Message{
-				Type:      "registered",
-				UserID:    client.UserID,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			if err := encoder.Encode(response); err != nil {
-				log.Printf("Error encoding register response to %s: %v", client.ID, err)
-				return
-			}
-
-		case "progress_update":
-			// Broadcast progress update to all clients
-			s.broadcastMessage(msg, client.ID)
-			response := Message{
-				Type:      "progress_ack",
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			if err := encoder.Encode(response); err != nil {
-				log.Printf("Error encoding progress_ack to %s: %v", client.ID, err)
-				return
-			}
-
-		case "ping":
-			response := Message{
-				Type:      "pong",
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			if err := encoder.Encode(response); err != nil {
-				log.Printf("Error encoding pong to %s: %v", client.ID, err)
-				return
-			}
-
-		default:
-			response := Message{
-				Type:      "error",
-				Data:      "Unknown message type",
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			if err := encoder.Encode(response); err != nil {
-				log.Printf("Error encoding error response to %s: %v", client.ID, err)
-				return
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+
+		var response Message
+		if msg.Type == "register" {
+			// The identity comes from the token; claimed user IDs are ignored
+			userID, _, _, err := auth.ParseToken(msg.Token)
+			if err != nil || userID == "" {
+				logger.InfoContext(ctx, "TCP register rejected: invalid token")
+				response = Message{Type: "error", Data: "Invalid or missing token", Timestamp: time.Now().Format(time.RFC3339)}
+			} else {
+				msg.UserID = userID
+				msg.Token = ""
 			}
 		}
-	}
-}
-
-func (s *Server) broadcastMessage(msg Message, excludeID string) {
-	s.mutex.RLock()
-	clients := make([]*Client, 0, len(s.clients))
-	for id, client := range s.clients {
-		if id != excludeID {
-			clients = append(clients, client)
+		if response.Type == "" {
+			response = s.Router.Handle(session, msg)
 		}
-	}
-	s.mutex.RUnlock()
-
-	data, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
-		return
-	}
-
-	// Send to all clients with error handling
-	for _, client := range clients {
-		client.Conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-		_, err := client.Conn.Write(append(data, '\n'))
-		if err != nil {
-			log.Printf("Error sending message to client %s: %v", client.ID, err)
-			// Remove failed client
-			s.mutex.Lock()
-			delete(s.clients, client.ID)
-			s.mutex.Unlock()
-			client.Conn.Close()
+		if err := session.Send(response); err != nil {
+			logger.WarnContext(ctx, "Failed to send TCP reply", "type", response.Type, "error", err)
+			return
 		}
 	}
 }
 
-// BroadcastProgress broadcasts a progress update to all connected clients
+// BroadcastProgress sends a progress update to the user's connected clients
 func (s *Server) BroadcastProgress(userID, mangaID string, chapter int) {
-	msg := Message{
-		Type:      "progress_broadcast",
+	s.Router.HandleEvent(events.ProgressUpdated{
 		UserID:    userID,
 		MangaID:   mangaID,
 		Chapter:   chapter,
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
-	s.broadcastMessage(msg, "")
+		Timestamp: time.Now(),
+	})
+}
+
+// HandleEvent forwards domain events to connected clients through the
+// Router. Subscribe it to an events.Bus.
+func (s *Server) HandleEvent(event events.Event) {
+	s.Router.HandleEvent(event)
+}
+
+// Running reports whether the server is accepting connections
+func (s *Server) Running() bool {
+	return s.running.Load()
 }
 
 // GetClientCount returns the number of connected clients
 func (s *Server) GetClientCount() int {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return len(s.clients)
+	return s.Router.Count(presence.TransportTCP)
 }
-
@@ -0,0 +1,56 @@
+package tcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialTestServer(t *testing.T) (net.Conn, *bufio.Reader) {
+	s := NewServer("127.0.0.1:0")
+	require.NoError(t, s.Start())
+	t.Cleanup(s.Stop)
+
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, bufio.NewReader(conn)
+}
+
+func exchange(t *testing.T, conn net.Conn, reader *bufio.Reader, msg Message) Message {
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	_, err = conn.Write(append(data, '\n'))
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := reader.ReadBytes('\n')
+	require.NoError(t, err)
+	var reply Message
+	require.NoError(t, json.Unmarshal(line, &reply))
+	return reply
+}
+
+func TestServer_RegisterRequiresToken(t *testing.T) {
+	conn, reader := dialTestServer(t)
+
+	reply := exchange(t, conn, reader, Message{Type: "register", UserID: "alice"})
+	assert.Equal(t, "error", reply.Type, "a claimed user ID without a token is rejected")
+
+	reply = exchange(t, conn, reader, Message{Type: "register", UserID: "alice", Token: "forged"})
+	assert.Equal(t, "error", reply.Type)
+
+	token, err := auth.GenerateToken(models.User{ID: "bob", Username: "bob"})
+	require.NoError(t, err)
+	reply = exchange(t, conn, reader, Message{Type: "register", UserID: "alice", Token: token})
+	assert.Equal(t, "registered", reply.Type)
+	assert.Equal(t, "bob", reply.UserID, "the identity comes from the token")
+}
@@ -0,0 +1,224 @@
+package udp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// seenHistorySize bounds how many notification IDs a client remembers for
+// duplicate suppression.
+const seenHistorySize = 1024
+
+// Client is a UDP notification client. It ACKs every notification it
+// receives, drops retransmitted duplicates by notification ID and discards
+// notifications whose signature does not match its session key.
+type Client struct {
+	conn        *net.UDPConn
+	done        chan bool
+	sessionKey  []byte
+	reassembler *reassembler
+
+	mutex     sync.Mutex
+	seen      map[uint64]bool
+	seenOrder []uint64
+}
+
+// Dial connects a client to the UDP notification server
+func Dial(serverAddr string) (*Client, error) {
+	addr, err := net.ResolveUDPAddr("udp", serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn:        conn,
+		done:        make(chan bool),
+		seen:        make(map[uint64]bool),
+		reassembler: newReassembler(),
+	}, nil
+}
+
+// Register authenticates with a JWT and completes the challenge handshake.
+// It must be called before Listen.
+func (c *Client) Register(token string, topics ...string) error {
+	msg := map[string]interface{}{"type": "register", "token": token}
+	if len(topics) > 0 {
+		msg["topics"] = topics
+	}
+	if err := c.send(msg); err != nil {
+		return err
+	}
+
+	challenge, _, err := c.await("challenge")
+	if err != nil {
+		return err
+	}
+
+	// The session key is derived on both sides from an X25519 exchange
+	// bound to the registration; it is never sent
+	share := newKeyShare()
+	msg["cookie"] = challenge.Message
+	msg["key_share"] = hex.EncodeToString(share.PublicKey().Bytes())
+	if err := c.send(msg); err != nil {
+		return err
+	}
+
+	registered, raw, err := c.await("registered")
+	if err != nil {
+		return err
+	}
+
+	challengeData, _ := challenge.Data.(map[string]interface{})
+	address, _ := challengeData["address"].(string)
+	data, _ := registered.Data.(map[string]interface{})
+	serverShare, _ := data["key_share"].(string)
+	key, err := deriveSessionKey(share, serverShare, token, challenge.Message, address)
+	if err != nil {
+		return errors.New("server did not return a valid key share")
+	}
+	if !VerifyNotification(raw, key) {
+		return errors.New("registration reply is not signed with the session key")
+	}
+	c.sessionKey = key
+	return nil
+}
+
+// await reads datagrams until a notification of the given type arrives,
+// returning it along with the datagram it was decoded from
+func (c *Client) await(msgType string) (Notification, []byte, error) {
+	buffer := make([]byte, 4096)
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	for {
+		n, err := c.conn.Read(buffer)
+		if err != nil {
+			return Notification{}, nil, fmt.Errorf("waiting for %s: %w", msgType, err)
+		}
+
+		var notification Notification
+		if err := json.Unmarshal(buffer[:n], &notification); err != nil {
+			continue
+		}
+		if notification.Type == "error" {
+			return Notification{}, nil, errors.New(notification.Message)
+		}
+		if notification.Type == msgType {
+			return notification, append([]byte(nil), buffer[:n]...), nil
+		}
+	}
+}
+
+// Subscribe adds topics to the client's subscriptions
+func (c *Client) Subscribe(topics ...string) error {
+	return c.send(map[string]interface{}{"type": "subscribe", "topics": topics})
+}
+
+// Unsubscribe removes topics from the client's subscriptions
+func (c *Client) Unsubscribe(topics ...string) error {
+	return c.send(map[string]interface{}{"type": "unsubscribe", "topics": topics})
+}
+
+// Heartbeat keeps the registration alive
+func (c *Client) Heartbeat() error {
+	return c.send(map[string]interface{}{"type": "heartbeat"})
+}
+
+// Listen reads notifications until Close is called, passing each new
+// notification to handler exactly once. Heartbeats are sent every 30 seconds.
+func (c *Client) Listen(handler func(Notification)) error {
+	go c.heartbeatLoop()
+
+	buffer := make([]byte, 65536)
+	for {
+		n, err := c.conn.Read(buffer)
+		if err != nil {
+			select {
+			case <-c.done:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		data, complete := c.reassembler.unwrap(buffer[:n])
+		if !complete {
+			continue
+		}
+
+		if c.sessionKey != nil && !VerifyNotification(data, c.sessionKey) {
+			continue
+		}
+
+		var notification Notification
+		if err := json.Unmarshal(data, &notification); err != nil {
+			continue
+		}
+
+		if c.accept(notification) {
+			handler(notification)
+		}
+	}
+}
+
+// accept ACKs a notification and reports whether it has not been seen before
+func (c *Client) accept(notification Notification) bool {
+	if notification.ID == 0 {
+		return true
+	}
+
+	c.send(map[string]interface{}{"type": "ack", "id": notification.ID})
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.seen[notification.ID] {
+		return false
+	}
+	c.seen[notification.ID] = true
+	c.seenOrder = append(c.seenOrder, notification.ID)
+	if len(c.seenOrder) > seenHistorySize {
+		delete(c.seen, c.seenOrder[0])
+		c.seenOrder = c.seenOrder[1:]
+	}
+	return true
+}
+
+func (c *Client) heartbeatLoop() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.Heartbeat()
+		}
+	}
+}
+
+func (c *Client) send(msg map[string]interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	_, err = c.conn.Write(data)
+	return err
+}
+
+// Close stops listening and closes the connection
+func (c *Client) Close() error {
+	close(c.done)
+	return c.conn.Close()
+}
@@ -0,0 +1,169 @@
+package udp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxDatagramSize is the largest datagram the server sends or accepts.
+	// It stays below the common 1280-1500 byte MTUs so datagrams are never
+	// split by IP fragmentation.
+	MaxDatagramSize = 1200
+	// MaxFragments is the most fragments a notification may be split into.
+	// Larger notifications are replaced by a pointer to fetch them over HTTP.
+	MaxFragments = 16
+	// fragmentChunkSize leaves room for the fragment header and the base64
+	// expansion of the payload.
+	fragmentChunkSize = (MaxDatagramSize - 128) * 3 / 4
+	// reassemblyTimeout is how long a client keeps an incomplete notification.
+	reassemblyTimeout = 30 * time.Second
+	// maxPartials bounds the incomplete notifications a client keeps.
+	maxPartials = 64
+)
+
+// Fragment carries one piece of a notification too large for one datagram.
+// The reassembled payload is the full (signed) notification.
+type Fragment struct {
+	Type    string `json:"type"`
+	ID      uint64 `json:"id"`
+	Index   int    `json:"index"`
+	Count   int    `json:"count"`
+	Payload []byte `json:"payload"`
+}
+
+// packets encodes a notification into the datagrams to send: a single one
+// when it fits, fragments when it needs up to MaxFragments, and otherwise a
+// compact notification without Data pointing at DetailsURL.
+func (s *Server) packets(notification Notification, key []byte) ([][]byte, error) {
+	data, err := s.encode(notification, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= MaxDatagramSize {
+		return [][]byte{data}, nil
+	}
+
+	count := (len(data) + fragmentChunkSize - 1) / fragmentChunkSize
+	if count <= MaxFragments && notification.ID != 0 {
+		return fragment(notification.ID, data)
+	}
+
+	compact := notification
+	compact.Data = nil
+	compact.Truncated = true
+	data, err = s.encode(compact, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxDatagramSize {
+		return nil, fmt.Errorf("notification %d too large even without data (%d bytes)", notification.ID, len(data))
+	}
+	return [][]byte{data}, nil
+}
+
+// fragment splits data into fragmentChunkSize pieces
+func fragment(id uint64, data []byte) ([][]byte, error) {
+	count := (len(data) + fragmentChunkSize - 1) / fragmentChunkSize
+	packets := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * fragmentChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		packet, err := json.Marshal(Fragment{
+			Type:    "fragment",
+			ID:      id,
+			Index:   i,
+			Count:   count,
+			Payload: data[i*fragmentChunkSize : end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+	return packets, nil
+}
+
+// partial is an incomplete fragmented notification
+type partial struct {
+	parts    [][]byte
+	received int
+	started  time.Time
+}
+
+// reassembler collects fragments until a notification is complete
+type reassembler struct {
+	partials map[uint64]*partial
+	mutex    sync.Mutex
+}
+
+func newReassembler() *reassembler {
+	return &reassembler{partials: make(map[uint64]*partial)}
+}
+
+// add stores a fragment and returns the full datagram once every fragment
+// has arrived. Retransmitted fragments are ignored.
+func (r *reassembler) add(f Fragment) ([]byte, bool) {
+	if f.Count <= 0 || f.Count > MaxFragments || f.Index < 0 || f.Index >= f.Count {
+		return nil, false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for id, p := range r.partials {
+		if now.Sub(p.started) > reassemblyTimeout {
+			delete(r.partials, id)
+		}
+	}
+
+	p, exists := r.partials[f.ID]
+	if !exists {
+		if len(r.partials) >= maxPartials {
+			return nil, false
+		}
+		p = &partial{parts: make([][]byte, f.Count), started: now}
+		r.partials[f.ID] = p
+	}
+	if len(p.parts) != f.Count || p.parts[f.Index] != nil {
+		return nil, false
+	}
+	p.parts[f.Index] = f.Payload
+	p.received++
+
+	if p.received < f.Count {
+		return nil, false
+	}
+
+	delete(r.partials, f.ID)
+	var data []byte
+	for _, part := range p.parts {
+		data = append(data, part...)
+	}
+	return data, true
+}
+
+// unwrap returns the complete notification datagram for raw, reassembling
+// fragments as needed; ok is false while a notification is incomplete.
+func (r *reassembler) unwrap(raw []byte) ([]byte, bool) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, false
+	}
+	if header.Type != "fragment" {
+		return raw, true
+	}
+
+	var f Fragment
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, false
+	}
+	return r.add(f)
+}
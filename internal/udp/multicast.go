@@ -0,0 +1,249 @@
+package udp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"mangahub/internal/logging"
+)
+
+const (
+	// DefaultGroupAddress is the administratively scoped multicast group used
+	// for LAN discovery and the public notification channel.
+	DefaultGroupAddress = "239.255.77.77:8083"
+	// announceInterval is how often the server announces itself.
+	announceInterval = 5 * time.Second
+)
+
+// Announcement is multicast periodically so clients can discover servers on
+// the local network without any configuration
+type Announcement struct {
+	Type      string            `json:"type"`
+	Server    string            `json:"server"`
+	Services  map[string]string `json:"services"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// DiscoveredServer is a server found by Discover
+type DiscoveredServer struct {
+	Address  string
+	Name     string
+	Services map[string]string
+	LastSeen time.Time
+}
+
+// startMulticast resolves the configured group and starts announcing. It is
+// a no-op when no broadcast target is configured.
+func (s *Server) startMulticast() error {
+	if s.broadcastIP == "" || s.broadcastPort == 0 {
+		return nil
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(s.broadcastIP, strconv.Itoa(s.broadcastPort)))
+	if err != nil {
+		return err
+	}
+	s.groupAddr = addr
+
+	logging.Or(s.Logger).Info("UDP server announcing", "group", addr.String())
+	go s.announceLoop()
+	return nil
+}
+
+func (s *Server) announceLoop() {
+	s.announce()
+
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.announce()
+		}
+	}
+}
+
+// announce multicasts the server's presence and service ports
+func (s *Server) announce() {
+	name, err := os.Hostname()
+	if err != nil {
+		name = "mangahub"
+	}
+
+	services := map[string]string{"udp": s.Address}
+	for service, address := range s.Services {
+		services[service] = address
+	}
+
+	data, err := json.Marshal(Announcement{
+		Type:      "announce",
+		Server:    name,
+		Services:  services,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		logging.Or(s.Logger).Error("Failed to encode UDP announcement", "error", err)
+		return
+	}
+	s.sendToGroup(context.Background(), data)
+}
+
+// multicastNotification publishes a notification on the group channel.
+// Private notifications (a user's own progress or library) are never
+// multicast.
+func (s *Server) multicastNotification(ctx context.Context, notification Notification) {
+	if s.groupAddr == nil {
+		return
+	}
+	for _, topic := range notification.Topics {
+		if privateTopic(topic) {
+			return
+		}
+	}
+
+	packets, err := s.packets(notification, nil)
+	if err != nil {
+		logging.Or(s.Logger).ErrorContext(ctx, "Failed to encode UDP multicast", "id", notification.ID, "error", err)
+		return
+	}
+	for _, packet := range packets {
+		s.sendToGroup(ctx, packet)
+	}
+	logging.Or(s.Logger).DebugContext(ctx, "UDP notification multicast", "id", notification.ID, "group", s.groupAddr.String())
+}
+
+func (s *Server) sendToGroup(ctx context.Context, data []byte) {
+	s.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	if _, err := s.conn.WriteToUDP(data, s.groupAddr); err != nil {
+		logging.Or(s.Logger).WarnContext(ctx, "Failed to send to UDP multicast group", "group", s.groupAddr.String(), "error", err)
+	}
+}
+
+// GroupListener receives announcements and notifications from a multicast
+// group without registering with any server
+type GroupListener struct {
+	conn        *net.UDPConn
+	done        chan bool
+	reassembler *reassembler
+}
+
+// JoinGroup joins the multicast group at groupAddr
+func JoinGroup(groupAddr string) (*GroupListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", groupAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GroupListener{conn: conn, done: make(chan bool), reassembler: newReassembler()}, nil
+}
+
+// Listen reads the group until Close is called. Announcements are passed to
+// onAnnounce and notifications to onNotification; either may be nil.
+func (g *GroupListener) Listen(onAnnounce func(Announcement, *net.UDPAddr), onNotification func(Notification)) error {
+	buffer := make([]byte, 65536)
+	for {
+		n, from, err := g.conn.ReadFromUDP(buffer)
+		if err != nil {
+			select {
+			case <-g.done:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		data, complete := g.reassembler.unwrap(buffer[:n])
+		if !complete {
+			continue
+		}
+
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &header); err != nil {
+			continue
+		}
+
+		if header.Type == "announce" {
+			var announcement Announcement
+			if err := json.Unmarshal(data, &announcement); err == nil && onAnnounce != nil {
+				onAnnounce(announcement, from)
+			}
+			continue
+		}
+
+		var notification Notification
+		if err := json.Unmarshal(data, &notification); err == nil && onNotification != nil {
+			onNotification(notification)
+		}
+	}
+}
+
+// Close leaves the group
+func (g *GroupListener) Close() error {
+	close(g.done)
+	return g.conn.Close()
+}
+
+// Discover listens on the group for the given duration and returns the
+// servers that announced themselves, keyed by their source address
+func Discover(groupAddr string, timeout time.Duration) ([]DiscoveredServer, error) {
+	group, err := JoinGroup(groupAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		announcement Announcement
+		from         *net.UDPAddr
+	}
+	results := make(chan result)
+	stop := make(chan bool)
+
+	go group.Listen(func(a Announcement, from *net.UDPAddr) {
+		select {
+		case results <- result{a, from}:
+		case <-stop:
+		}
+	}, nil)
+
+	found := make(map[string]*DiscoveredServer)
+	var order []string
+	deadline := time.After(timeout)
+	for {
+		select {
+		case r := <-results:
+			key := r.from.IP.String() + "/" + r.announcement.Server
+			server, exists := found[key]
+			if !exists {
+				server = &DiscoveredServer{Address: r.from.IP.String(), Name: r.announcement.Server}
+				found[key] = server
+				order = append(order, key)
+			}
+			server.Services = r.announcement.Services
+			server.LastSeen = time.Now()
+
+		case <-deadline:
+			close(stop)
+			group.Close()
+
+			servers := make([]DiscoveredServer, 0, len(order))
+			for _, key := range order {
+				servers = append(servers, *found[key])
+			}
+			return servers, nil
+		}
+	}
+}
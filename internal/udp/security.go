@@ -0,0 +1,199 @@
+package udp
+
+import (
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// cookieLifetime is how long a registration challenge stays valid.
+	cookieLifetime = 30 * time.Second
+	// inboundRate and inboundBurst limit datagrams per source IP.
+	inboundRate  = 20.0
+	inboundBurst = 40.0
+	// challengeRate and challengeBurst limit the registration challenges
+	// sent for each user, whatever address asks for them.
+	challengeRate  = 0.2
+	challengeBurst = 5.0
+)
+
+var errInvalidCookie = errors.New("invalid or expired cookie")
+
+// newCookie returns a challenge cookie binding the client address and user ID
+// to an expiry time. It is stateless: the server only needs its secret to
+// verify the cookie when it is echoed back.
+func (s *Server) newCookie(addr, userID string) string {
+	expiry := strconv.FormatInt(time.Now().Add(cookieLifetime).Unix(), 10)
+	return expiry + "." + s.mac("cookie", addr, userID, expiry)
+}
+
+// checkCookie verifies a cookie issued by newCookie for the same address and user
+func (s *Server) checkCookie(cookie, addr, userID string) error {
+	expiry, mac, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return errInvalidCookie
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().After(time.Unix(unix, 0)) {
+		return errInvalidCookie
+	}
+	if !hmac.Equal([]byte(mac), []byte(s.mac("cookie", addr, userID, expiry))) {
+		return errInvalidCookie
+	}
+	return nil
+}
+
+// errInvalidKeyShare rejects registrations without a usable X25519 public key
+var errInvalidKeyShare = errors.New("invalid key share")
+
+// newKeyShare returns a fresh X25519 key pair for one registration
+func newKeyShare() *ecdh.PrivateKey {
+	// crypto/rand never fails; it crashes the program instead
+	key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	return key
+}
+
+// deriveSessionKey computes the per-client key used to sign notifications.
+// Both sides compute it from an X25519 exchange, so the key itself never
+// travels, and bind it to the registration: the token, the cookie and the
+// client address the server saw.
+func deriveSessionKey(private *ecdh.PrivateKey, peerShare, token, cookie, addr string) ([]byte, error) {
+	raw, err := hex.DecodeString(peerShare)
+	if err != nil {
+		return nil, errInvalidKeyShare
+	}
+	peer, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, errInvalidKeyShare
+	}
+	shared, err := private.ECDH(peer)
+	if err != nil {
+		return nil, errInvalidKeyShare
+	}
+
+	tokenHash := sha256.Sum256([]byte(token))
+	h := hmac.New(sha256.New, shared)
+	h.Write([]byte(strings.Join([]string{"session", hex.EncodeToString(tokenHash[:]), cookie, addr}, "|")))
+	return h.Sum(nil), nil
+}
+
+func (s *Server) mac(parts ...string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// signNotification marshals a notification with an HMAC-SHA256 signature
+// computed over its JSON encoding without the signature field.
+func signNotification(notification Notification, key []byte) ([]byte, error) {
+	notification.Signature = ""
+	unsigned, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(unsigned)
+	notification.Signature = hex.EncodeToString(h.Sum(nil))
+	return json.Marshal(notification)
+}
+
+// VerifyNotification checks the signature of a raw notification datagram
+// against the session key received at registration.
+func VerifyNotification(data, key []byte) bool {
+	// Mirrors Notification field for field, but keeps Data raw so the
+	// re-encoded payload matches the signed bytes
+	var wire struct {
+		ID         uint64          `json:"id,omitempty"`
+		Type       string          `json:"type"`
+		Message    string          `json:"message"`
+		Topics     []string        `json:"topics,omitempty"`
+		UserID     string          `json:"user_id,omitempty"`
+		Data       json.RawMessage `json:"data,omitempty"`
+		DetailsURL string          `json:"details_url,omitempty"`
+		Truncated  bool            `json:"truncated,omitempty"`
+		Timestamp  string          `json:"timestamp"`
+		Signature  string          `json:"signature,omitempty"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil || wire.Signature == "" {
+		return false
+	}
+	signature, err := hex.DecodeString(wire.Signature)
+	if err != nil {
+		return false
+	}
+
+	wire.Signature = ""
+	unsigned, err := json.Marshal(wire)
+	if err != nil {
+		return false
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(unsigned)
+	return hmac.Equal(signature, h.Sum(nil))
+}
+
+// sourceBucket is a token bucket for one source IP
+type sourceBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// sourceLimiter rate limits what each source, such as an IP or a user, may
+// cause: rate per second with bursts of up to burst
+type sourceLimiter struct {
+	rate    float64
+	burst   float64
+	buckets map[string]*sourceBucket
+	mutex   sync.Mutex
+}
+
+func newSourceLimiter(rate, burst float64) *sourceLimiter {
+	return &sourceLimiter{rate: rate, burst: burst, buckets: make(map[string]*sourceBucket)}
+}
+
+// allow reports whether another datagram from ip may be processed
+func (l *sourceLimiter) allow(ip string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	bucket, exists := l.buckets[ip]
+	if !exists {
+		bucket = &sourceBucket{tokens: l.burst, last: now}
+		l.buckets[ip] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// cleanup forgets sources that have been idle long enough to refill
+func (l *sourceLimiter) cleanup() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	idle := time.Duration(l.burst / l.rate * float64(time.Second))
+	for ip, bucket := range l.buckets {
+		if time.Since(bucket.last) > idle {
+			delete(l.buckets, ip)
+		}
+	}
+}
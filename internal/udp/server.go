@@ -1,47 +1,189 @@
 package udp
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/events"
+	"mangahub/internal/logging"
+	"mangahub/internal/presence"
+)
+
+const (
+	// defaultAckTimeout is how long the server waits for the first ACK
+	// before retransmitting a notification. It doubles after every retry.
+	defaultAckTimeout = 1 * time.Second
+	// defaultMaxRetries is how many times a notification is retransmitted
+	// before it is considered expired.
+	defaultMaxRetries = 5
+	// retransmitInterval is how often pending notifications are checked.
+	retransmitInterval = 200 * time.Millisecond
 )
 
+// Notification topics clients can subscribe to. Genre and manga topics are
+// built with GenreTopic and MangaTopic.
+const (
+	TopicNewManga = "new_manga"
+	TopicProgress = "progress"
+	TopicLibrary  = "library"
+)
+
+// privateTopic reports whether notifications on topic belong to a single
+// user and must only reach that user's clients
+func privateTopic(topic string) bool {
+	return topic == TopicProgress || topic == TopicLibrary
+}
+
+// GenreTopic returns the topic for new manga in the given genre
+func GenreTopic(genre string) string {
+	return "genre:" + strings.ToLower(genre)
+}
+
+// MangaTopic returns the topic for chapter releases of the given manga
+func MangaTopic(mangaID string) string {
+	return "manga:" + mangaID
+}
+
+// validTopic reports whether a client may subscribe to topic
+func validTopic(topic string) bool {
+	switch {
+	case topic == TopicNewManga, topic == TopicProgress, topic == TopicLibrary:
+		return true
+	case strings.HasPrefix(topic, "genre:"):
+		return len(topic) > len("genre:")
+	case strings.HasPrefix(topic, "manga:"):
+		return len(topic) > len("manga:")
+	}
+	return false
+}
+
 // Notification represents a UDP notification message
 type Notification struct {
-	Type      string      `json:"type"`
-	Message   string      `json:"message"`
-	Data      interface{} `json:"data,omitempty"`
-	Timestamp string      `json:"timestamp"`
+	ID      uint64      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Message string      `json:"message"`
+	Topics  []string    `json:"topics,omitempty"`
+	UserID  string      `json:"user_id,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	// DetailsURL is the HTTP path where the full data can be fetched; when
+	// the notification is too large to send, Data is dropped and Truncated set
+	DetailsURL string `json:"details_url,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Timestamp  string `json:"timestamp"`
+	Signature  string `json:"signature,omitempty"`
+}
+
+// pendingNotification is a notification sent to a client that has not been
+// acknowledged yet.
+type pendingNotification struct {
+	packets     [][]byte
+	attempts    int
+	backoff     time.Duration
+	nextAttempt time.Time
 }
 
-// RegisteredClient represents a registered UDP client
+// RegisteredClient represents a registered UDP client. A client that never
+// subscribed (Topics is nil) receives every notification.
 type RegisteredClient struct {
 	Address  *net.UDPAddr
 	LastSeen time.Time
 	UserID   string
+	Topics   map[string]bool
+	key      []byte
+	pending  map[uint64]*pendingNotification
+}
+
+// wants reports whether the client should receive the notification
+func (c *RegisteredClient) wants(notification Notification) bool {
+	// Private notifications only go to the user they belong to, even when
+	// the client receives everything
+	for _, topic := range notification.Topics {
+		if privateTopic(topic) && c.UserID != notification.UserID {
+			return false
+		}
+	}
+	if len(notification.Topics) == 0 || c.Topics == nil {
+		return true
+	}
+	for _, topic := range notification.Topics {
+		if c.Topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats holds delivery counters for reliable notifications
+type Stats struct {
+	Delivered uint64 `json:"delivered"`
+	Retried   uint64 `json:"retried"`
+	Expired   uint64 `json:"expired"`
 }
 
-// Server represents the UDP broadcast server
+// Server represents the UDP broadcast server. Notifications go unicast to
+// registered clients and, when a broadcast target is configured, public ones
+// are also multicast to the group along with periodic announcements.
 type Server struct {
-	Address      string
-	clients      map[string]*RegisteredClient
-	mutex        sync.RWMutex
-	conn         *net.UDPConn
-	done         chan bool
-	broadcastIP  string
+	Address string
+	// Services lists the server's other endpoints (e.g. "http": ":8080")
+	// included in multicast announcements
+	Services map[string]string
+	// Presence is told which users are registered; may be nil
+	Presence *presence.Tracker
+	// Logger logs the server, its clients and broadcasts; nil uses the
+	// default logger
+	Logger *slog.Logger
+
+	clients       map[string]*RegisteredClient
+	mutex         sync.RWMutex
+	conn          *net.UDPConn
+	running       atomic.Bool
+	done          chan bool
+	broadcastIP   string
 	broadcastPort int
+	groupAddr     *net.UDPAddr
+
+	nextID     uint64
+	ackTimeout time.Duration
+	maxRetries int
+
+	secret  []byte
+	limiter *sourceLimiter
+	// challenges limits the registration challenges sent per user
+	challenges *sourceLimiter
+
+	delivered uint64
+	retried   uint64
+	expired   uint64
 }
 
 // NewServer creates a new UDP server
 func NewServer(address, broadcastIP string, broadcastPort int) *Server {
+	// rand.Read never fails; it crashes the program instead
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
 	return &Server{
 		Address:       address,
 		clients:       make(map[string]*RegisteredClient),
 		done:          make(chan bool),
 		broadcastIP:   broadcastIP,
 		broadcastPort: broadcastPort,
+		ackTimeout:    defaultAckTimeout,
+		maxRetries:    defaultMaxRetries,
+		secret:        secret,
+		limiter:       newSourceLimiter(inboundRate, inboundBurst),
+		challenges:    newSourceLimiter(challengeRate, challengeBurst),
 	}
 }
 
@@ -57,31 +199,42 @@ func (s *Server) Start() error {
 		return err
 	}
 	s.conn = conn
+	s.running.Store(true)
+
+	logging.Or(s.Logger).Info("UDP server listening", "address", s.Address)
 
-	log.Printf("UDP Server listening on %s", s.Address)
+	if err := s.startMulticast(); err != nil {
+		logging.Or(s.Logger).Warn("UDP multicast disabled", "error", err)
+	}
 
 	go s.handleMessages()
 	go s.cleanupInactiveClients()
+	go s.retransmitPending()
 
 	return nil
 }
 
 // Stop stops the UDP server
 func (s *Server) Stop() {
+	s.running.Store(false)
 	close(s.done)
 	if s.conn != nil {
 		s.conn.Close()
 	}
 
 	s.mutex.Lock()
+	for _, client := range s.clients {
+		s.Presence.Disconnect(client.UserID, presence.TransportUDP)
+	}
 	s.clients = make(map[string]*RegisteredClient)
 	s.mutex.Unlock()
 
-	log.Println("UDP Server stopped")
+	logging.Or(s.Logger).Info("UDP server stopped")
 }
 
 func (s *Server) handleMessages() {
-	buffer := make([]byte, 4096)
+	// One spare byte detects datagrams that would otherwise be truncated
+	buffer := make([]byte, MaxDatagramSize+1)
 
 	for {
 		select {
@@ -94,13 +247,21 @@ func (s *Server) handleMessages() {
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 					continue
 				}
-				log.Printf("Error reading UDP message: %v", err)
+				logging.Or(s.Logger).Error("Failed to read UDP message", "error", err)
+				continue
+			}
+
+			if !s.limiter.allow(clientAddr.IP.String()) {
+				continue
+			}
+			if n > MaxDatagramSize {
+				logging.Or(s.Logger).Warn("Dropping oversize UDP datagram", "remote_addr", clientAddr.String())
 				continue
 			}
 
 			var msg map[string]interface{}
 			if err := json.Unmarshal(buffer[:n], &msg); err != nil {
-				log.Printf("Error unmarshaling UDP message: %v", err)
+				logging.Or(s.Logger).Debug("Failed to decode UDP message", "remote_addr", clientAddr.String(), "error", err)
 				continue
 			}
 
@@ -119,22 +280,86 @@ func (s *Server) handleMessage(msg map[string]interface{}, clientAddr *net.UDPAd
 
 	switch msgType {
 	case "register":
-		userID, _ := msg["user_id"].(string)
-		s.mutex.Lock()
-		s.clients[clientKey] = &RegisteredClient{
+		// Registration is a two step handshake: the token is verified and a
+		// cookie bound to the source address is returned; only a register
+		// message echoing that cookie (proving the client receives packets at
+		// that address) adds the client. That message carries the client's
+		// X25519 key share and the reply carries ours, so both sides derive
+		// the session key without it ever being sent. Nothing is sent for invalid tokens,
+		// but anyone holding a valid token can still have challenges sent to
+		// a spoofed address, so challenges are rate limited per user to keep
+		// the server from being a useful reflector.
+		token, _ := msg["token"].(string)
+		userID, _, _, err := auth.ParseToken(token)
+		if err != nil || userID == "" {
+			logging.Or(s.Logger).Info("UDP register rejected: invalid token", "remote_addr", clientKey)
+			return
+		}
+
+		cookie, _ := msg["cookie"].(string)
+		if cookie == "" {
+			if !s.challenges.allow(userID) {
+				logging.Or(s.Logger).Info("UDP register challenge rate limited", "remote_addr", clientKey, "user_id", userID)
+				return
+			}
+			s.sendNotification(Notification{
+				Type:      "challenge",
+				Message:   s.newCookie(clientKey, userID),
+				Data:      map[string]string{"address": clientKey},
+				Timestamp: time.Now().Format(time.RFC3339),
+			}, clientAddr)
+			return
+		}
+		if err := s.checkCookie(cookie, clientKey, userID); err != nil {
+			logging.Or(s.Logger).Info("UDP register rejected", "remote_addr", clientKey, "error", err)
+			return
+		}
+
+		clientShare, _ := msg["key_share"].(string)
+		serverShare := newKeyShare()
+		key, err := deriveSessionKey(serverShare, clientShare, token, cookie, clientKey)
+		if err != nil {
+			logging.Or(s.Logger).Info("UDP register rejected", "remote_addr", clientKey, "error", err)
+			return
+		}
+
+		client := &RegisteredClient{
 			Address:  clientAddr,
 			LastSeen: time.Now(),
 			UserID:   userID,
+			key:      key,
+			pending:  make(map[uint64]*pendingNotification),
+		}
+		if _, ok := msg["topics"]; ok {
+			topics, err := parseTopics(msg["topics"])
+			if err != nil {
+				s.sendError(err.Error(), clientAddr)
+				return
+			}
+			client.Topics = make(map[string]bool, len(topics))
+			for _, topic := range topics {
+				client.Topics[topic] = true
+			}
+		}
+		s.mutex.Lock()
+		if previous, exists := s.clients[clientKey]; exists {
+			s.Presence.Disconnect(previous.UserID, presence.TransportUDP)
 		}
+		s.clients[clientKey] = client
+		s.Presence.Connect(userID, presence.TransportUDP)
 		s.mutex.Unlock()
 
+		// The reply carries our key share and, like everything after it, is
+		// signed with the session key so the client can check both sides
+		// derived the same one
 		response := Notification{
 			Type:      "registered",
 			Message:   "Successfully registered for notifications",
+			Data:      map[string]string{"key_share": hex.EncodeToString(serverShare.PublicKey().Bytes())},
 			Timestamp: time.Now().Format(time.RFC3339),
 		}
-		s.sendNotification(response, clientAddr)
-		log.Printf("UDP client registered: %s (UserID: %s)", clientKey, userID)
+		s.sendRaw(response, client.key, clientAddr)
+		logging.Or(s.Logger).Info("UDP client registered", "remote_addr", clientKey, "user_id", userID)
 
 	case "heartbeat":
 		s.mutex.Lock()
@@ -142,54 +367,181 @@ func (s *Server) handleMessage(msg map[string]interface{}, clientAddr *net.UDPAd
 			client.LastSeen = time.Now()
 		}
 		s.mutex.Unlock()
+
+	case "subscribe", "unsubscribe":
+		topics, err := parseTopics(msg["topics"])
+		if err != nil {
+			s.sendError(err.Error(), clientAddr)
+			return
+		}
+
+		s.mutex.Lock()
+		client, exists := s.clients[clientKey]
+		if !exists {
+			s.mutex.Unlock()
+			s.sendError("Client is not registered", clientAddr)
+			return
+		}
+		client.LastSeen = time.Now()
+		if client.Topics == nil {
+			client.Topics = make(map[string]bool)
+		}
+		for _, topic := range topics {
+			if msgType == "subscribe" {
+				client.Topics[topic] = true
+			} else {
+				delete(client.Topics, topic)
+			}
+		}
+		current := make([]string, 0, len(client.Topics))
+		for topic := range client.Topics {
+			current = append(current, topic)
+		}
+		s.mutex.Unlock()
+
+		response := Notification{
+			Type:      "subscriptions",
+			Message:   "Subscriptions updated",
+			Data:      map[string]interface{}{"topics": current},
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		s.sendNotification(response, clientAddr)
+
+	case "ack":
+		id, ok := msg["id"].(float64)
+		if !ok {
+			return
+		}
+		s.mutex.Lock()
+		if client, exists := s.clients[clientKey]; exists {
+			client.LastSeen = time.Now()
+			if _, pending := client.pending[uint64(id)]; pending {
+				delete(client.pending, uint64(id))
+				atomic.AddUint64(&s.delivered, 1)
+			}
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// parseTopics validates the topics list of a register or subscribe message
+func parseTopics(raw interface{}) ([]string, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("topics must be a list of strings")
+	}
+	topics := make([]string, 0, len(list))
+	for _, item := range list {
+		topic, ok := item.(string)
+		if !ok || !validTopic(topic) {
+			return nil, fmt.Errorf("invalid topic: %v", item)
+		}
+		if strings.HasPrefix(topic, "genre:") {
+			topic = strings.ToLower(topic)
+		}
+		topics = append(topics, topic)
 	}
+	return topics, nil
+}
+
+func (s *Server) sendError(message string, addr *net.UDPAddr) {
+	s.sendNotification(Notification{
+		Type:      "error",
+		Message:   message,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, addr)
 }
 
+// sendNotification sends a single notification, signed with the client's
+// session key when the address is registered
 func (s *Server) sendNotification(notification Notification, addr *net.UDPAddr) {
-	data, err := json.Marshal(notification)
+	var key []byte
+	s.mutex.RLock()
+	if client, exists := s.clients[addr.String()]; exists {
+		key = client.key
+	}
+	s.mutex.RUnlock()
+
+	s.sendRaw(notification, key, addr)
+}
+
+func (s *Server) sendRaw(notification Notification, key []byte, addr *net.UDPAddr) {
+	packets, err := s.packets(notification, key)
 	if err != nil {
-		log.Printf("Error marshaling notification: %v", err)
+		logging.Or(s.Logger).Error("Failed to encode UDP notification", "type", notification.Type, "error", err)
 		return
 	}
 
-	// Set write deadline for UDP send
-	s.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
-	_, err = s.conn.WriteToUDP(data, addr)
-	if err != nil {
+	if err := s.writePackets(packets, addr); err != nil {
 		// Check for network errors
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			log.Printf("UDP send timeout to %s: %v", addr.String(), err)
-		} else {
-			log.Printf("Error sending notification to %s: %v", addr.String(), err)
-		}
+		logging.Or(s.Logger).Warn("Failed to send UDP notification", "remote_addr", addr.String(), "type", notification.Type, "error", err)
 		// Remove client on persistent network failure
-		clientKey := addr.String()
-		s.mutex.Lock()
-		if _, exists := s.clients[clientKey]; exists {
-			delete(s.clients, clientKey)
-			log.Printf("Removed UDP client due to send failure: %s", clientKey)
+		s.removeClient(context.Background(), addr.String())
+	}
+}
+
+// writePackets sends the datagrams of one notification to addr
+func (s *Server) writePackets(packets [][]byte, addr *net.UDPAddr) error {
+	for _, packet := range packets {
+		// Set write deadline for UDP send
+		s.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+		if _, err := s.conn.WriteToUDP(packet, addr); err != nil {
+			return err
 		}
-		s.mutex.Unlock()
+	}
+	return nil
+}
+
+// encode marshals a notification, signing it if a key is given
+func (s *Server) encode(notification Notification, key []byte) ([]byte, error) {
+	if key == nil {
+		return json.Marshal(notification)
+	}
+	return signNotification(notification, key)
+}
+
+// removeClient drops a client and counts its unacknowledged notifications
+// as expired
+func (s *Server) removeClient(ctx context.Context, clientKey string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if client, exists := s.clients[clientKey]; exists {
+		atomic.AddUint64(&s.expired, uint64(len(client.pending)))
+		delete(s.clients, clientKey)
+		s.Presence.Disconnect(client.UserID, presence.TransportUDP)
+		logging.Or(s.Logger).InfoContext(ctx, "Removed UDP client after send failure", "remote_addr", clientKey, "user_id", client.UserID)
 	}
 }
 
-// Broadcast sends a notification to all registered clients
+// Broadcast sends a notification to all registered clients subscribed to
+// one of its topics. Each client is expected to ACK the notification ID;
+// unacknowledged notifications are retransmitted with exponential backoff
+// until they expire.
 func (s *Server) Broadcast(notification Notification) {
+	s.broadcast(context.Background(), notification)
+}
+
+// broadcast implements Broadcast, logging with ctx so the lines carry the
+// ID of the request that caused the notification
+func (s *Server) broadcast(ctx context.Context, notification Notification) {
+	logger := logging.Or(s.Logger)
+	if notification.ID == 0 {
+		notification.ID = atomic.AddUint64(&s.nextID, 1)
+	}
+
 	s.mutex.RLock()
 	clients := make([]*RegisteredClient, 0, len(s.clients))
 	for _, client := range s.clients {
-		clients = append(clients, client)
+		if client.wants(notification) {
+			clients = append(clients, client)
+		}
 	}
 	s.mutex.RUnlock()
 
-	if len(clients) == 0 {
-		log.Printf("No clients registered for UDP broadcast")
-		return
-	}
+	s.multicastNotification(ctx, notification)
 
-	data, err := json.Marshal(notification)
-	if err != nil {
-		log.Printf("Error marshaling broadcast notification: %v", err)
+	if len(clients) == 0 {
+		logger.DebugContext(ctx, "No subscribed clients for UDP broadcast", "id", notification.ID, "topics", notification.Topics)
 		return
 	}
 
@@ -197,42 +549,189 @@ func (s *Server) Broadcast(notification Notification) {
 	failedClients := make([]string, 0)
 
 	for _, client := range clients {
-		s.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
-		_, err := s.conn.WriteToUDP(data, client.Address)
+		packets, err := s.packets(notification, client.key)
 		if err != nil {
-			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-				log.Printf("UDP broadcast timeout to %s", client.Address.String())
-			} else {
-				log.Printf("Error broadcasting to %s: %v", client.Address.String(), err)
-			}
+			// Skip the client but keep it registered; the others may still
+			// get the notification
+			logger.ErrorContext(ctx, "Failed to encode UDP broadcast", "remote_addr", client.Address.String(), "id", notification.ID, "error", err)
+			continue
+		}
+
+		if err := s.writePackets(packets, client.Address); err != nil {
+			logger.WarnContext(ctx, "Failed to send UDP broadcast", "remote_addr", client.Address.String(), "id", notification.ID, "error", err)
 			failedClients = append(failedClients, client.Address.String())
-		} else {
-			successCount++
+			continue
+		}
+
+		successCount++
+		s.mutex.Lock()
+		client.pending[notification.ID] = &pendingNotification{
+			packets:     packets,
+			backoff:     s.ackTimeout,
+			nextAttempt: time.Now().Add(s.ackTimeout),
 		}
+		s.mutex.Unlock()
 	}
 
 	// Remove failed clients
 	if len(failedClients) > 0 {
-		s.mutex.Lock()
 		for _, addr := range failedClients {
-			delete(s.clients, addr)
+			s.removeClient(ctx, addr)
 		}
-		s.mutex.Unlock()
-		log.Printf("Removed %d failed UDP clients from broadcast list", len(failedClients))
 	}
 
-	log.Printf("Broadcasted notification to %d/%d clients successfully", successCount, len(clients))
+	logger.InfoContext(ctx, "UDP notification broadcast", "id", notification.ID, "type", notification.Type,
+		"topics", notification.Topics, "clients", len(clients), "sent", successCount)
 }
 
-// BroadcastNewManga broadcasts a new manga notification
-func (s *Server) BroadcastNewManga(mangaID, title string) {
-	notification := Notification{
-		Type:      "new_manga",
-		Message:   "New manga added: " + title,
-		Data:      map[string]string{"manga_id": mangaID, "title": title},
-		Timestamp: time.Now().Format(time.RFC3339),
+// retransmitPending resends unacknowledged notifications, doubling the wait
+// after every attempt, and expires them once maxRetries is exceeded.
+func (s *Server) retransmitPending() {
+	ticker := time.NewTicker(retransmitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			type resend struct {
+				addr    *net.UDPAddr
+				packets [][]byte
+			}
+			var resends []resend
+
+			now := time.Now()
+			s.mutex.Lock()
+			for _, client := range s.clients {
+				for id, p := range client.pending {
+					if now.Before(p.nextAttempt) {
+						continue
+					}
+					if p.attempts >= s.maxRetries {
+						delete(client.pending, id)
+						atomic.AddUint64(&s.expired, 1)
+						logging.Or(s.Logger).Info("UDP notification expired", "id", id, "remote_addr", client.Address.String(), "attempts", p.attempts)
+						continue
+					}
+					p.attempts++
+					p.backoff *= 2
+					p.nextAttempt = now.Add(p.backoff)
+					resends = append(resends, resend{addr: client.Address, packets: p.packets})
+				}
+			}
+			s.mutex.Unlock()
+
+			for _, r := range resends {
+				if err := s.writePackets(r.packets, r.addr); err != nil {
+					logging.Or(s.Logger).Warn("Failed to retransmit UDP notification", "remote_addr", r.addr.String(), "error", err)
+					continue
+				}
+				atomic.AddUint64(&s.retried, 1)
+			}
+		}
+	}
+}
+
+// BroadcastNewManga broadcasts a new manga notification to subscribers of
+// new_manga and of any of the manga's genres
+func (s *Server) BroadcastNewManga(mangaID, title string, genres []string) {
+	s.Broadcast(newMangaNotification(mangaID, title, genres))
+}
+
+func newMangaNotification(mangaID, title string, genres []string) Notification {
+	topics := []string{TopicNewManga}
+	for _, genre := range genres {
+		topics = append(topics, GenreTopic(genre))
+	}
+
+	return Notification{
+		Type:       "new_manga",
+		Message:    "New manga added: " + title,
+		Topics:     topics,
+		Data:       map[string]string{"manga_id": mangaID, "title": title},
+		DetailsURL: "/api/v1/manga/" + mangaID,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}
+}
+
+// BroadcastChapterRelease notifies subscribers of a manga about a new chapter
+func (s *Server) BroadcastChapterRelease(mangaID, title string, chapter int) {
+	s.Broadcast(chapterReleaseNotification(mangaID, title, chapter))
+}
+
+func chapterReleaseNotification(mangaID, title string, chapter int) Notification {
+	return Notification{
+		Type:       "chapter_release",
+		Message:    fmt.Sprintf("New chapter of %s: %d", title, chapter),
+		Topics:     []string{MangaTopic(mangaID)},
+		Data:       map[string]interface{}{"manga_id": mangaID, "title": title, "chapter": chapter},
+		DetailsURL: "/api/v1/manga/" + mangaID,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}
+}
+
+// BroadcastProgress notifies a user's own clients about a progress update
+func (s *Server) BroadcastProgress(userID, mangaID string, chapter int) {
+	s.Broadcast(progressNotification(userID, mangaID, chapter))
+}
+
+func progressNotification(userID, mangaID string, chapter int) Notification {
+	return Notification{
+		Type:    "update",
+		Message: "Progress updated",
+		Topics:  []string{TopicProgress},
+		UserID:  userID,
+		Data: map[string]interface{}{
+			"user_id":  userID,
+			"manga_id": mangaID,
+			"chapter":  chapter,
+		},
+		DetailsURL: "/api/v1/progress/" + mangaID,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}
+}
+
+// BroadcastLibraryUpdate notifies a user's own clients that a manga was
+// added to, changed in or removed from their library
+func (s *Server) BroadcastLibraryUpdate(userID, mangaID, action, status string) {
+	s.Broadcast(libraryNotification(userID, mangaID, action, status))
+}
+
+func libraryNotification(userID, mangaID, action, status string) Notification {
+	return Notification{
+		Type:    "library_update",
+		Message: "Library " + strings.ReplaceAll(action, "_", " "),
+		Topics:  []string{TopicLibrary},
+		UserID:  userID,
+		Data: map[string]interface{}{
+			"user_id":  userID,
+			"manga_id": mangaID,
+			"action":   action,
+			"status":   status,
+		},
+		DetailsURL: "/api/v1/library",
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}
+}
+
+// HandleEvent turns domain events into notifications. Subscribe it to an
+// events.Bus. Broadcasts are logged with the ID of the request that caused
+// the event.
+func (s *Server) HandleEvent(event events.Event) {
+	ctx := events.Context(event)
+	switch e := event.(type) {
+	case events.ProgressUpdated:
+		s.broadcast(ctx, progressNotification(e.UserID, e.MangaID, e.Chapter))
+	case events.LibraryUpdated:
+		s.broadcast(ctx, libraryNotification(e.UserID, e.MangaID, e.Action, e.Status))
+	case events.MangaCreated:
+		s.broadcast(ctx, newMangaNotification(e.Manga.ID, e.Manga.Title, e.Manga.Genres))
+	case events.MangaUpdated:
+		if e.Manga.TotalChapters > e.PreviousChapters {
+			s.broadcast(ctx, chapterReleaseNotification(e.Manga.ID, e.Manga.Title, e.Manga.TotalChapters))
+		}
 	}
-	s.Broadcast(notification)
 }
 
 // BroadcastUpdate broadcasts a general update notification
@@ -259,15 +758,24 @@ func (s *Server) cleanupInactiveClients() {
 			now := time.Now()
 			for key, client := range s.clients {
 				if now.Sub(client.LastSeen) > 2*time.Minute {
+					atomic.AddUint64(&s.expired, uint64(len(client.pending)))
 					delete(s.clients, key)
-					log.Printf("Removed inactive UDP client: %s", key)
+					s.Presence.Disconnect(client.UserID, presence.TransportUDP)
+					logging.Or(s.Logger).Info("Removed inactive UDP client", "remote_addr", key, "user_id", client.UserID)
 				}
 			}
 			s.mutex.Unlock()
+			s.limiter.cleanup()
+			s.challenges.cleanup()
 		}
 	}
 }
 
+// Running reports whether the server is receiving datagrams
+func (s *Server) Running() bool {
+	return s.running.Load()
+}
+
 // GetClientCount returns the number of registered clients
 func (s *Server) GetClientCount() int {
 	s.mutex.RLock()
@@ -275,3 +783,11 @@ func (s *Server) GetClientCount() int {
 	return len(s.clients)
 }
 
+// GetStats returns the delivered, retried and expired notification counters
+func (s *Server) GetStats() Stats {
+	return Stats{
+		Delivered: atomic.LoadUint64(&s.delivered),
+		Retried:   atomic.LoadUint64(&s.retried),
+		Expired:   atomic.LoadUint64(&s.expired),
+	}
+}
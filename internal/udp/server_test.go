@@ -0,0 +1,394 @@
+package udp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/events"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestServer(t *testing.T) *Server {
+	s := NewServer("127.0.0.1:0", "", 0)
+	s.ackTimeout = 50 * time.Millisecond
+	require.NoError(t, s.Start())
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func dialTestServer(t *testing.T, s *Server) *net.UDPConn {
+	conn, err := net.DialUDP("udp", nil, s.conn.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func sendJSON(t *testing.T, conn *net.UDPConn, msg map[string]interface{}) {
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+}
+
+func readDatagram(t *testing.T, conn *net.UDPConn) []byte {
+	buffer := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+	return buffer[:n]
+}
+
+func readNotification(t *testing.T, conn *net.UDPConn) Notification {
+	var notification Notification
+	require.NoError(t, json.Unmarshal(readDatagram(t, conn), &notification))
+	return notification
+}
+
+// register completes the token and cookie handshake and returns the session key
+func register(t *testing.T, conn *net.UDPConn, userID string, topics ...string) []byte {
+	key, _ := handshake(t, conn, userID, topics...)
+	return key
+}
+
+// handshake registers like register and also returns every datagram the
+// server sent during the handshake
+func handshake(t *testing.T, conn *net.UDPConn, userID string, topics ...string) ([]byte, [][]byte) {
+	token, err := auth.GenerateToken(models.User{ID: userID, Username: userID})
+	require.NoError(t, err)
+
+	msg := map[string]interface{}{"type": "register", "token": token}
+	if len(topics) > 0 {
+		msg["topics"] = topics
+	}
+	sendJSON(t, conn, msg)
+	first := readDatagram(t, conn)
+	var challenge Notification
+	require.NoError(t, json.Unmarshal(first, &challenge))
+	require.Equal(t, "challenge", challenge.Type)
+
+	share := newKeyShare()
+	msg["cookie"] = challenge.Message
+	msg["key_share"] = hex.EncodeToString(share.PublicKey().Bytes())
+	sendJSON(t, conn, msg)
+	second := readDatagram(t, conn)
+	var registered Notification
+	require.NoError(t, json.Unmarshal(second, &registered))
+	require.Equal(t, "registered", registered.Type)
+
+	address := challenge.Data.(map[string]interface{})["address"].(string)
+	serverShare := registered.Data.(map[string]interface{})["key_share"].(string)
+	key, err := deriveSessionKey(share, serverShare, token, challenge.Message, address)
+	require.NoError(t, err)
+	require.True(t, VerifyNotification(second, key), "the reply is signed with the derived key")
+	return key, [][]byte{first, second}
+}
+
+func TestServer_RetransmitsUntilAcked(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+
+	register(t, conn, "u1")
+
+	s.BroadcastNewManga("one-piece", "One Piece", nil)
+
+	first := readNotification(t, conn)
+	assert.Equal(t, "new_manga", first.Type)
+	assert.NotZero(t, first.ID)
+
+	// Not acknowledged yet, so the same notification comes back
+	retry := readNotification(t, conn)
+	assert.Equal(t, first.ID, retry.ID)
+
+	sendJSON(t, conn, map[string]interface{}{"type": "ack", "id": first.ID})
+	assert.Eventually(t, func() bool { return s.GetStats().Delivered == 1 }, time.Second, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, s.GetStats().Retried, uint64(1))
+}
+
+func TestServer_ExpiresUnackedNotifications(t *testing.T) {
+	s := startTestServer(t)
+	s.maxRetries = 1
+	conn := dialTestServer(t, s)
+
+	register(t, conn, "u1")
+
+	s.BroadcastUpdate("Progress updated", nil)
+
+	assert.Eventually(t, func() bool { return s.GetStats().Expired == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(0), s.GetStats().Delivered)
+}
+
+func TestClient_SuppressesDuplicates(t *testing.T) {
+	s := startTestServer(t)
+	c, err := Dial(s.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer c.Close()
+
+	notification := Notification{ID: 7, Type: "update"}
+	assert.True(t, c.accept(notification))
+	assert.False(t, c.accept(notification))
+	assert.True(t, c.accept(Notification{ID: 8, Type: "update"}))
+}
+
+func TestServer_FiltersBySubscription(t *testing.T) {
+	s := startTestServer(t)
+
+	action := dialTestServer(t, s)
+	register(t, action, "u1", "genre:Action")
+
+	follower := dialTestServer(t, s)
+	register(t, follower, "u2")
+	sendJSON(t, follower, map[string]interface{}{"type": "subscribe", "topics": []string{"manga:naruto", "progress"}})
+	assert.Equal(t, "subscriptions", readNotification(t, follower).Type)
+
+	s.BroadcastNewManga("one-piece", "One Piece", []string{"Action", "Adventure"})
+	s.BroadcastChapterRelease("naruto", "Naruto", 701)
+	s.BroadcastProgress("u2", "naruto", 12)
+	s.BroadcastProgress("u1", "naruto", 3)
+
+	assert.Equal(t, "new_manga", readNotification(t, action).Type)
+	assert.Equal(t, "chapter_release", readNotification(t, follower).Type)
+
+	progress := readNotification(t, follower)
+	assert.Equal(t, "update", progress.Type)
+	assert.Equal(t, "u2", progress.UserID)
+
+	// u1 only subscribed to a genre, so only the new manga is pending for it
+	s.mutex.RLock()
+	for _, client := range s.clients {
+		if client.UserID == "u1" {
+			assert.Len(t, client.pending, 1)
+		}
+	}
+	s.mutex.RUnlock()
+}
+
+func TestServer_KeepsPrivateNotificationsPrivate(t *testing.T) {
+	s := startTestServer(t)
+
+	// Neither client subscribed, so both receive every public notification
+	owner := dialTestServer(t, s)
+	register(t, owner, "u1")
+	other := dialTestServer(t, s)
+	register(t, other, "u2")
+
+	s.HandleEvent(events.LibraryUpdated{UserID: "u1", MangaID: "naruto", Action: events.LibraryAdded, Status: "reading"})
+	s.HandleEvent(events.ProgressUpdated{UserID: "u1", MangaID: "naruto", Chapter: 4})
+	s.HandleEvent(events.MangaCreated{Manga: models.Manga{ID: "one-piece", Title: "One Piece"}})
+
+	library := readNotification(t, owner)
+	assert.Equal(t, "library_update", library.Type)
+	assert.Equal(t, "added", library.Data.(map[string]interface{})["action"])
+	assert.Equal(t, "update", readNotification(t, owner).Type)
+	assert.Equal(t, "new_manga", readNotification(t, owner).Type)
+
+	assert.Equal(t, "new_manga", readNotification(t, other).Type)
+}
+
+func TestServer_RejectsUnauthenticatedRegistration(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+
+	// Legacy registration by claimed user ID gets no reply at all
+	sendJSON(t, conn, map[string]interface{}{"type": "register", "user_id": "u1"})
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 4096))
+	assert.Error(t, err)
+
+	// A forged cookie is rejected
+	token, err := auth.GenerateToken(models.User{ID: "u1"})
+	require.NoError(t, err)
+	sendJSON(t, conn, map[string]interface{}{"type": "register", "token": token, "cookie": "9999999999.deadbeef"})
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 4096))
+	assert.Error(t, err)
+	assert.Equal(t, 0, s.GetClientCount())
+}
+
+func TestServer_LimitsChallengesPerUser(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+	token, err := auth.GenerateToken(models.User{ID: "u1"})
+	require.NoError(t, err)
+
+	// A leaked token cannot turn the server into a reflector for spoofed
+	// sources: challenges stop once the user's burst is spent
+	for i := 0; i < int(challengeBurst); i++ {
+		sendJSON(t, conn, map[string]interface{}{"type": "register", "token": token})
+		assert.Equal(t, "challenge", readNotification(t, conn).Type)
+	}
+	sendJSON(t, conn, map[string]interface{}{"type": "register", "token": token})
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 4096))
+	assert.Error(t, err)
+}
+
+func TestServer_SignsNotifications(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+	key := register(t, conn, "u1")
+
+	s.BroadcastProgress("u1", "naruto", 5)
+
+	data := readDatagram(t, conn)
+	assert.True(t, VerifyNotification(data, key))
+	assert.False(t, VerifyNotification(data, []byte("some other key")))
+}
+
+func TestServer_NeverSendsSessionKey(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+	key, datagrams := handshake(t, conn, "u1")
+
+	s.mutex.RLock()
+	client := s.clients[conn.LocalAddr().String()]
+	s.mutex.RUnlock()
+	require.NotNil(t, client)
+	assert.Equal(t, client.key, key, "both sides derive the same key")
+
+	s.BroadcastProgress("u1", "naruto", 5)
+	datagrams = append(datagrams, readDatagram(t, conn))
+
+	for _, data := range datagrams {
+		assert.NotContains(t, string(data), hex.EncodeToString(key))
+		assert.False(t, bytes.Contains(data, key))
+	}
+
+	// The client library derives the same way
+	c, err := Dial(s.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer c.Close()
+	token, err := auth.GenerateToken(models.User{ID: "u2", Username: "u2"})
+	require.NoError(t, err)
+	require.NoError(t, c.Register(token))
+
+	s.mutex.RLock()
+	client = s.clients[c.conn.LocalAddr().String()]
+	s.mutex.RUnlock()
+	require.NotNil(t, client)
+	assert.Equal(t, client.key, c.sessionKey)
+}
+
+func TestServer_RejectsMissingKeyShare(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+	token, err := auth.GenerateToken(models.User{ID: "u1", Username: "u1"})
+	require.NoError(t, err)
+
+	msg := map[string]interface{}{"type": "register", "token": token}
+	sendJSON(t, conn, msg)
+	challenge := readNotification(t, conn)
+	require.Equal(t, "challenge", challenge.Type)
+
+	msg["cookie"] = challenge.Message
+	sendJSON(t, conn, msg)
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 4096))
+	assert.Error(t, err)
+	assert.Equal(t, 0, s.GetClientCount())
+}
+
+func TestSourceLimiter(t *testing.T) {
+	l := newSourceLimiter(inboundRate, inboundBurst)
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		if l.allow("10.0.0.1") {
+			allowed++
+		}
+	}
+	assert.Equal(t, int(inboundBurst), allowed)
+	assert.True(t, l.allow("10.0.0.2"))
+}
+
+func TestServer_AnnouncesAndMulticastsPublicNotifications(t *testing.T) {
+	group, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer group.Close()
+
+	s := NewServer("127.0.0.1:0", "127.0.0.1", group.LocalAddr().(*net.UDPAddr).Port)
+	s.Services = map[string]string{"http": ":8080"}
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	var announcement Announcement
+	require.NoError(t, json.Unmarshal(readDatagram(t, group), &announcement))
+	assert.Equal(t, "announce", announcement.Type)
+	assert.Equal(t, ":8080", announcement.Services["http"])
+	assert.Equal(t, "127.0.0.1:0", announcement.Services["udp"])
+
+	// Private progress is never multicast, public notifications are
+	s.BroadcastProgress("u1", "naruto", 3)
+	s.BroadcastNewManga("one-piece", "One Piece", nil)
+
+	notification := readNotification(t, group)
+	assert.Equal(t, "new_manga", notification.Type)
+	assert.Empty(t, notification.Signature)
+}
+
+func TestServer_FragmentsLargeNotifications(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+	key := register(t, conn, "u1")
+
+	s.BroadcastUpdate("Large update", map[string]string{"blob": strings.Repeat("x", 5000)})
+
+	r := newReassembler()
+	var data []byte
+	for complete := false; !complete; {
+		packet := readDatagram(t, conn)
+		assert.LessOrEqual(t, len(packet), MaxDatagramSize)
+		data, complete = r.unwrap(packet)
+	}
+
+	assert.True(t, VerifyNotification(data, key))
+	var notification Notification
+	require.NoError(t, json.Unmarshal(data, &notification))
+	assert.Equal(t, "Large update", notification.Message)
+	assert.Len(t, notification.Data.(map[string]interface{})["blob"], 5000)
+}
+
+func TestServer_FallsBackToDetailsURL(t *testing.T) {
+	s := startTestServer(t)
+	conn := dialTestServer(t, s)
+	key := register(t, conn, "u1")
+
+	s.Broadcast(Notification{
+		Type:       "update",
+		Message:    "Huge update",
+		Data:       strings.Repeat("x", MaxFragments*MaxDatagramSize),
+		DetailsURL: "/api/v1/manga/one-piece",
+	})
+
+	data := readDatagram(t, conn)
+	assert.True(t, VerifyNotification(data, key))
+	var notification Notification
+	require.NoError(t, json.Unmarshal(data, &notification))
+	assert.True(t, notification.Truncated)
+	assert.Nil(t, notification.Data)
+	assert.Equal(t, "/api/v1/manga/one-piece", notification.DetailsURL)
+}
+
+func TestReassembler_IgnoresDuplicateFragments(t *testing.T) {
+	payload := strings.Repeat("0123456789", fragmentChunkSize/10+1)
+	packets, err := fragment(9, []byte(payload))
+	require.NoError(t, err)
+	require.Len(t, packets, 2)
+
+	r := newReassembler()
+	_, complete := r.unwrap(packets[0])
+	assert.False(t, complete)
+	_, complete = r.unwrap(packets[0])
+	assert.False(t, complete)
+
+	data, complete := r.unwrap(packets[1])
+	assert.True(t, complete)
+	assert.Equal(t, payload, string(data))
+}
@@ -2,83 +2,77 @@ package user
 
 import (
 	"net/http"
-	"mangahub/internal/auth"
-	"mangahub/pkg/models"
+
+	"mangahub/internal/problem"
+	"mangahub/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
+// UserHandler registers and logs in users over REST through the UserService
 type UserHandler struct {
-	Repo *UserRepository
+	Service *service.UserService
 }
 
-func (h *UserHandler) Register(c *gin.Context) {
-	var req struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required,min=6"`
-	}
-
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		return
-	}
-
-	// Check if user exists
-	_, err := h.Repo.GetUserByUsername(req.Username)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
-		return
-	}
-
-	hash, err := auth.HashPassword(req.Password)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-		return
-	}
+// RegisterRequest is the body of Register
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email"`
+}
 
-	user := models.User{
-		ID:           uuid.New().String(),
-		Username:     req.Username,
-		PasswordHash: hash,
-	}
+// RegisterResponse is the reply of Register
+type RegisterResponse struct {
+	Message string `json:"message"`
+	UserID  string `json:"user_id"`
+}
 
-	if err := h.Repo.CreateUser(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-		return
-	}
+// LoginRequest is the body of Login
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully", "user_id": user.ID})
+// LoginResponse is the reply of Login. Token goes in the Authorization
+// header of authenticated requests.
+type LoginResponse struct {
+	Token    string `json:"token"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
 }
 
-func (h *UserHandler) Login(c *gin.Context) {
-	var req struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
+func (h *UserHandler) Register(c *gin.Context) {
+	var req RegisterRequest
 
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if !problem.BindJSON(c, &req) {
 		return
 	}
 
-	user, err := h.Repo.GetUserByUsername(req.Username)
+	user, err := h.Service.Register(c.Request.Context(), service.Registration{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+	})
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		problem.Write(c, err)
 		return
 	}
 
-	if err := auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	c.JSON(http.StatusCreated, RegisterResponse{Message: "User registered successfully", UserID: user.ID})
+}
+
+func (h *UserHandler) Login(c *gin.Context) {
+	var req LoginRequest
+
+	if !problem.BindJSON(c, &req) {
 		return
 	}
 
-	token, err := auth.GenerateToken(user)
+	session, err := h.Service.Login(c.Request.Context(), service.Credentials{Username: req.Username, Password: req.Password})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		problem.Write(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token, "user_id": user.ID, "username": user.Username})
+	c.JSON(http.StatusOK, LoginResponse{Token: session.Token, UserID: session.Claims.UserID, Username: session.Claims.Username})
 }
-
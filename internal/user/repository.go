@@ -1,7 +1,9 @@
 package user
 
 import (
+	"context"
 	"database/sql"
+	"mangahub/pkg/database"
 	"mangahub/pkg/models"
 )
 
@@ -9,36 +11,52 @@ type UserRepository struct {
 	DB *sql.DB
 }
 
-func (r *UserRepository) CreateUser(user models.User) error {
-	_, err := r.DB.Exec("INSERT INTO users (id, username, email, password_hash) VALUES (?, ?, ?, ?)",
+// userColumns are the columns scanned into a models.User
+const userColumns = "id, username, COALESCE(email, ''), role, password_hash, created_at"
+
+func (r *UserRepository) CreateUser(ctx context.Context, user models.User) error {
+	// An empty email is stored as NULL so it does not clash with the UNIQUE constraint
+	_, err := r.DB.ExecContext(ctx, "INSERT INTO users (id, username, email, password_hash) VALUES (?, ?, NULLIF(?, ''), ?)",
 		user.ID, user.Username, user.Email, user.PasswordHash)
-	return err
+	return database.Translate(err)
 }
 
-func (r *UserRepository) GetUserByUsername(username string) (models.User, error) {
-	row := r.DB.QueryRow("SELECT id, username, email, password_hash FROM users WHERE username = ?", username)
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE username = ?", username)
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.PasswordHash, &user.CreatedAt)
 	return user, err
 }
 
-func (r *UserRepository) GetUserByEmail(email string) (models.User, error) {
-	row := r.DB.QueryRow("SELECT id, username, email, password_hash FROM users WHERE email = ?", email)
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE email = ?", email)
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.PasswordHash, &user.CreatedAt)
 	return user, err
 }
 
 // GetUserByID fetches a user by their ID.
-func (r *UserRepository) GetUserByID(id string) (models.User, error) {
-	row := r.DB.QueryRow("SELECT id, username, email, password_hash FROM users WHERE id = ?", id)
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (models.User, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", id)
 	var user models.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash)
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.PasswordHash, &user.CreatedAt)
 	return user, err
 }
 
 // UpdatePassword updates the password hash for a user.
-func (r *UserRepository) UpdatePassword(id string, newHash string) error {
-	_, err := r.DB.Exec("UPDATE users SET password_hash = ? WHERE id = ?", newHash, id)
+func (r *UserRepository) UpdatePassword(ctx context.Context, id string, newHash string) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE users SET password_hash = ? WHERE id = ?", newHash, id)
+	return err
+}
+
+// SetRole changes a user's role. It returns sql.ErrNoRows when the user does not exist.
+func (r *UserRepository) SetRole(ctx context.Context, id string, role string) error {
+	result, err := r.DB.ExecContext(ctx, "UPDATE users SET role = ? WHERE id = ?", role, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
 	return err
 }
@@ -0,0 +1,78 @@
+package websocket
+
+import (
+	"sync"
+
+	"mangahub/pkg/models"
+)
+
+// Envelope is a message routed between hubs through a Broker. Exactly one of
+// Room, User or Sanction is usually set; an envelope with none of them goes
+// to every client.
+type Envelope struct {
+	// Origin is the ID of the hub that published the envelope
+	Origin string `json:"origin"`
+	// Room delivers the message to the members of a room
+	Room string `json:"room,omitempty"`
+	// User delivers the message to every connection of a user
+	User string `json:"user,omitempty"`
+	// Exclude is the ID of a client on the origin hub that must not receive
+	// the message
+	Exclude  string                   `json:"exclude,omitempty"`
+	Message  Message                  `json:"message"`
+	Sanction *models.ModerationAction `json:"sanction,omitempty"`
+}
+
+// Broker carries envelopes between hubs, so clients connected to different
+// server instances can talk to each other. Every subscriber, including the
+// publishing hub, receives every envelope.
+type Broker interface {
+	Publish(env Envelope) error
+	// Subscribe calls handler for every published envelope until the
+	// returned function is called
+	Subscribe(handler func(Envelope)) (unsubscribe func(), err error)
+}
+
+// MemoryBroker delivers envelopes to the hubs of a single process. It is the
+// default when no external broker is configured.
+type MemoryBroker struct {
+	subscribers map[int]func(Envelope)
+	nextID      int
+	mutex       sync.RWMutex
+}
+
+// NewMemoryBroker creates an in-process broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: make(map[int]func(Envelope))}
+}
+
+// Publish calls every subscriber in turn
+func (b *MemoryBroker) Publish(env Envelope) error {
+	b.mutex.RLock()
+	handlers := make([]func(Envelope), 0, len(b.subscribers))
+	for _, handler := range b.subscribers {
+		handlers = append(handlers, handler)
+	}
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(env)
+	}
+	return nil
+}
+
+// Subscribe registers a handler
+func (b *MemoryBroker) Subscribe(handler func(Envelope)) (func(), error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = handler
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.subscribers, id)
+	}, nil
+}
@@ -0,0 +1,183 @@
+package websocket
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHubWithBroker starts a hub using broker, as one server instance
+func startHubWithBroker(t *testing.T, broker Broker) (*Hub, string) {
+	hub := NewHub()
+	hub.Broker = broker
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWebSocket(hub, w, r)
+	}))
+	t.Cleanup(server.Close)
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// fakeRedis implements the SUBSCRIBE and PUBLISH commands of a Redis server
+type fakeRedis struct {
+	listener    net.Listener
+	subscribers map[string][]net.Conn
+	mutex       sync.Mutex
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f := &fakeRedis{listener: listener, subscribers: make(map[string][]net.Conn)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		command, err := readRESP(reader)
+		if err != nil {
+			return
+		}
+		args, _ := command.([]interface{})
+		if len(args) == 0 {
+			return
+		}
+
+		f.mutex.Lock()
+		switch strings.ToUpper(args[0].(string)) {
+		case "SUBSCRIBE":
+			channel := args[1].(string)
+			f.subscribers[channel] = append(f.subscribers[channel], conn)
+			fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(channel), channel)
+		case "PUBLISH":
+			channel, payload := args[1].(string), args[2].(string)
+			for _, subscriber := range f.subscribers[channel] {
+				writeCommand(subscriber, "message", channel, payload)
+			}
+			fmt.Fprintf(conn, ":%d\r\n", len(f.subscribers[channel]))
+		default:
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		f.mutex.Unlock()
+	}
+}
+
+// dropSubscribers closes every subscriber connection, as a restart would
+func (f *fakeRedis) dropSubscribers() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for channel, conns := range f.subscribers {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		delete(f.subscribers, channel)
+	}
+}
+
+func (f *fakeRedis) subscriberCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.subscribers["mangahub:test"])
+}
+
+func TestRedisBroker_SharesMessagesBetweenHubs(t *testing.T) {
+	redis := startFakeRedis(t)
+	addr := redis.listener.Addr().String()
+
+	hubA, urlA := startHubWithBroker(t, NewRedisBroker(addr, "mangahub:test"))
+	hubB, urlB := startHubWithBroker(t, NewRedisBroker(addr, "mangahub:test"))
+	require.Eventually(t, func() bool { return redis.subscriberCount() == 2 }, 2*time.Second, 10*time.Millisecond)
+
+	alice := dialAs(t, urlA, "u1", "alice")
+	bob := dialAs(t, urlB, "u2", "bob")
+	assert.Eventually(t, func() bool { return hubA.IsOnline("u1") && hubB.IsOnline("u2") }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "join_room", Room: "lobby"}))
+	readMessage(t, alice, "room_joined")
+	require.NoError(t, bob.WriteJSON(Message{Type: "join_room", Room: "lobby"}))
+	readMessage(t, bob, "room_joined")
+	assert.Equal(t, "bob", readMessage(t, alice, "user_joined").Username)
+
+	require.NoError(t, bob.WriteJSON(Message{Type: "chat", Room: "lobby", Content: "across instances"}))
+	assert.Equal(t, "across instances", readMessage(t, alice, "chat").Content)
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "dm", To: "u2", Content: "psst"}))
+	assert.Equal(t, "psst", readMessage(t, bob, "dm").Content)
+
+	// Subscriptions come back after the connection drops
+	redis.dropSubscribers()
+	require.Eventually(t, func() bool { return redis.subscriberCount() == 2 }, 5*time.Second, 50*time.Millisecond)
+	require.NoError(t, bob.WriteJSON(Message{Type: "chat", Room: "lobby", Content: "still here"}))
+	assert.Equal(t, "still here", readMessage(t, alice, "chat").Content)
+}
+
+func TestMemoryBroker_SharesMessagesBetweenHubs(t *testing.T) {
+	broker := NewMemoryBroker()
+	_, urlA := startHubWithBroker(t, broker)
+	hubB, urlB := startHubWithBroker(t, broker)
+
+	alice := dialAs(t, urlA, "u1", "alice")
+	dialAs(t, urlB, "u2", "bob")
+	assert.Eventually(t, func() bool { return hubB.IsOnline("u2") }, time.Second, 10*time.Millisecond)
+
+	// Sanctions reach the other hub too
+	mod := dialWithRole(t, urlA, "m1", "mod", "moderator")
+	require.NoError(t, mod.WriteJSON(Message{Type: "ban", To: "u2"}))
+	readMessage(t, mod, "moderated")
+	assert.Eventually(t, func() bool { return hubB.IsBanned("u2") }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "ping"}))
+	readMessage(t, alice, "pong")
+}
+
+func TestHub_SlowClientIsDroppedOnce(t *testing.T) {
+	hub, url := startTestHub(t)
+
+	// A client nobody reads from, with room for a single message
+	slow := &Client{ID: "slow", UserID: "u1", Username: "slow", Send: make(chan Message, 1), Hub: hub}
+	hub.register <- slow
+	require.True(t, hub.joinRoom(slow, "lobby"))
+
+	for i := 0; i < 3; i++ {
+		hub.broadcastToOthers(Message{Type: "chat", Room: "lobby", Content: "flood"}, nil)
+	}
+	assert.Eventually(t, func() bool { return !hub.IsOnline("u1") }, time.Second, 10*time.Millisecond)
+	assert.Empty(t, hub.RoomMembers("lobby"))
+
+	// The buffered message is still there, then the channel is closed
+	msg, ok := <-slow.Send
+	assert.True(t, ok)
+	assert.Equal(t, "flood", msg.Content)
+	_, ok = <-slow.Send
+	assert.False(t, ok)
+
+	// Unregistering it again, as its readPump would, is harmless
+	hub.unregister <- slow
+	conn := dialAs(t, url, "u2", "bob")
+	require.NoError(t, conn.WriteJSON(Message{Type: "ping"}))
+	readMessage(t, conn, "pong")
+}
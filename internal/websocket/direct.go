@@ -0,0 +1,208 @@
+package websocket
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"mangahub/pkg/models"
+)
+
+// DirectMessageStore persists direct messages so they reach users who are
+// offline when they are sent
+type DirectMessageStore interface {
+	SaveDirectMessage(ctx context.Context, msg models.DirectMessage) (int64, error)
+	GetUndelivered(ctx context.Context, userID string) ([]models.DirectMessage, error)
+	MarkDelivered(ctx context.Context, ids []int64) error
+	MarkRead(ctx context.Context, userID, peerID string, upToID int64) (int64, error)
+}
+
+// addUserClient indexes a client under its user. Only called from Run.
+func (h *Hub) addUserClient(client *Client) {
+	if h.users[client.UserID] == nil {
+		h.users[client.UserID] = make(map[*Client]bool)
+	}
+	h.users[client.UserID][client] = true
+}
+
+// removeUserClient drops a client from the user index. Only called from Run.
+func (h *Hub) removeUserClient(client *Client) {
+	delete(h.users[client.UserID], client)
+	if len(h.users[client.UserID]) == 0 {
+		delete(h.users, client.UserID)
+	}
+}
+
+// IsOnline reports whether a user has at least one client connected to
+// this hub
+func (h *Hub) IsOnline(userID string) bool {
+	var online bool
+	h.do(func() { online = len(h.users[userID]) > 0 })
+	return online
+}
+
+// sendToUser sends a message to every connection of a user, on any hub,
+// except exclude
+func (h *Hub) sendToUser(userID string, message Message, exclude *Client) {
+	env := Envelope{User: userID, Message: message}
+	if exclude != nil {
+		env.Exclude = exclude.ID
+	}
+	h.publish(env)
+}
+
+// isDirectDelivery reports whether an envelope carries a stored direct
+// message to its recipient, as opposed to the copy echoed to the sender
+func isDirectDelivery(env Envelope) bool {
+	return env.Message.Type == "dm" && env.Message.ID > 0 && env.User == env.Message.To
+}
+
+// markDelivered flags a direct message as delivered without blocking Run
+func (h *Hub) markDelivered(id int64) {
+	if h.DirectStore == nil {
+		return
+	}
+	go func() {
+		if err := h.DirectStore.MarkDelivered(context.Background(), []int64{id}); err != nil {
+			h.logger().Error("Failed to mark direct message delivered", "message_id", id, "error", err)
+		}
+	}()
+}
+
+// deliverPending sends a newly connected client the direct messages its
+// user received while offline. Messages that do not fit in the client's
+// buffer stay pending until the next connection.
+func (h *Hub) deliverPending(client *Client) {
+	if h.DirectStore == nil {
+		return
+	}
+
+	pending, err := h.DirectStore.GetUndelivered(client.context(), client.UserID)
+	if err != nil {
+		client.log().Error("Failed to load pending direct messages", "error", err)
+		return
+	}
+
+	var delivered []int64
+	h.do(func() {
+		for _, m := range pending {
+			if len(client.Send) >= cap(client.Send)/2 || !h.send(client, directMessage(m)) {
+				break
+			}
+			delivered = append(delivered, m.ID)
+		}
+	})
+	if err := h.DirectStore.MarkDelivered(client.context(), delivered); err != nil {
+		client.log().Error("Failed to mark direct messages delivered", "error", err)
+	}
+}
+
+// sendDirect stores a direct message and delivers it to the recipient's
+// connections and the sender's own, so every device sees the conversation
+func (c *Client) sendDirect(msg Message) {
+	if msg.To == "" || msg.To == c.UserID {
+		c.reply(errorMessage("Direct messages need a recipient other than yourself"))
+		return
+	}
+	content, ok := c.checkContent(msg.Content)
+	if !ok {
+		return
+	}
+
+	dm := Message{
+		Type:      "dm",
+		UserID:    c.UserID,
+		Username:  c.Username,
+		To:        msg.To,
+		Content:   content,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	store := c.Hub.DirectStore
+	if store != nil {
+		id, err := store.SaveDirectMessage(c.context(), models.DirectMessage{
+			SenderID:    c.UserID,
+			SenderName:  c.Username,
+			RecipientID: msg.To,
+			Content:     content,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			c.reply(errorMessage("Unknown recipient"))
+			return
+		}
+		if err != nil {
+			c.log().Error("Failed to save direct message", "to", msg.To, "error", err)
+			c.reply(errorMessage("Failed to send message"))
+			return
+		}
+		dm.ID = id
+	}
+
+	// The recipient's hub marks the message delivered
+	c.Hub.sendToUser(msg.To, dm, nil)
+	c.Hub.sendToUser(c.UserID, dm, nil)
+}
+
+// markRead records that the user read msg.To's messages up to msg.ID and
+// sends a read receipt to that user and the reader's other connections
+func (c *Client) markRead(msg Message) {
+	if msg.To == "" {
+		c.reply(errorMessage("Read receipts need the user whose messages were read"))
+		return
+	}
+
+	if c.Hub.DirectStore != nil {
+		if _, err := c.Hub.DirectStore.MarkRead(c.context(), c.UserID, msg.To, msg.ID); err != nil {
+			c.log().Error("Failed to mark direct messages read", "from", msg.To, "error", err)
+			c.reply(errorMessage("Failed to mark messages read"))
+			return
+		}
+	}
+
+	receipt := Message{
+		ID:        msg.ID,
+		Type:      "dm_read",
+		UserID:    c.UserID,
+		Username:  c.Username,
+		To:        msg.To,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	c.Hub.sendToUser(msg.To, receipt, nil)
+	c.Hub.sendToUser(c.UserID, receipt, c)
+}
+
+// typing forwards a typing indicator to a user or to the other members of a
+// room. Indicators are never stored.
+func (c *Client) typing(msg Message) {
+	indicator := Message{
+		Type:      "typing",
+		UserID:    c.UserID,
+		Username:  c.Username,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	switch {
+	case msg.To != "" && msg.To != c.UserID:
+		indicator.To = msg.To
+		c.Hub.sendToUser(msg.To, indicator, nil)
+	case msg.Room != "" && c.Hub.inRoom(c, msg.Room):
+		indicator.Room = msg.Room
+		c.Hub.broadcastToOthers(indicator, c)
+	default:
+		c.reply(errorMessage("Typing indicators need a recipient or a room you are in"))
+	}
+}
+
+// directMessage converts a stored direct message into its WebSocket form
+func directMessage(m models.DirectMessage) Message {
+	return Message{
+		ID:        m.ID,
+		Type:      "dm",
+		UserID:    m.SenderID,
+		Username:  m.SenderName,
+		To:        m.RecipientID,
+		Content:   m.Content,
+		Timestamp: storedTimestamp(m.CreatedAt),
+	}
+}
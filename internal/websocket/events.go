@@ -0,0 +1,69 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"mangahub/internal/events"
+)
+
+// HandleEvent pushes domain events to connected clients: progress and
+// library changes to the user's own connections, catalog changes to
+// everyone and presence changes to those allowed to see them. Events go
+// through the broker like any other message, so they reach clients on every
+// instance sharing it. Subscribe it to an events.Bus. Each event is logged
+// with the ID of the request that caused it.
+func (h *Hub) HandleEvent(event events.Event) {
+	ctx := events.Context(event)
+	var message Message
+	switch e := event.(type) {
+	case events.ProgressUpdated:
+		message = eventMessage("progress_update", e.UserID, e, e.Timestamp)
+		h.sendToUser(e.UserID, message, nil)
+	case events.LibraryUpdated:
+		message = eventMessage("library_update", e.UserID, e, e.Timestamp)
+		h.sendToUser(e.UserID, message, nil)
+	case events.MangaCreated:
+		message = eventMessage("new_manga", "", e.Manga, e.Timestamp)
+		h.publish(Envelope{Message: message})
+	case events.MangaUpdated:
+		message = eventMessage("manga_updated", "", e.Manga, e.Timestamp)
+		h.publish(Envelope{Message: message})
+	case events.MangaDeleted:
+		message = eventMessage("manga_deleted", "", e, e.Timestamp)
+		h.publish(Envelope{Message: message})
+	case events.PresenceChanged:
+		message = h.presenceChanged(ctx, e)
+	default:
+		return
+	}
+	h.logger().DebugContext(ctx, "WebSocket event published", "type", message.Type, "user_id", message.UserID)
+}
+
+// presenceChanged sends a presence update to the user's audience and
+// returns it
+func (h *Hub) presenceChanged(ctx context.Context, e events.PresenceChanged) Message {
+	message := eventMessage("presence_update", e.UserID, e, e.Timestamp)
+	everyone, audience, err := h.Presence.Audience(ctx, e.UserID)
+	if err != nil {
+		h.logger().ErrorContext(ctx, "Failed to load presence audience", "user_id", e.UserID, "error", err)
+		return message
+	}
+	if everyone || e.Everyone {
+		h.publish(Envelope{Message: message})
+		return message
+	}
+	for _, userID := range audience {
+		h.sendToUser(userID, message, nil)
+	}
+	return message
+}
+
+func eventMessage(messageType, userID string, data interface{}, timestamp time.Time) Message {
+	return Message{
+		Type:      messageType,
+		UserID:    userID,
+		Data:      data,
+		Timestamp: timestamp.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mangahub/internal/events"
+	"mangahub/internal/presence"
+	"mangahub/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_HandleEvent(t *testing.T) {
+	hub, url := startTestHub(t)
+
+	alice := dialAs(t, url, "u1", "alice")
+	bob := dialAs(t, url, "u2", "bob")
+	assert.Eventually(t, func() bool { return hub.IsOnline("u1") && hub.IsOnline("u2") }, time.Second, 10*time.Millisecond)
+
+	hub.HandleEvent(events.ProgressUpdated{UserID: "u1", MangaID: "naruto", Chapter: 7, Timestamp: time.Now()})
+	hub.HandleEvent(events.LibraryUpdated{UserID: "u1", MangaID: "naruto", Action: events.LibraryRemoved, Timestamp: time.Now()})
+	hub.HandleEvent(events.MangaCreated{Manga: models.Manga{ID: "one-piece", Title: "One Piece"}, Timestamp: time.Now()})
+
+	progress := readMessage(t, alice, "progress_update")
+	assert.Equal(t, "u1", progress.UserID)
+	assert.Equal(t, float64(7), progress.Data.(map[string]interface{})["chapter"])
+	library := readMessage(t, alice, "library_update")
+	assert.Equal(t, "removed", library.Data.(map[string]interface{})["action"])
+	readMessage(t, alice, "new_manga")
+
+	// Other users only see the public event
+	bob.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg Message
+	require.NoError(t, bob.ReadJSON(&msg))
+	assert.Equal(t, "new_manga", msg.Type)
+	assert.Equal(t, "One Piece", msg.Data.(map[string]interface{})["title"])
+}
+
+// friendsOnly lets users see only the users that added them as friends
+type friendsOnly map[string][]string
+
+func (f friendsOnly) GetVisibility(_ context.Context, userID string) (string, error) {
+	return models.VisibilityFriends, nil
+}
+
+func (f friendsOnly) GetFriendIDs(_ context.Context, userID string) ([]string, error) {
+	return f[userID], nil
+}
+
+func TestHub_PresenceUpdates(t *testing.T) {
+	hub, url := startTestHub(t)
+	bus := events.NewBus()
+	defer bus.Close()
+	hub.Presence = presence.NewTracker(friendsOnly{"u1": {"u2"}}, bus)
+	bus.Subscribe("websocket", hub.HandleEvent)
+
+	bob := dialAs(t, url, "u2", "bob")
+	carol := dialAs(t, url, "u3", "carol")
+	assert.Eventually(t, func() bool { return hub.IsOnline("u2") && hub.IsOnline("u3") }, time.Second, 10*time.Millisecond)
+
+	dialAs(t, url, "u1", "alice")
+	update := readMessage(t, bob, "presence_update")
+	assert.Equal(t, "u1", update.UserID)
+	assert.Equal(t, true, update.Data.(map[string]interface{})["online"])
+	assert.True(t, hub.Presence.Status("u1").Online)
+
+	// carol is not on alice's friends list, so the next thing she hears
+	// about is the new manga published after alice connected
+	bus.Publish(events.MangaCreated{Manga: models.Manga{ID: "one-piece"}, Timestamp: time.Now()})
+	carol.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg Message
+		require.NoError(t, carol.ReadJSON(&msg))
+		if msg.Type == "new_manga" {
+			break
+		}
+		assert.NotEqual(t, "u1", msg.UserID, "unexpected %s", msg.Type)
+	}
+}
@@ -0,0 +1,323 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"mangahub/internal/logging"
+	"mangahub/internal/presence"
+	"mangahub/internal/ratelimit"
+
+	"github.com/google/uuid"
+)
+
+// queueSize bounds the hub's internal queues
+const queueSize = 1024
+
+var errHubStopped = errors.New("hub stopped")
+
+// Hub maintains the set of active clients and routes messages to them.
+//
+// All client, room and sanction state is owned by the Run goroutine. Other
+// goroutines hand it work through channels and only Run sends on or closes a
+// client's Send channel, so a slow client is dropped instead of blocking
+// anyone. Room and user messages go through the Broker, which delivers them
+// to every hub sharing it, including this one.
+type Hub struct {
+	// CheckOrigin decides which browser origins may connect. When nil only
+	// same-origin and non-browser requests are accepted.
+	CheckOrigin func(r *http.Request) bool
+	// Store persists chat messages; when nil messages are not kept
+	Store MessageStore
+	// HistorySize is how many stored messages are replayed on join
+	HistorySize int
+	// DirectStore persists direct messages; when nil they are only delivered
+	// to users who are online
+	DirectStore DirectMessageStore
+	// Moderation records moderation actions; when nil they are not audited
+	// and messages cannot be deleted
+	Moderation ModerationStore
+	// Users gives the roles of moderation targets; when nil only the roles
+	// of targets connected to this hub are known
+	Users UserLookup
+	// Filter masks blocked words in chat and direct messages
+	Filter *WordFilter
+	// MaxMessageLength bounds message content in characters; 0 is unlimited
+	MaxMessageLength int
+	// MessageRate is how many messages per second a connection may send on
+	// average, with bursts of MessageBurst; 0 disables rate limiting
+	MessageRate  float64
+	MessageBurst int
+	// ConnectionLimiter limits how often each client IP may connect; nil
+	// accepts every connection
+	ConnectionLimiter *ratelimit.Limiter
+	// Presence is told which users are connected and decides who receives
+	// their presence updates; may be nil
+	Presence *presence.Tracker
+	// Logger logs the hub and its connections; nil uses the default logger
+	Logger *slog.Logger
+
+	// ID identifies this hub on the broker
+	ID string
+	// Broker shares room and user messages between server instances. It
+	// defaults to a MemoryBroker and must be set before Run.
+	Broker Broker
+
+	clients map[*Client]bool
+	rooms   map[string]map[*Client]bool
+	users   map[string]map[*Client]bool
+	mutes   map[string]time.Time
+	bans    map[string]time.Time
+
+	register   chan *Client
+	unregister chan *Client
+	actions    chan func()
+	inbound    chan Envelope
+	outbox     chan Envelope
+	done       chan struct{}
+	stopOnce   sync.Once
+}
+
+// NewHub creates a new Hub
+func NewHub() *Hub {
+	return &Hub{
+		ID:         uuid.New().String(),
+		Broker:     NewMemoryBroker(),
+		clients:    make(map[*Client]bool),
+		rooms:      make(map[string]map[*Client]bool),
+		users:      make(map[string]map[*Client]bool),
+		mutes:      make(map[string]time.Time),
+		bans:       make(map[string]time.Time),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		actions:    make(chan func(), queueSize),
+		inbound:    make(chan Envelope, queueSize),
+		outbox:     make(chan Envelope, queueSize),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run starts the hub and returns once Stop is called
+func (h *Hub) Run() {
+	unsubscribe, err := h.Broker.Subscribe(func(env Envelope) {
+		select {
+		case h.inbound <- env:
+		case <-h.done:
+		}
+	})
+	if err != nil {
+		h.logger().Error("WebSocket hub could not subscribe to the broker", "error", err)
+	} else {
+		defer unsubscribe()
+	}
+	go h.publishLoop()
+
+	for {
+		select {
+		case client := <-h.register:
+			h.add(client)
+
+		case client := <-h.unregister:
+			h.remove(client)
+
+		case fn := <-h.actions:
+			fn()
+
+		case env := <-h.inbound:
+			h.deliver(env)
+
+		case <-h.done:
+			for client := range h.clients {
+				close(client.Send)
+				h.Presence.Disconnect(client.UserID, presence.TransportWebSocket)
+			}
+			h.clients = make(map[*Client]bool)
+			return
+		}
+	}
+}
+
+// logger returns the hub's logger, which names the hub
+func (h *Hub) logger() *slog.Logger {
+	return logging.Or(h.Logger).With("hub_id", h.ID)
+}
+
+// Stop disconnects every client and stops Run
+func (h *Hub) Stop() {
+	h.stopOnce.Do(func() { close(h.done) })
+}
+
+// Ping reports whether Run is processing work, waiting at most until ctx
+// is done
+func (h *Hub) Ping(ctx context.Context) error {
+	finished := make(chan struct{})
+	select {
+	case h.actions <- func() { close(finished) }:
+	case <-h.done:
+		return errHubStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-finished:
+		return nil
+	case <-h.done:
+		return errHubStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// publishLoop hands queued envelopes to the broker in order
+func (h *Hub) publishLoop() {
+	for {
+		select {
+		case env := <-h.outbox:
+			if err := h.Broker.Publish(env); err != nil {
+				h.logger().Error("WebSocket hub failed to publish", "type", env.Message.Type, "error", err)
+			}
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// publish queues an envelope for the broker. It never blocks, so it is safe
+// to call from Run.
+func (h *Hub) publish(env Envelope) {
+	env.Origin = h.ID
+	select {
+	case h.outbox <- env:
+	default:
+		h.logger().Warn("WebSocket hub broker queue full, dropping message", "type", env.Message.Type)
+	}
+}
+
+// run queues fn to be executed by Run
+func (h *Hub) run(fn func()) {
+	select {
+	case h.actions <- fn:
+	case <-h.done:
+	}
+}
+
+// do executes fn on Run and waits for it. It reports false when the hub has
+// stopped and fn did not run.
+func (h *Hub) do(fn func()) bool {
+	finished := make(chan struct{})
+	select {
+	case h.actions <- func() { fn(); close(finished) }:
+	case <-h.done:
+		return false
+	}
+	select {
+	case <-finished:
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+// add registers a client. Only called from Run.
+func (h *Hub) add(client *Client) {
+	h.clients[client] = true
+	h.addUserClient(client)
+	h.Presence.Connect(client.UserID, presence.TransportWebSocket)
+	client.log().Info("WebSocket client connected", "clients", len(h.clients))
+}
+
+// remove unregisters a client, closes its Send channel and tells the rooms
+// it was in. Removing a client twice is a no-op. Only called from Run.
+func (h *Hub) remove(client *Client) {
+	if !h.clients[client] {
+		return
+	}
+	delete(h.clients, client)
+	close(client.Send)
+	h.removeUserClient(client)
+	h.Presence.Disconnect(client.UserID, presence.TransportWebSocket)
+
+	for room := range client.rooms {
+		h.removeFromRoom(client, room)
+		h.publish(Envelope{Room: room, Message: Message{
+			Type:      "user_left",
+			UserID:    client.UserID,
+			Username:  client.Username,
+			Room:      room,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}})
+	}
+	client.log().Info("WebSocket client disconnected", "clients", len(h.clients))
+}
+
+// send queues a message for a client without blocking. A client whose
+// buffer is full is too slow to keep up and is disconnected. Only called
+// from Run.
+func (h *Hub) send(client *Client, message Message) bool {
+	if !h.clients[client] {
+		return false
+	}
+	select {
+	case client.Send <- message:
+		return true
+	default:
+		client.log().Warn("WebSocket client send channel full, removing")
+		h.remove(client)
+		return false
+	}
+}
+
+// deliver hands an envelope from the broker to the local clients it
+// addresses. Only called from Run.
+func (h *Hub) deliver(env Envelope) {
+	if env.Sanction != nil {
+		// The publishing hub applied it already
+		if env.Origin != h.ID {
+			h.applySanction(*env.Sanction)
+		}
+		return
+	}
+
+	targets := h.clients
+	switch {
+	case env.Room != "":
+		targets = h.rooms[env.Room]
+	case env.User != "":
+		targets = h.users[env.User]
+	}
+
+	sent := 0
+	for client := range targets {
+		if env.Origin == h.ID && client.ID == env.Exclude {
+			continue
+		}
+		if h.send(client, env.Message) {
+			sent++
+		}
+	}
+
+	if sent > 0 && isDirectDelivery(env) {
+		h.markDelivered(env.Message.ID)
+	}
+}
+
+// broadcastToOthers sends a message to every client in message.Room (or to
+// every client when the message has no room), except exclude
+func (h *Hub) broadcastToOthers(message Message, exclude *Client) {
+	env := Envelope{Room: message.Room, Message: message}
+	if exclude != nil {
+		env.Exclude = exclude.ID
+	}
+	h.publish(env)
+}
+
+// GetClientCount returns the number of clients connected to this hub
+func (h *Hub) GetClientCount() int {
+	var count int
+	h.do(func() { count = len(h.clients) })
+	return count
+}
@@ -0,0 +1,353 @@
+package websocket
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mangahub/internal/auth"
+	"mangahub/pkg/models"
+)
+
+// sqliteTime is the format timestamps are stored in
+const sqliteTime = "2006-01-02 15:04:05"
+
+// targetNotices maps moderator commands to the message sent to their target
+var targetNotices = map[string]string{
+	"mute":   "muted",
+	"unmute": "unmuted",
+	"kick":   "kicked",
+	"ban":    "banned",
+}
+
+// ModerationStore records moderation actions and deletes chat messages
+type ModerationStore interface {
+	SaveModerationAction(ctx context.Context, action models.ModerationAction) (int64, error)
+	GetSanctions(ctx context.Context) ([]models.ModerationAction, error)
+	DeleteMessage(ctx context.Context, id int64) (models.ChatMessage, error)
+}
+
+// UserLookup finds the accounts moderation commands target
+type UserLookup interface {
+	GetUserByID(ctx context.Context, id string) (models.User, error)
+}
+
+// WordFilter masks blocked words in chat messages
+type WordFilter struct {
+	pattern *regexp.Regexp
+}
+
+// NewWordFilter builds a filter matching the given words case-insensitively
+// as whole words. It returns nil, which filters nothing, when words is empty.
+func NewWordFilter(words []string) *WordFilter {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return &WordFilter{pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+// Clean replaces every blocked word in text with asterisks
+func (f *WordFilter) Clean(text string) string {
+	if f == nil {
+		return text
+	}
+	return f.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+}
+
+// LoadSanctions restores the mutes and bans recorded in the moderation
+// store. Call it before Run.
+func (h *Hub) LoadSanctions(ctx context.Context) error {
+	if h.Moderation == nil {
+		return nil
+	}
+	actions, err := h.Moderation.GetSanctions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, action := range actions {
+		h.applySanction(action)
+	}
+	return nil
+}
+
+// applySanction updates the active mutes and bans for an action. A zero
+// expiry means the sanction lasts until lifted. Only called from Run.
+func (h *Hub) applySanction(action models.ModerationAction) {
+	var until time.Time
+	if action.ExpiresAt != "" {
+		t, err := parseStoredTime(action.ExpiresAt)
+		if err != nil {
+			h.logger().Warn("Ignoring moderation action with invalid expiry", "action_id", action.ID, "expires_at", action.ExpiresAt)
+			return
+		}
+		until = t
+	}
+
+	switch action.Action {
+	case "mute":
+		h.mutes[action.TargetID] = until
+	case "unmute":
+		delete(h.mutes, action.TargetID)
+	case "ban":
+		h.bans[action.TargetID] = until
+	case "unban":
+		delete(h.bans, action.TargetID)
+	}
+}
+
+// sanctioned reports whether a sanction is active and until when
+func sanctioned(sanctions map[string]time.Time, userID string) (time.Time, bool) {
+	until, ok := sanctions[userID]
+	if !ok || (!until.IsZero() && time.Now().After(until)) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// IsBanned reports whether a user is currently banned from chat
+func (h *Hub) IsBanned(userID string) bool {
+	var banned bool
+	h.do(func() { _, banned = sanctioned(h.bans, userID) })
+	return banned
+}
+
+// mutedUntil reports whether a user is muted and until when
+func (h *Hub) mutedUntil(userID string) (until time.Time, muted bool) {
+	h.do(func() { until, muted = sanctioned(h.mutes, userID) })
+	return until, muted
+}
+
+// allow takes a token from the client's rate limit bucket
+func (c *Client) allow() bool {
+	rate, burst := c.Hub.MessageRate, float64(c.Hub.MessageBurst)
+	if rate <= 0 {
+		return true
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := time.Now()
+	if c.lastMessage.IsZero() {
+		c.tokens = burst
+	} else {
+		c.tokens += now.Sub(c.lastMessage).Seconds() * rate
+		if c.tokens > burst {
+			c.tokens = burst
+		}
+	}
+	c.lastMessage = now
+
+	if c.tokens < 1 {
+		return false
+	}
+	c.tokens--
+	return true
+}
+
+// checkContent applies mutes, the length limit and the word filter to a
+// message the client wants to send. It returns the content to deliver, or
+// sends the client an error and returns false.
+func (c *Client) checkContent(content string) (string, bool) {
+	if until, muted := c.Hub.mutedUntil(c.UserID); muted {
+		if until.IsZero() {
+			c.reply(errorMessage("You are muted"))
+		} else {
+			c.reply(errorMessage("You are muted until " + until.Format(time.RFC3339)))
+		}
+		return "", false
+	}
+	if content == "" {
+		c.reply(errorMessage("Message content is required"))
+		return "", false
+	}
+	if max := c.Hub.MaxMessageLength; max > 0 && utf8.RuneCountInString(content) > max {
+		c.reply(errorMessage(fmt.Sprintf("Message is too long (max %d characters)", max)))
+		return "", false
+	}
+	return c.Hub.Filter.Clean(content), true
+}
+
+// moderate runs a moderator command: mute, unmute, kick, ban, unban or
+// delete_message. The target user is in msg.To, the message to delete in
+// msg.ID, the reason in msg.Content and an optional duration such as "10m"
+// in msg.Data.
+func (c *Client) moderate(msg Message) {
+	if !auth.IsModerator(c.Role) {
+		c.reply(errorMessage("Insufficient permissions"))
+		return
+	}
+
+	action := models.ModerationAction{
+		Action:        msg.Type,
+		ModeratorID:   c.UserID,
+		ModeratorName: c.Username,
+		TargetID:      msg.To,
+		Reason:        msg.Content,
+	}
+
+	if msg.Type == "delete_message" {
+		if !c.deleteMessage(msg.ID) {
+			return
+		}
+		action.TargetID = ""
+		action.MessageID = msg.ID
+	} else if msg.To == "" || msg.To == c.UserID {
+		c.reply(errorMessage("Moderation commands need a target other than yourself"))
+		return
+	} else if !c.canSanction(msg.To) {
+		return
+	}
+
+	var until time.Time
+	if msg.Type == "mute" || msg.Type == "ban" {
+		duration, err := sanctionDuration(msg.Data)
+		if err != nil {
+			c.reply(errorMessage(err.Error()))
+			return
+		}
+		if duration > 0 {
+			until = time.Now().Add(duration).UTC().Truncate(time.Second)
+			action.ExpiresAt = until.Format(sqliteTime)
+		}
+	}
+
+	if c.Hub.Moderation != nil {
+		id, err := c.Hub.Moderation.SaveModerationAction(c.context(), action)
+		if err != nil {
+			c.log().Error("Failed to save moderation action", "error", err)
+		}
+		action.ID = id
+	}
+	// Apply the sanction here before answering, and on the other hubs
+	// through the broker
+	switch action.Action {
+	case "mute", "unmute", "ban", "unban":
+		c.Hub.do(func() { c.Hub.applySanction(action) })
+		c.Hub.publish(Envelope{Sanction: &action})
+	}
+	c.log().Info("Moderation action", "action", action.Action, "target_id", action.TargetID, "reason", action.Reason)
+
+	// Tell the target; kicked and banned connections are closed afterwards
+	if notice, ok := targetNotices[msg.Type]; ok {
+		target := Message{
+			Type:      notice,
+			UserID:    c.UserID,
+			Username:  c.Username,
+			To:        action.TargetID,
+			Content:   action.Reason,
+			Timestamp: time.Now().Format(time.RFC3339),
+		}
+		if !until.IsZero() {
+			target.Data = map[string]string{"until": until.Format(time.RFC3339)}
+		}
+		c.Hub.sendToUser(action.TargetID, target, nil)
+	}
+
+	c.reply(Message{
+		ID:        action.ID,
+		Type:      "moderated",
+		To:        action.TargetID,
+		Content:   msg.Type,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// canSanction reports whether the client outranks a target user, so
+// moderators cannot act on other moderators or admins. It sends the client
+// an error when not.
+func (c *Client) canSanction(userID string) bool {
+	role, err := c.targetRole(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.reply(errorMessage("Unknown user"))
+		return false
+	}
+	if err != nil {
+		c.log().Error("Failed to look up moderation target", "target_id", userID, "error", err)
+		c.reply(errorMessage("Failed to look up user"))
+		return false
+	}
+	if !auth.Outranks(c.Role, role) {
+		c.reply(errorMessage("Cannot moderate a user with an equal or higher role"))
+		return false
+	}
+	return true
+}
+
+// targetRole returns the role of a user: from Users when set, otherwise the
+// highest role of their connections to this hub
+func (c *Client) targetRole(userID string) (string, error) {
+	if c.Hub.Users != nil {
+		u, err := c.Hub.Users.GetUserByID(c.context(), userID)
+		return u.Role, err
+	}
+	role := models.RoleUser
+	c.Hub.do(func() {
+		for client := range c.Hub.users[userID] {
+			if auth.Outranks(client.Role, role) {
+				role = client.Role
+			}
+		}
+	})
+	return role, nil
+}
+
+// deleteMessage removes a stored chat message and tells its room
+func (c *Client) deleteMessage(id int64) bool {
+	if c.Hub.Moderation == nil || id <= 0 {
+		c.reply(errorMessage("Message not found"))
+		return false
+	}
+
+	deleted, err := c.Hub.Moderation.DeleteMessage(c.context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.reply(errorMessage("Message not found"))
+		return false
+	}
+	if err != nil {
+		c.log().Error("Failed to delete chat message", "message_id", id, "error", err)
+		c.reply(errorMessage("Failed to delete message"))
+		return false
+	}
+
+	c.Hub.broadcastToOthers(Message{
+		ID:        id,
+		Type:      "message_deleted",
+		Room:      deleted.Room,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil)
+	return true
+}
+
+// sanctionDuration reads the optional {"duration": "10m"} of a mute or ban
+func sanctionDuration(data interface{}) (time.Duration, error) {
+	fields, _ := data.(map[string]interface{})
+	value, _ := fields["duration"].(string)
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, errors.New("Invalid duration, use a value such as \"10m\" or \"24h\"")
+	}
+	return duration, nil
+}
+
+// closesConnection reports whether the connection is closed after sending a
+// message to the client
+func closesConnection(message Message) bool {
+	return message.Type == "kicked" || message.Type == "banned"
+}
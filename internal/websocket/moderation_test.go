@@ -0,0 +1,143 @@
+package websocket
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/pkg/models"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialWithRole(t *testing.T, url, userID, username, role string) *websocket.Conn {
+	token, err := auth.GenerateToken(models.User{ID: userID, Username: username, Role: role})
+	require.NoError(t, err)
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWordFilter(t *testing.T) {
+	filter := NewWordFilter([]string{"spoiler", " darn "})
+	assert.Equal(t, "no ******* please, **** it", filter.Clean("no SPOILER please, darn it"))
+	assert.Equal(t, "spoilers are fine", filter.Clean("spoilers are fine"))
+
+	var none *WordFilter = NewWordFilter(nil)
+	assert.Nil(t, none)
+	assert.Equal(t, "anything", none.Clean("anything"))
+}
+
+func TestHub_MessageLimits(t *testing.T) {
+	hub, url := startTestHub(t)
+	hub.MaxMessageLength = 10
+	hub.MessageRate = 1
+	hub.MessageBurst = 3
+	hub.Filter = NewWordFilter([]string{"spoiler"})
+
+	alice := dialAs(t, url, "u1", "alice")
+	require.NoError(t, alice.WriteJSON(Message{Type: "join_room", Room: "lobby"}))
+	readMessage(t, alice, "room_joined")
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "chat", Room: "lobby", Content: "way too long message"}))
+	assert.Contains(t, readMessage(t, alice, "error").Content, "too long")
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "chat", Room: "lobby", Content: "a spoiler"}))
+	assert.Equal(t, "a *******", readMessage(t, alice, "chat").Content)
+
+	// The burst of 3 is used up by now
+	require.NoError(t, alice.WriteJSON(Message{Type: "chat", Room: "lobby", Content: "hi"}))
+	readMessage(t, alice, "chat")
+	require.NoError(t, alice.WriteJSON(Message{Type: "chat", Room: "lobby", Content: "hi"}))
+	assert.Equal(t, "You are sending messages too fast", readMessage(t, alice, "error").Content)
+}
+
+func TestHub_Moderation(t *testing.T) {
+	hub, url := startTestHub(t)
+
+	mod := dialWithRole(t, url, "m1", "mod", models.RoleModerator)
+	alice := dialAs(t, url, "u1", "alice")
+	bob := dialAs(t, url, "u2", "bob")
+	assert.Eventually(t, func() bool { return hub.IsOnline("u1") && hub.IsOnline("u2") }, time.Second, 10*time.Millisecond)
+
+	// Regular users cannot moderate
+	require.NoError(t, alice.WriteJSON(Message{Type: "kick", To: "u2"}))
+	assert.Equal(t, "Insufficient permissions", readMessage(t, alice, "error").Content)
+
+	require.NoError(t, mod.WriteJSON(Message{Type: "mute", To: "u1", Content: "spam", Data: map[string]string{"duration": "1h"}}))
+	assert.Equal(t, "mute", readMessage(t, mod, "moderated").Content)
+	assert.Equal(t, "spam", readMessage(t, alice, "muted").Content)
+	require.NoError(t, alice.WriteJSON(Message{Type: "dm", To: "u2", Content: "hello"}))
+	assert.Contains(t, readMessage(t, alice, "error").Content, "You are muted until")
+
+	require.NoError(t, mod.WriteJSON(Message{Type: "kick", To: "u2"}))
+	assert.Equal(t, "kick", readMessage(t, mod, "moderated").Content)
+	readMessage(t, bob, "kicked")
+	bob.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := bob.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "unexpected error %v", err)
+
+	require.NoError(t, mod.WriteJSON(Message{Type: "ban", To: "u1"}))
+	assert.Equal(t, "ban", readMessage(t, mod, "moderated").Content)
+	readMessage(t, alice, "banned")
+	assert.True(t, hub.IsBanned("u1"))
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?token="+testToken(t, "u1", "alice"), nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	require.NoError(t, mod.WriteJSON(Message{Type: "unban", To: "u1"}))
+	assert.Equal(t, "unban", readMessage(t, mod, "moderated").Content)
+	assert.False(t, hub.IsBanned("u1"))
+}
+
+// userLookup is a UserLookup for tests
+type userLookup map[string]models.User
+
+func (l userLookup) GetUserByID(_ context.Context, id string) (models.User, error) {
+	u, ok := l[id]
+	if !ok {
+		return models.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func TestHub_ModerationRespectsRoles(t *testing.T) {
+	hub, url := startTestHub(t)
+
+	mod := dialWithRole(t, url, "m1", "mod", models.RoleModerator)
+	admin := dialWithRole(t, url, "a1", "admin", models.RoleAdmin)
+	dialWithRole(t, url, "m2", "other mod", models.RoleModerator)
+	assert.Eventually(t, func() bool { return hub.IsOnline("a1") && hub.IsOnline("m2") }, time.Second, 10*time.Millisecond)
+
+	// Without a user store the roles of connected targets are used
+	for _, action := range []string{"mute", "kick", "ban"} {
+		require.NoError(t, mod.WriteJSON(Message{Type: action, To: "a1"}))
+		assert.Equal(t, "Cannot moderate a user with an equal or higher role", readMessage(t, mod, "error").Content, action)
+	}
+	require.NoError(t, mod.WriteJSON(Message{Type: "mute", To: "m2"}))
+	readMessage(t, mod, "error")
+	assert.False(t, hub.IsBanned("a1"))
+
+	require.NoError(t, admin.WriteJSON(Message{Type: "mute", To: "m2"}))
+	assert.Equal(t, "mute", readMessage(t, admin, "moderated").Content)
+
+	// The user store knows the roles of offline users too
+	hub.Users = userLookup{
+		"a2": {ID: "a2", Role: models.RoleAdmin},
+		"u9": {ID: "u9", Role: models.RoleUser},
+	}
+	require.NoError(t, mod.WriteJSON(Message{Type: "ban", To: "a2"}))
+	readMessage(t, mod, "error")
+	assert.False(t, hub.IsBanned("a2"))
+	require.NoError(t, mod.WriteJSON(Message{Type: "ban", To: "nobody"}))
+	assert.Equal(t, "Unknown user", readMessage(t, mod, "error").Content)
+	require.NoError(t, mod.WriteJSON(Message{Type: "ban", To: "u9"}))
+	assert.Equal(t, "ban", readMessage(t, mod, "moderated").Content)
+	assert.True(t, hub.IsBanned("u9"))
+}
@@ -0,0 +1,294 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"mangahub/internal/logging"
+)
+
+const (
+	// redisTimeout bounds dialing and each publish round trip
+	redisTimeout = 5 * time.Second
+	// maxRedisBulk bounds the size of a single reply we accept
+	maxRedisBulk = 16 << 20
+	// maxRedisBackoff caps the delay between resubscription attempts
+	maxRedisBackoff = 30 * time.Second
+)
+
+// RedisBroker shares envelopes between server instances through the pub/sub
+// channel of a Redis server, or anything else speaking the Redis protocol
+// (RESP). Publishing reuses one connection; each subscription holds its own
+// and reconnects with backoff when it drops.
+type RedisBroker struct {
+	// Logger logs lost subscriptions; nil uses the default logger
+	Logger *slog.Logger
+
+	addr    string
+	channel string
+
+	conn   net.Conn
+	reader *bufio.Reader
+	mutex  sync.Mutex
+}
+
+// NewRedisBroker creates a broker using channel on the server at addr.
+// Connections are opened on first use.
+func NewRedisBroker(addr, channel string) *RedisBroker {
+	return &RedisBroker{addr: addr, channel: channel}
+}
+
+// Publish sends an envelope to every subscriber, retrying once on a fresh
+// connection if the current one has failed
+func (b *RedisBroker) Publish(env Envelope) error {
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.conn == nil {
+			conn, dialErr := net.DialTimeout("tcp", b.addr, redisTimeout)
+			if dialErr != nil {
+				return dialErr
+			}
+			b.conn, b.reader = conn, bufio.NewReader(conn)
+		}
+
+		b.conn.SetDeadline(time.Now().Add(redisTimeout))
+		if err = writeCommand(b.conn, "PUBLISH", b.channel, string(payload)); err == nil {
+			if _, err = readRESP(b.reader); err == nil {
+				return nil
+			}
+		}
+		b.conn.Close()
+		b.conn = nil
+	}
+	return err
+}
+
+// Close closes the publishing connection. Subscriptions are closed by the
+// function Subscribe returned.
+func (b *RedisBroker) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.conn == nil {
+		return nil
+	}
+	err := b.conn.Close()
+	b.conn = nil
+	return err
+}
+
+// redisSubscription is the current SUBSCRIBE connection of a subscriber
+type redisSubscription struct {
+	conn  net.Conn
+	stop  chan struct{}
+	once  sync.Once
+	mutex sync.Mutex
+}
+
+func (s *redisSubscription) close() {
+	s.once.Do(func() {
+		close(s.stop)
+		s.mutex.Lock()
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		s.mutex.Unlock()
+	})
+}
+
+// wait sleeps for d and reports false if the subscription was closed meanwhile
+func (s *redisSubscription) wait(d time.Duration) bool {
+	select {
+	case <-s.stop:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// Subscribe calls handler from a background goroutine for every envelope
+// received. The server does not need to be up yet: the subscriber keeps
+// (re)connecting with backoff until unsubscribed.
+func (b *RedisBroker) Subscribe(handler func(Envelope)) (func(), error) {
+	s := &redisSubscription{stop: make(chan struct{})}
+	go b.receive(s, handler)
+	return s.close, nil
+}
+
+// subscribe opens a connection and waits for the SUBSCRIBE confirmation
+func (b *RedisBroker) subscribe() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", b.addr, redisTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := bufio.NewReader(conn)
+
+	conn.SetDeadline(time.Now().Add(redisTimeout))
+	if err := writeCommand(conn, "SUBSCRIBE", b.channel); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	reply, err := readRESP(reader)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if items, ok := reply.([]interface{}); !ok || len(items) != 3 || items[0] != "subscribe" {
+		conn.Close()
+		return nil, nil, fmt.Errorf("redis: unexpected SUBSCRIBE reply %v", reply)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, reader, nil
+}
+
+// receive subscribes and reads published envelopes until the subscription
+// is closed, resubscribing whenever the connection fails
+func (b *RedisBroker) receive(s *redisSubscription, handler func(Envelope)) {
+	backoff := time.Second
+	for {
+		conn, reader, err := b.subscribe()
+		if err != nil {
+			logging.Or(b.Logger).Warn("Redis broker subscribe failed", "addr", b.addr, "error", err)
+			if !s.wait(backoff) {
+				return
+			}
+			backoff *= 2
+			if backoff > maxRedisBackoff {
+				backoff = maxRedisBackoff
+			}
+			continue
+		}
+
+		s.mutex.Lock()
+		select {
+		case <-s.stop:
+			s.mutex.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		s.conn = conn
+		s.mutex.Unlock()
+		backoff = time.Second
+
+		err = b.readMessages(reader, handler)
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		logging.Or(b.Logger).Warn("Redis broker subscription lost", "addr", b.addr, "error", err)
+		if !s.wait(backoff) {
+			return
+		}
+	}
+}
+
+// readMessages passes the envelopes published on the broker's channel to
+// handler until reading fails
+func (b *RedisBroker) readMessages(reader *bufio.Reader, handler func(Envelope)) error {
+	for {
+		reply, err := readRESP(reader)
+		if err != nil {
+			return err
+		}
+
+		items, ok := reply.([]interface{})
+		if !ok || len(items) != 3 || items[0] != "message" || items[1] != b.channel {
+			continue
+		}
+		payload, _ := items[2].(string)
+
+		var env Envelope
+		if err := json.Unmarshal([]byte(payload), &env); err != nil {
+			logging.Or(b.Logger).Warn("Redis broker dropped malformed envelope", "error", err)
+			continue
+		}
+		handler(env)
+	}
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// writeCommand sends a command as a RESP array of bulk strings
+func writeCommand(w io.Writer, args ...string) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readRESP reads one RESP value: simple and bulk strings become strings,
+// integers int64, arrays []interface{} and nulls nil. Error replies are
+// returned as a redisError.
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n > maxRedisBulk {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n > maxRedisBulk {
+			return nil, fmt.Errorf("redis: invalid array length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readRESP(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,183 @@
+package websocket
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"mangahub/pkg/models"
+)
+
+// maxRoomNameLength bounds custom room names
+const maxRoomNameLength = 64
+
+// Member is a user present in a room
+type Member struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// RoomInfo summarizes an active room
+type RoomInfo struct {
+	Room      string `json:"room"`
+	Occupants int    `json:"occupants"`
+}
+
+// MangaRoom returns the chat room of a manga
+func MangaRoom(mangaID string) string {
+	return "manga:" + mangaID
+}
+
+// validRoomName accepts letters, digits and - _ . : up to maxRoomNameLength
+func validRoomName(room string) bool {
+	if room == "" || len(room) > maxRoomNameLength {
+		return false
+	}
+	for _, r := range room {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// joinRoom adds a client to a room and reports whether it was not a member yet
+func (h *Hub) joinRoom(client *Client, room string) bool {
+	joined := false
+	h.do(func() {
+		if !h.clients[client] || client.rooms[room] {
+			return
+		}
+		if h.rooms[room] == nil {
+			h.rooms[room] = make(map[*Client]bool)
+		}
+		h.rooms[room][client] = true
+		if client.rooms == nil {
+			client.rooms = make(map[string]bool)
+		}
+		client.rooms[room] = true
+		joined = true
+	})
+	return joined
+}
+
+// leaveRoom removes a client from a room and reports whether it was a member
+func (h *Hub) leaveRoom(client *Client, room string) bool {
+	left := false
+	h.do(func() {
+		if client.rooms[room] {
+			h.removeFromRoom(client, room)
+			left = true
+		}
+	})
+	return left
+}
+
+// removeFromRoom drops a client from a room, deleting the room once empty.
+// Only called from Run.
+func (h *Hub) removeFromRoom(client *Client, room string) {
+	delete(client.rooms, room)
+	delete(h.rooms[room], client)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// inRoom reports whether a client is a member of a room
+func (h *Hub) inRoom(client *Client, room string) bool {
+	var member bool
+	h.do(func() { member = client.rooms[room] })
+	return member
+}
+
+// RoomMembers returns the users in a room on this hub, one entry per user
+// even when they have several connections
+func (h *Hub) RoomMembers(room string) []Member {
+	var members []Member
+	h.do(func() { members = h.roomMembers(room) })
+	if members == nil {
+		members = []Member{}
+	}
+	return members
+}
+
+// roomMembers lists a room's users. Only called from Run.
+func (h *Hub) roomMembers(room string) []Member {
+	seen := make(map[string]bool)
+	members := make([]Member, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		if seen[client.UserID] {
+			continue
+		}
+		seen[client.UserID] = true
+		members = append(members, Member{UserID: client.UserID, Username: client.Username})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Username < members[j].Username })
+	return members
+}
+
+// Rooms lists the active rooms on this hub and how many users are in each
+func (h *Hub) Rooms() []RoomInfo {
+	rooms := []RoomInfo{}
+	h.do(func() {
+		for room := range h.rooms {
+			if occupants := len(h.roomMembers(room)); occupants > 0 {
+				rooms = append(rooms, RoomInfo{Room: room, Occupants: occupants})
+			}
+		}
+	})
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Room < rooms[j].Room })
+	return rooms
+}
+
+// history returns the stored messages replayed to a client joining a room
+func (h *Hub) history(ctx context.Context, room string) []Message {
+	if h.Store == nil || h.HistorySize <= 0 {
+		return nil
+	}
+
+	stored, err := h.Store.GetRecentMessages(ctx, room, h.HistorySize)
+	if err != nil {
+		h.logger().ErrorContext(ctx, "Failed to load chat history", "room", room, "error", err)
+		return nil
+	}
+
+	messages := make([]Message, 0, len(stored))
+	for _, m := range stored {
+		messages = append(messages, chatMessage(m))
+	}
+	return messages
+}
+
+// chatMessage converts a stored message into its WebSocket form
+func chatMessage(m models.ChatMessage) Message {
+	return Message{
+		ID:        m.ID,
+		Type:      "chat",
+		UserID:    m.UserID,
+		Username:  m.Username,
+		Room:      m.Room,
+		Content:   m.Content,
+		Timestamp: storedTimestamp(m.CreatedAt),
+	}
+}
+
+// storedTimestamp converts a SQLite timestamp to RFC 3339
+func storedTimestamp(createdAt string) string {
+	if t, err := parseStoredTime(createdAt); err == nil {
+		return t.Format(time.RFC3339)
+	}
+	return createdAt
+}
+
+// parseStoredTime parses a timestamp read back from SQLite, which depending
+// on the column type comes back as written or converted to RFC 3339
+func parseStoredTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(sqliteTime, value)
+}
@@ -1,25 +1,41 @@
 package websocket
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
+	"mangahub/internal/auth"
+	"mangahub/internal/logging"
+	"mangahub/internal/ratelimit"
+	"mangahub/pkg/models"
+
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins in development
-	},
+// bearerProtocol is the subprotocol browsers use to pass a token, since they
+// cannot set headers on WebSocket requests: the client offers
+// ["bearer", "<token>"] and the server selects "bearer".
+const bearerProtocol = "bearer"
+
+// MessageStore persists chat messages so they can be replayed to late joiners
+type MessageStore interface {
+	SaveMessage(ctx context.Context, msg models.ChatMessage) (int64, error)
+	GetRecentMessages(ctx context.Context, room string, limit int) ([]models.ChatMessage, error)
 }
 
 // Message represents a WebSocket message
 type Message struct {
+	ID        int64       `json:"id,omitempty"`
 	Type      string      `json:"type"`
 	UserID    string      `json:"user_id,omitempty"`
 	Username  string      `json:"username,omitempty"`
+	Room      string      `json:"room,omitempty"`
+	To        string      `json:"to,omitempty"`
 	Content   string      `json:"content,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
 	Timestamp string      `json:"timestamp"`
@@ -31,137 +47,135 @@ type Client struct {
 	Conn     *websocket.Conn
 	UserID   string
 	Username string
+	Role     string
 	Send     chan Message
 	Hub      *Hub
+	rooms    map[string]bool
+	// logger names the connection and the request that opened it
+	logger *slog.Logger
+	// ctx carries the ID of the request that opened the connection to the
+	// stores
+	ctx context.Context
+
+	// Rate limit bucket, only touched by readPump
+	tokens      float64
+	lastMessage time.Time
 }
 
-// Hub maintains the set of active clients and broadcasts messages
-type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan Message
-	register   chan *Client
-	unregister chan *Client
-	mutex      sync.RWMutex
+// log returns the logger of the connection
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return c.Hub.logger().With("client_id", c.ID, "user_id", c.UserID)
+	}
+	return c.logger
 }
 
-// NewHub creates a new Hub
-func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan Message),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+// context returns the context the connection's store calls run with
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
 	}
+	return c.ctx
 }
 
-// Run starts the hub
-func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.mutex.Lock()
-			h.clients[client] = true
-			h.mutex.Unlock()
-			log.Printf("WebSocket client connected: %s (Total: %d)", client.ID, len(h.clients))
-
-			// Notify others about new user
-			welcomeMsg := Message{
-				Type:      "user_joined",
-				UserID:    client.UserID,
-				Username:  client.Username,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			h.broadcastToOthers(welcomeMsg, client)
+// reply queues a message for this client
+func (c *Client) reply(message Message) {
+	c.Hub.run(func() { c.Hub.send(c, message) })
+}
 
-		case client := <-h.unregister:
-			h.mutex.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.Send)
-			}
-			h.mutex.Unlock()
-			log.Printf("WebSocket client disconnected: %s (Total: %d)", client.ID, len(h.clients))
-
-			// Notify others about user leaving
-			leaveMsg := Message{
-				Type:      "user_left",
-				UserID:    client.UserID,
-				Username:  client.Username,
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			h.broadcastToOthers(leaveMsg, nil)
+// OriginChecker returns a CheckOrigin function accepting requests without
+// an Origin header (non-browser clients) and the origins allowed by allows,
+// such as the CORS policy of the HTTP API
+func OriginChecker(allows func(origin string) bool) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || allows(origin)
+	}
+}
 
-		case message := <-h.broadcast:
-			h.mutex.RLock()
-			clients := make([]*Client, 0, len(h.clients))
-			for client := range h.clients {
-				clients = append(clients, client)
-			}
-			h.mutex.RUnlock()
-
-			// Broadcast to all clients with error handling
-			for _, client := range clients {
-				select {
-				case client.Send <- message:
-				default:
-					// Channel full, remove client
-					log.Printf("WebSocket client %s send channel full during broadcast, removing", client.ID)
-					h.mutex.Lock()
-					if _, exists := h.clients[client]; exists {
-						delete(h.clients, client)
-						close(client.Send)
-					}
-					h.mutex.Unlock()
-				}
-			}
-		}
+// clientIP returns the IP recorded by the HTTP router, or the address the
+// request came from
+func clientIP(r *http.Request) string {
+	if ip, ok := ratelimit.ClientIP(r.Context()); ok {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
+	return host
 }
 
-func (h *Hub) broadcastToOthers(message Message, exclude *Client) {
-	h.mutex.RLock()
-	clients := make([]*Client, 0, len(h.clients))
-	for client := range h.clients {
-		if exclude == nil || client != exclude {
-			clients = append(clients, client)
-		}
+// tokenFromRequest extracts the JWT from the Authorization header, the
+// Sec-WebSocket-Protocol header or the token query parameter
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
 	}
-	h.mutex.RUnlock()
 
-	// Send to clients with error handling
-	for _, client := range clients {
-		select {
-		case client.Send <- message:
-		default:
-			// Channel full or client disconnected
-			log.Printf("WebSocket client %s send channel full, removing", client.ID)
-			h.mutex.Lock()
-			if _, exists := h.clients[client]; exists {
-				delete(h.clients, client)
-				close(client.Send)
-			}
-			h.mutex.Unlock()
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == bearerProtocol && i+1 < len(protocols) {
+			return protocols[i+1]
 		}
 	}
+
+	return r.URL.Query().Get("token")
 }
 
-// HandleWebSocket handles WebSocket connections
+// HandleWebSocket authenticates the request with a JWT and upgrades it. The
+// client's identity always comes from the token. IPs opening connections
+// faster than the hub's ConnectionLimiter allows are turned away first.
 func HandleWebSocket(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	if result := hub.ConnectionLimiter.Allow(r.Context(), clientIP(r)); !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(result.RetryAfterSeconds()))
+		http.Error(w, "Too many connections, retry later", http.StatusTooManyRequests)
+		return
+	}
+
+	claims, err := auth.ParseTokenClaims(tokenFromRequest(r))
+	if err != nil || claims.UserID == "" {
+		http.Error(w, "Invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+	if hub.IsBanned(claims.UserID) {
+		http.Error(w, "You are banned from chat", http.StatusForbidden)
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  hub.CheckOrigin,
+		Subprotocols: []string{bearerProtocol},
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+		hub.logger().InfoContext(r.Context(), "WebSocket upgrade failed", "error", err)
 		return
 	}
 
 	clientID := r.RemoteAddr
 	client := &Client{
-		ID:   clientID,
-		Conn: conn,
-		Send: make(chan Message, 256),
-		Hub:  hub,
+		ID:       clientID,
+		Conn:     conn,
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Role:     claims.Role,
+		Send:     make(chan Message, 256),
+		Hub:      hub,
+		// The connection logs with the ID of the upgrade request, so its
+		// lines can be matched with the access log
+		logger: hub.logger().With("client_id", clientID, "user_id", claims.UserID,
+			"request_id", logging.RequestID(r.Context())),
+		ctx: logging.WithRequestID(context.Background(), logging.RequestID(r.Context())),
 	}
 
-	hub.register <- client
+	select {
+	case hub.register <- client:
+	case <-hub.done:
+		conn.Close()
+		return
+	}
+	hub.deliverPending(client)
 
 	go client.writePump()
 	go client.readPump()
@@ -169,7 +183,10 @@ func HandleWebSocket(hub *Hub, w http.ResponseWriter, r *http.Request) {
 
 func (c *Client) readPump() {
 	defer func() {
-		c.Hub.unregister <- c
+		select {
+		case c.Hub.unregister <- c:
+		case <-c.Hub.done:
+		}
 		c.Conn.Close()
 	}()
 
@@ -184,60 +201,160 @@ func (c *Client) readPump() {
 		err := c.Conn.ReadJSON(&msg)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				c.log().Warn("WebSocket connection closed unexpectedly", "error", err)
 			}
 			break
 		}
 
 		msg.Timestamp = time.Now().Format(time.RFC3339)
 
+		switch msg.Type {
+		case "chat", "dm", "typing":
+			if !c.allow() {
+				c.reply(errorMessage("You are sending messages too fast"))
+				continue
+			}
+		}
+
 		switch msg.Type {
 		case "register":
-			c.UserID = msg.UserID
-			c.Username = msg.Username
+			// Identity comes from the token; claimed IDs are ignored and the
+			// reply only confirms who the connection is authenticated as
 			response := Message{
 				Type:      "registered",
 				UserID:    c.UserID,
 				Username:  c.Username,
 				Timestamp: time.Now().Format(time.RFC3339),
 			}
-			c.Send <- response
+			c.reply(response)
+
+		case "join_room":
+			if !validRoomName(msg.Room) {
+				c.reply(errorMessage("Invalid room name"))
+				continue
+			}
+			if c.Hub.joinRoom(c, msg.Room) {
+				joinedMsg := Message{
+					Type:      "user_joined",
+					UserID:    c.UserID,
+					Username:  c.Username,
+					Room:      msg.Room,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.Hub.broadcastToOthers(joinedMsg, c)
+			}
+			c.reply(Message{
+				Type:      "room_joined",
+				Room:      msg.Room,
+				Data:      c.Hub.RoomMembers(msg.Room),
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+			if history := c.Hub.history(c.context(), msg.Room); len(history) > 0 {
+				c.reply(Message{
+					Type:      "history",
+					Room:      msg.Room,
+					Data:      history,
+					Timestamp: time.Now().Format(time.RFC3339),
+				})
+			}
+
+		case "leave_room":
+			if c.Hub.leaveRoom(c, msg.Room) {
+				leftMsg := Message{
+					Type:      "user_left",
+					UserID:    c.UserID,
+					Username:  c.Username,
+					Room:      msg.Room,
+					Timestamp: time.Now().Format(time.RFC3339),
+				}
+				c.Hub.broadcastToOthers(leftMsg, nil)
+			}
+			c.reply(Message{
+				Type:      "room_left",
+				Room:      msg.Room,
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
+
+		case "room_members":
+			c.reply(Message{
+				Type:      "room_members",
+				Room:      msg.Room,
+				Data:      c.Hub.RoomMembers(msg.Room),
+				Timestamp: time.Now().Format(time.RFC3339),
+			})
 
 		case "chat":
-			// Broadcast chat message to all clients
+			if !c.Hub.inRoom(c, msg.Room) {
+				c.reply(errorMessage("Join the room before sending messages"))
+				continue
+			}
+			content, ok := c.checkContent(msg.Content)
+			if !ok {
+				continue
+			}
+			// Store and broadcast chat message to the room
 			broadcastMsg := Message{
 				Type:      "chat",
 				UserID:    c.UserID,
 				Username:  c.Username,
-				Content:   msg.Content,
+				Room:      msg.Room,
+				Content:   content,
 				Timestamp: time.Now().Format(time.RFC3339),
 			}
-			c.Hub.broadcast <- broadcastMsg
+			if c.Hub.Store != nil {
+				id, err := c.Hub.Store.SaveMessage(c.context(), models.ChatMessage{
+					Room:     broadcastMsg.Room,
+					UserID:   broadcastMsg.UserID,
+					Username: broadcastMsg.Username,
+					Content:  broadcastMsg.Content,
+				})
+				if err != nil {
+					c.log().Error("Failed to save chat message", "room", broadcastMsg.Room, "error", err)
+				}
+				broadcastMsg.ID = id
+			}
+			c.Hub.broadcastToOthers(broadcastMsg, nil)
+
+		case "dm":
+			c.sendDirect(msg)
+
+		case "dm_read":
+			c.markRead(msg)
+
+		case "typing":
+			c.typing(msg)
+
+		case "mute", "unmute", "kick", "ban", "unban", "delete_message":
+			c.moderate(msg)
 
 		case "ping":
 			response := Message{
 				Type:      "pong",
 				Timestamp: time.Now().Format(time.RFC3339),
 			}
-			c.Send <- response
+			c.reply(response)
 
 		default:
-			response := Message{
-				Type:      "error",
-				Content:   "Unknown message type",
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			c.Send <- response
+			c.reply(errorMessage("Unknown message type"))
 		}
 	}
 }
 
+// errorMessage builds an error reply for the client
+func errorMessage(content string) Message {
+	return Message{
+		Type:      "error",
+		Content:   content,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
 func (c *Client) writePump() {
 	ticker := time.NewTicker(54 * time.Second)
 	defer func() {
 		ticker.Stop()
 		if err := c.Conn.Close(); err != nil {
-			log.Printf("Error closing WebSocket connection for %s: %v", c.ID, err)
+			c.log().Warn("Failed to close WebSocket connection", "error", err)
 		}
 	}()
 
@@ -253,25 +370,22 @@ func (c *Client) writePump() {
 
 			if err := c.Conn.WriteJSON(message); err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("WebSocket write error for %s: %v", c.ID, err)
+					c.log().Warn("WebSocket write failed", "error", err)
 				}
 				return
 			}
+			if closesConnection(message) {
+				c.Conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, message.Content))
+				return
+			}
 
 		case <-ticker.C:
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Printf("WebSocket ping error for %s: %v", c.ID, err)
+				c.log().Warn("WebSocket ping failed", "error", err)
 				return
 			}
 		}
 	}
 }
-
-// GetClientCount returns the number of connected clients
-func (h *Hub) GetClientCount() int {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-	return len(h.clients)
-}
-
@@ -0,0 +1,249 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mangahub/internal/auth"
+	"mangahub/internal/ratelimit"
+	"mangahub/pkg/models"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestHub(t *testing.T) (*Hub, string) {
+	hub := NewHub()
+	hub.CheckOrigin = OriginChecker(func(origin string) bool { return origin == "http://localhost:3000" })
+	go hub.Run()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWebSocket(hub, w, r)
+	}))
+	t.Cleanup(server.Close)
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func testToken(t *testing.T, userID, username string) string {
+	token, err := auth.GenerateToken(models.User{ID: userID, Username: username})
+	require.NoError(t, err)
+	return token
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn, msgType string) Message {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg Message
+		require.NoError(t, conn.ReadJSON(&msg))
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestHandleWebSocket_RequiresToken(t *testing.T) {
+	_, url := startTestHub(t)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	_, resp, err = websocket.DefaultDialer.Dial(url+"?token=garbage", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandleWebSocket_IdentityComesFromToken(t *testing.T) {
+	_, url := startTestHub(t)
+	token := testToken(t, "u1", "alice")
+
+	// Token via query parameter
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(Message{Type: "register", UserID: "u2", Username: "mallory"}))
+	registered := readMessage(t, conn, "registered")
+	assert.Equal(t, "u1", registered.UserID)
+	assert.Equal(t, "alice", registered.Username)
+
+	// Token via Authorization header
+	header := http.Header{"Authorization": {"Bearer " + token}}
+	conn2, _, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	conn2.Close()
+
+	// Token via subprotocol, as browsers do
+	dialer := websocket.Dialer{Subprotocols: []string{"bearer", token}}
+	conn3, resp, err := dialer.Dial(url, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bearer", resp.Header.Get("Sec-WebSocket-Protocol"))
+	conn3.Close()
+}
+
+func TestHandleWebSocket_ChecksOrigin(t *testing.T) {
+	_, url := startTestHub(t)
+	token := testToken(t, "u1", "alice")
+
+	header := http.Header{"Origin": {"http://evil.example"}}
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?token="+token, header)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header = http.Header{"Origin": {"http://localhost:3000"}}
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, header)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestHandleWebSocket_LimitsConnections(t *testing.T) {
+	hub := NewHub()
+	hub.ConnectionLimiter = &ratelimit.Limiter{
+		Store: ratelimit.NewMemoryStore(),
+		Name:  "connection",
+		Limit: ratelimit.Limit{Rate: 0.01, Burst: 2},
+	}
+	go hub.Run()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWebSocket(hub, w, r)
+	}))
+	t.Cleanup(server.Close)
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	dialAs(t, url, "u1", "alice")
+	dialAs(t, url, "u2", "bob")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?token="+testToken(t, "u3", "carol"), nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func dialAs(t *testing.T, url, userID, username string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+testToken(t, userID, username), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHub_RoomScopedMessages(t *testing.T) {
+	hub, url := startTestHub(t)
+
+	alice := dialAs(t, url, "u1", "alice")
+	bob := dialAs(t, url, "u2", "bob")
+	carol := dialAs(t, url, "u3", "carol")
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "join_room", Room: "manga:naruto"}))
+	readMessage(t, alice, "room_joined")
+	require.NoError(t, carol.WriteJSON(Message{Type: "join_room", Room: "lobby"}))
+	readMessage(t, carol, "room_joined")
+
+	require.NoError(t, bob.WriteJSON(Message{Type: "join_room", Room: "manga:naruto"}))
+	joined := readMessage(t, bob, "room_joined")
+	assert.Len(t, joined.Data, 2)
+
+	// Only the other room member hears about bob
+	joinedEvent := readMessage(t, alice, "user_joined")
+	assert.Equal(t, "bob", joinedEvent.Username)
+	assert.Equal(t, "manga:naruto", joinedEvent.Room)
+
+	require.NoError(t, bob.WriteJSON(Message{Type: "chat", Room: "manga:naruto", Content: "hello"}))
+	chat := readMessage(t, alice, "chat")
+	assert.Equal(t, "hello", chat.Content)
+	assert.Equal(t, "manga:naruto", chat.Room)
+
+	// Chatting in a room you have not joined is refused
+	require.NoError(t, carol.WriteJSON(Message{Type: "chat", Room: "manga:naruto", Content: "hi"}))
+	assert.Equal(t, "Join the room before sending messages", readMessage(t, carol, "error").Content)
+
+	assert.Equal(t, []RoomInfo{{Room: "lobby", Occupants: 1}, {Room: "manga:naruto", Occupants: 2}}, hub.Rooms())
+
+	bob.Close()
+	left := readMessage(t, alice, "user_left")
+	assert.Equal(t, "bob", left.Username)
+	assert.Eventually(t, func() bool { return len(hub.RoomMembers("manga:naruto")) == 1 }, time.Second, 10*time.Millisecond)
+}
+
+// memoryDirectStore is a DirectMessageStore for tests
+type memoryDirectStore struct {
+	mutex     sync.Mutex
+	messages  []models.DirectMessage
+	delivered map[int64]bool
+}
+
+func (s *memoryDirectStore) SaveDirectMessage(_ context.Context, msg models.DirectMessage) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	msg.ID = int64(len(s.messages) + 1)
+	s.messages = append(s.messages, msg)
+	return msg.ID, nil
+}
+
+func (s *memoryDirectStore) GetUndelivered(_ context.Context, userID string) ([]models.DirectMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var pending []models.DirectMessage
+	for _, m := range s.messages {
+		if m.RecipientID == userID && !s.delivered[m.ID] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+func (s *memoryDirectStore) MarkDelivered(_ context.Context, ids []int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		s.delivered[id] = true
+	}
+	return nil
+}
+
+func (s *memoryDirectStore) MarkRead(_ context.Context, userID, peerID string, upToID int64) (int64, error) {
+	return 0, nil
+}
+
+func TestHub_DirectMessages(t *testing.T) {
+	hub, url := startTestHub(t)
+	hub.DirectStore = &memoryDirectStore{delivered: make(map[int64]bool)}
+
+	alice := dialAs(t, url, "u1", "alice")
+	aliceTablet := dialAs(t, url, "u1", "alice")
+	bob := dialAs(t, url, "u2", "bob")
+	assert.Eventually(t, func() bool { return hub.IsOnline("u1") && hub.IsOnline("u2") }, time.Second, 10*time.Millisecond)
+
+	// Delivered to every connection of the recipient and echoed to the sender's
+	require.NoError(t, bob.WriteJSON(Message{Type: "typing", To: "u1"}))
+	assert.Equal(t, "bob", readMessage(t, alice, "typing").Username)
+
+	require.NoError(t, bob.WriteJSON(Message{Type: "dm", To: "u1", Content: "psst"}))
+	dm := readMessage(t, alice, "dm")
+	assert.Equal(t, "psst", dm.Content)
+	assert.Equal(t, "u2", dm.UserID)
+	assert.Equal(t, int64(1), dm.ID)
+	assert.Equal(t, "psst", readMessage(t, aliceTablet, "dm").Content)
+	assert.Equal(t, int64(1), readMessage(t, bob, "dm").ID)
+
+	require.NoError(t, alice.WriteJSON(Message{Type: "dm_read", To: "u2", ID: dm.ID}))
+	receipt := readMessage(t, bob, "dm_read")
+	assert.Equal(t, "u1", receipt.UserID)
+	assert.Equal(t, dm.ID, receipt.ID)
+
+	// Messages to an offline user wait for their next connection
+	require.NoError(t, alice.WriteJSON(Message{Type: "dm", To: "u3", Content: "see you later"}))
+	readMessage(t, alice, "dm")
+	carol := dialAs(t, url, "u3", "carol")
+	pending := readMessage(t, carol, "dm")
+	assert.Equal(t, "see you later", pending.Content)
+	assert.Equal(t, "alice", pending.Username)
+
+	require.NoError(t, carol.WriteJSON(Message{Type: "dm", To: "u3", Content: "me"}))
+	assert.Contains(t, readMessage(t, carol, "error").Content, "other than yourself")
+}
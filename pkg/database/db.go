@@ -8,7 +8,7 @@ import (
 )
 
 func ConnectDB() *sql.DB {
-	db, err := sql.Open("sqlite", "./mangahub.db")
+	db, err := sql.Open("sqlite", "./mangahub.db?_pragma=foreign_keys(1)")
 	if err != nil {
 		log.Fatal("Failed to connect to DB:", err)
 	}
@@ -19,6 +19,8 @@ func ConnectDB() *sql.DB {
 		id TEXT PRIMARY KEY,
 		username TEXT UNIQUE NOT NULL,
 		email TEXT UNIQUE,
+		role TEXT NOT NULL DEFAULT 'user',
+		presence_visibility TEXT NOT NULL DEFAULT 'public',
 		password_hash TEXT NOT NULL,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);`
@@ -30,6 +32,8 @@ func ConnectDB() *sql.DB {
 	// Migration: Add email column if it doesn't exist
 	// We ignore the error here because if the column already exists, it will fail, which is fine.
 	db.Exec("ALTER TABLE users ADD COLUMN email TEXT UNIQUE;")
+	db.Exec("ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';")
+	db.Exec("ALTER TABLE users ADD COLUMN presence_visibility TEXT NOT NULL DEFAULT 'public';")
 
 	// Create manga table
 	createMangaTable := `
@@ -83,5 +87,74 @@ func ConnectDB() *sql.DB {
 		log.Fatal("Failed to create user_progress table:", err)
 	}
 
+	// Create chat_messages table
+	createChatMessagesTable := `
+	CREATE TABLE IF NOT EXISTS chat_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		room TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		username TEXT,
+		content TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_chat_messages_room ON chat_messages(room, id);`
+	_, err = db.Exec(createChatMessagesTable)
+	if err != nil {
+		log.Fatal("Failed to create chat_messages table:", err)
+	}
+
+	// Create direct_messages table
+	createDirectMessagesTable := `
+	CREATE TABLE IF NOT EXISTS direct_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		sender_id TEXT NOT NULL,
+		sender_name TEXT,
+		recipient_id TEXT NOT NULL,
+		content TEXT NOT NULL,
+		delivered INTEGER DEFAULT 0,
+		read_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_direct_messages_recipient ON direct_messages(recipient_id, delivered);
+	CREATE INDEX IF NOT EXISTS idx_direct_messages_sender ON direct_messages(sender_id);`
+	_, err = db.Exec(createDirectMessagesTable)
+	if err != nil {
+		log.Fatal("Failed to create direct_messages table:", err)
+	}
+
+	// Create moderation_actions table
+	createModerationActionsTable := `
+	CREATE TABLE IF NOT EXISTS moderation_actions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT NOT NULL,
+		moderator_id TEXT NOT NULL,
+		moderator_name TEXT,
+		target_id TEXT,
+		message_id INTEGER,
+		reason TEXT,
+		expires_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_moderation_actions_target ON moderation_actions(target_id);`
+	_, err = db.Exec(createModerationActionsTable)
+	if err != nil {
+		log.Fatal("Failed to create moderation_actions table:", err)
+	}
+
+	// Create friends table
+	createFriendsTable := `
+	CREATE TABLE IF NOT EXISTS friends (
+		user_id TEXT NOT NULL,
+		friend_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, friend_id),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (friend_id) REFERENCES users(id) ON DELETE CASCADE
+	);`
+	_, err = db.Exec(createFriendsTable)
+	if err != nil {
+		log.Fatal("Failed to create friends table:", err)
+	}
+
 	return db
 }
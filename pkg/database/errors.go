@@ -0,0 +1,34 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Repositories return these, wrapping the driver error, when a write breaks
+// a constraint. Reads still return sql.ErrNoRows when nothing matched.
+var (
+	// ErrDuplicate means a UNIQUE or PRIMARY KEY value is already taken
+	ErrDuplicate = errors.New("duplicate")
+	// ErrReference means a FOREIGN KEY refers to a missing row
+	ErrReference = errors.New("missing reference")
+)
+
+// Translate wraps constraint failures from SQLite in ErrDuplicate or
+// ErrReference and returns other errors as they are. SQLite reports the
+// constraint only in the message.
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "UNIQUE constraint failed"),
+		strings.Contains(message, "PRIMARY KEY constraint failed"):
+		return fmt.Errorf("%w: %v", ErrDuplicate, err)
+	case strings.Contains(message, "FOREIGN KEY constraint failed"):
+		return fmt.Errorf("%w: %v", ErrReference, err)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`
+	CREATE TABLE manga (id TEXT PRIMARY KEY);
+	CREATE TABLE user_library (
+		user_id TEXT NOT NULL,
+		manga_id TEXT NOT NULL,
+		FOREIGN KEY (manga_id) REFERENCES manga(id),
+		UNIQUE(user_id, manga_id)
+	);
+	INSERT INTO manga (id) VALUES ('berserk');`)
+	require.NoError(t, err)
+
+	_, err = db.Exec("INSERT INTO manga (id) VALUES ('berserk')")
+	assert.ErrorIs(t, Translate(err), ErrDuplicate)
+
+	_, err = db.Exec("INSERT INTO user_library (user_id, manga_id) VALUES ('alice', 'missing')")
+	assert.ErrorIs(t, Translate(err), ErrReference)
+
+	_, err = db.Exec("INSERT INTO user_library (user_id, manga_id) VALUES ('alice', 'berserk')")
+	require.NoError(t, Translate(err))
+	_, err = db.Exec("INSERT INTO user_library (user_id, manga_id) VALUES ('alice', 'berserk')")
+	assert.ErrorIs(t, Translate(err), ErrDuplicate)
+
+	other := errors.New("disk I/O error")
+	assert.Equal(t, other, Translate(other))
+}
@@ -0,0 +1,43 @@
+package models
+
+// ChatMessage is a chat message stored for a room
+type ChatMessage struct {
+	ID        int64  `json:"id"`
+	Room      string `json:"room"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+// DirectMessage is a private message between two users
+type DirectMessage struct {
+	ID          int64  `json:"id"`
+	SenderID    string `json:"sender_id"`
+	SenderName  string `json:"sender_name"`
+	RecipientID string `json:"recipient_id"`
+	Content     string `json:"content"`
+	ReadAt      string `json:"read_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Conversation summarizes the direct messages exchanged with another user
+type Conversation struct {
+	UserID      string        `json:"user_id"`
+	Username    string        `json:"username"`
+	UnreadCount int           `json:"unread_count"`
+	LastMessage DirectMessage `json:"last_message"`
+}
+
+// ModerationAction is an entry in the chat moderation audit log
+type ModerationAction struct {
+	ID            int64  `json:"id"`
+	Action        string `json:"action"` // mute, unmute, kick, ban, unban, delete_message
+	ModeratorID   string `json:"moderator_id"`
+	ModeratorName string `json:"moderator_name"`
+	TargetID      string `json:"target_id,omitempty"`
+	MessageID     int64  `json:"message_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	ExpiresAt     string `json:"expires_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
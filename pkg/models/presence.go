@@ -0,0 +1,33 @@
+package models
+
+// Presence visibility settings. Friends means only the users on your
+// friends list see you online.
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+	VisibilityHidden  = "hidden"
+)
+
+// Presence is whether a user is online and what they are reading
+type Presence struct {
+	UserID     string   `json:"user_id"`
+	Username   string   `json:"username,omitempty"`
+	Online     bool     `json:"online"`
+	Transports []string `json:"transports,omitempty"`
+	Reading    *Reading `json:"reading,omitempty"`
+	LastSeen   string   `json:"last_seen,omitempty"`
+}
+
+// Reading is the manga a user recently made progress in
+type Reading struct {
+	MangaID string `json:"manga_id"`
+	Chapter int    `json:"chapter"`
+	Since   string `json:"since"`
+}
+
+// Friend is an entry on a user's friends list
+type Friend struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	AddedAt  string `json:"added_at"`
+}
@@ -1,9 +1,19 @@
 package models
 
+// User roles. Moderators and admins can moderate chat. Admins and service
+// accounts may act on behalf of any user over gRPC.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	RoleService   = "service"
+)
+
 type User struct {
 	ID           string `json:"id"`
 	Username     string `json:"username"`
 	Email        string `json:"email"`
+	Role         string `json:"role"`
 	PasswordHash string `json:"-"`
 	CreatedAt    string `json:"created_at"`
 }